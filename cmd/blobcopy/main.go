@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/vrv501/simple-api/internal/blobstore"
+	"github.com/vrv501/simple-api/internal/db/mongodb"
+)
+
+const (
+	defaultAPIUser string = "apiUser"
+
+	imagesCollection string = "images"
+)
+
+type imageBlobs struct {
+	BlobKey    string            `bson:"blob_key"`
+	Renditions map[string]string `bson:"renditions"`
+}
+
+// Blobcopy is run once before api server is switched to BLOB_STORE=fs. Migrations move images of older
+// versions into GridFS bucket, blobcopy copies blobs of every image from there into store selected by
+// BLOB_STORE & BLOB_STORE_DIR. Blobs already in the store are skipped, hence it can be re-run after a failure.
+// It connects same as api server, as apiUser by default
+func main() {
+	ctx := signals.SetupSignalHandler()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	client, err := mongo.Connect(mongodb.ClientOptionsFromEnv("pet-store-blobcopy", defaultAPIUser))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create mongodb client")
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(mongodb.DBName)
+	dst, err := blobstore.NewStoreFromEnv(db)
+	if err != nil {
+		client.Disconnect(context.Background())
+		logger.Fatal().Err(err).Msg("Failed to create blob store")
+	}
+	if _, ok := dst.(*blobstore.GridFS); ok {
+		logger.Info().Msg("Blob store is GridFS already, nothing to copy")
+		return
+	}
+	src := blobstore.NewGridFS(db, blobstore.DefaultBucket)
+
+	start := time.Now()
+	copied, skipped, err := copyBlobs(ctx, db, dst, src)
+	event := logger.Info()
+	if err != nil {
+		event = logger.Error().Err(err)
+	}
+	event.Int("copied", copied).
+		Int("skipped", skipped).
+		Str("latency", time.Since(start).String()).
+		Msg("Blob copy summary")
+	if err != nil {
+		client.Disconnect(context.Background())
+		os.Exit(1) //nolint:gocritic // Client is disconnected explicitly above
+	}
+}
+
+// copyBlobs copies blobs of original & renditions of every image, including soft-deleted ones which are
+// yet to be purged. Returns count of blobs copied & skipped as dst already had them
+func copyBlobs(ctx context.Context, db *mongo.Database, dst, src blobstore.Store) (int, int, error) {
+	cursor, err := db.Collection(imagesCollection).Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"blob_key": 1, "renditions": 1}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var copied, skipped int
+	for cursor.Next(ctx) {
+		var img imageBlobs
+		if err = cursor.Decode(&img); err != nil {
+			return copied, skipped, err
+		}
+		keys := []string{img.BlobKey}
+		for _, key := range img.Renditions {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			ok, errS := blobstore.Copy(ctx, dst, src, key)
+			if errS != nil {
+				return copied, skipped, errS
+			}
+			if ok {
+				copied++
+			} else {
+				skipped++
+			}
+		}
+	}
+	return copied, skipped, cursor.Err()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	"github.com/vrv501/simple-api/internal/migrate"
+)
+
+const usage = `Usage: migrate COMMAND
+Applies Mongo migrations embedded in the binary to db in MIGRATE_DATABASE_URI
+
+Commands:
+  up             Apply all pending migrations
+  down           Revert the latest applied migration
+  goto VERSION   Apply or revert migrations until db is at VERSION
+  force VERSION  Set VERSION & clear dirty flag without running migrations, -1 means no migration applied
+  status         Print applied version & pending migrations`
+
+func main() {
+	ctx := signals.SetupSignalHandler()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	ctx = logger.WithContext(ctx)
+
+	args := os.Args[1:]
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status" && len(args) != 2) {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	timedCtx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+	defer cancel()
+	migrator, err := migrate.NewFromEnv(timedCtx)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to db")
+	}
+	defer migrator.Close(context.Background())
+
+	if err = run(timedCtx, migrator, args); err != nil {
+		logger.Error().Err(err).Msgf("Failed to %s", strings.Join(args, " "))
+		migrator.Close(context.Background())
+		os.Exit(1) //nolint:gocritic // Client is closed explicitly above
+	}
+}
+
+func run(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d, dirty: %t, latest: %d\n", status.Version, status.Dirty, status.Latest)
+		for _, migration := range status.Pending {
+			fmt.Printf("pending: %d_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+	}
+
+	version, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid version: %w", err)
+	}
+	switch args[0] {
+	case "goto":
+		if version < 0 {
+			return migrate.ErrUnknownVersion
+		}
+		return migrator.To(ctx, uint(version))
+	case "force":
+		return migrator.Force(ctx, version)
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
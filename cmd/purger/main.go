@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/vrv501/simple-api/internal/blobstore"
+	"github.com/vrv501/simple-api/internal/db/mongodb"
+	"github.com/vrv501/simple-api/internal/purge"
+)
+
+const defaultPurgerUser string = "purger"
+
+// Purger is meant to be run periodically, for instance as a cron job. It connects same as api server,
+// i.e. with MONGO_APPLY_URI or MONGO_URI along with DB_USERNAME & DB_PASSWORD, as purger user by default.
+// Blobs of images are deleted from the store selected by BLOB_STORE, same as api server
+func main() {
+	var opts purge.Options
+	flag.DurationVar(&opts.Retention, "retention", purge.DefaultRetention,
+		"purge documents soft-deleted longer than this ago")
+	flag.IntVar(&opts.BatchSize, "batch-size", purge.DefaultBatchSize, "documents deleted by a single command")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "only count documents which would be purged")
+	flag.Parse()
+
+	ctx := signals.SetupSignalHandler()
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+	client, err := mongo.Connect(mongodb.ClientOptionsFromEnv("pet-store-purger", defaultPurgerUser))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create mongodb client")
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(mongodb.DBName)
+	blobs, err := blobstore.NewStoreFromEnv(db)
+	if err != nil {
+		client.Disconnect(context.Background())
+		logger.Fatal().Err(err).Msg("Failed to create blob store")
+	}
+
+	start := time.Now()
+	summary, err := purge.New(db, blobs, opts).Run(ctx)
+	event := logger.Info()
+	if err != nil {
+		event = logger.Error().Err(err)
+	}
+	dict := zerolog.Dict()
+	for collection, n := range summary {
+		dict.Int64(collection, n)
+	}
+	event.Bool("dry_run", opts.DryRun).
+		Str("retention", opts.Retention.String()).
+		Str("latency", time.Since(start).String()).
+		Dict("purged", dict).
+		Msg("Purge summary")
+	if err != nil {
+		client.Disconnect(context.Background())
+		os.Exit(1) //nolint:gocritic // Client is disconnected explicitly above
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,24 +21,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	apihandler "github.com/vrv501/simple-api/internal/api-handler"
+	"github.com/vrv501/simple-api/internal/auth"
 	"github.com/vrv501/simple-api/internal/constants"
+	"github.com/vrv501/simple-api/internal/cursor"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
 	"github.com/vrv501/simple-api/internal/middleware"
+	"github.com/vrv501/simple-api/internal/migrate"
+	"github.com/vrv501/simple-api/internal/payments"
 )
 
 func main() {
 	ctx := signals.SetupSignalHandler()
+	logger := configLogger()
 	spec, _ := genRouter.GetSwagger()
+
+	tokenManager := newTokenManager(logger)
 	ogenMw := ogenMiddleware.OapiRequestValidatorWithOptions(spec, &ogenMiddleware.Options{
 		Options: openapi3filter.Options{
-			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, // Update once authnz is implemented
+			AuthenticationFunc: tokenManager.Authenticate,
 		},
 		ErrorHandlerWithOpts: func(_ context.Context, err error, w http.ResponseWriter,
 			_ *http.Request, opts ogenMiddleware.ErrorHandlerOpts) {
-			var reqErr *openapi3filter.RequestError
-			if errors.As(err, &reqErr) {
+			var (
+				reqErr *openapi3filter.RequestError
+				secErr *openapi3filter.SecurityRequirementsError
+			)
+			switch {
+			case errors.As(err, &reqErr):
 				errorLines := strings.Split(reqErr.Error(), "\n")
 				err = errors.New(errorLines[0])
+			case errors.As(err, &secErr) && len(secErr.Errors) > 0:
+				// Only the failure reason is relevant to clients
+				err = secErr.Errors[0]
+				w.Header().Set("WWW-Authenticate", "Bearer")
 			}
 
 			w.Header().Del("Content-Length")
@@ -56,13 +72,13 @@ func main() {
 	registerBodyEncoders()
 	registerBodyDecoders()
 
-	logger := configLogger()
 	basePath, err := spec.Servers.BasePath()
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to get base path from OpenAPI spec")
 	}
 
 	port := getPort(logger)
+	serveMetrics(logger)
 	router := http.NewServeMux()
 	router.HandleFunc(http.MethodGet+" /status",
 		func(w http.ResponseWriter, _ *http.Request) {
@@ -70,8 +86,10 @@ func main() {
 		},
 	)
 
-	apiHandler := apihandler.NewAPIHandler(ctx)
+	migrateOnStart(ctx, logger)
+	apiHandler := apihandler.NewAPIHandler(ctx, tokenManager, newCursorCodec(logger), newPaymentProvider(logger), basePath)
 	defer apiHandler.Close()
+	go apiHandler.SweepExpiredHolds(logger.WithContext(ctx), constants.CartSweepInterval)
 
 	routerWithCors := genRouter.HandlerWithOptions(
 		genRouter.NewStrictHandler(apiHandler, nil),
@@ -96,6 +114,7 @@ func main() {
 							Msg("Exit Audit")
 					},
 				),
+				middleware.RequireRoles(spec, basePath),
 				ogenMw,
 				middleware.PanicRecovery,
 				hlog.NewHandler(logger),
@@ -147,6 +166,33 @@ func getPort(logger zerolog.Logger) int {
 	return port
 }
 
+// serveMetrics serves expvar metrics, such as lock contention per user, on METRICS_PORT.
+// Port is separate from api, so that metrics are not exposed to clients
+func serveMetrics(logger zerolog.Logger) {
+	portStr := os.Getenv(constants.MetricsPort)
+	if portStr == "" {
+		return
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("Invalid %s", constants.MetricsPort)
+	}
+
+	router := http.NewServeMux()
+	router.Handle(http.MethodGet+" /debug/vars", expvar.Handler())
+	server := http.Server{
+		Addr:        fmt.Sprintf("0.0.0.0:%d", port),
+		Handler:     router,
+		ReadTimeout: 30 * time.Second,
+	}
+	go func() {
+		logger.Info().Msgf("Started metrics server on port %d", port)
+		if errS := server.ListenAndServe(); errS != nil {
+			logger.Error().Err(errS).Msg("Metrics server stopped")
+		}
+	}()
+}
+
 func configLogger() zerolog.Logger {
 	logger := zerolog.New(os.Stdout).With().Caller().Timestamp().Logger()
 	// To disable logging entirely, pass [zerolog.Disabled]
@@ -161,6 +207,60 @@ func configLogger() zerolog.Logger {
 	return logger.Level(logLevel)
 }
 
+// migrateOnStart applies pending Mongo migrations when MIGRATE_ON_START is set. Replicas starting together
+// wait for each other, hence only one of them migrates
+func migrateOnStart(ctx context.Context, logger zerolog.Logger) {
+	enabled, _ := strconv.ParseBool(os.Getenv(constants.MigrateOnStart))
+	if !enabled {
+		return
+	}
+
+	timedCtx, cancel := context.WithTimeout(logger.WithContext(ctx), constants.DefaultTimeout)
+	defer cancel()
+	migrator, err := migrate.NewFromEnv(timedCtx)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to db for migrations")
+	}
+	defer migrator.Close(timedCtx)
+	if err = migrator.Up(timedCtx); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to apply migrations")
+	}
+}
+
+func newTokenManager(logger zerolog.Logger) *auth.TokenManager {
+	cfg, err := auth.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid JWT configuration")
+	}
+	tokenManager, err := auth.NewTokenManager(cfg)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create token manager")
+	}
+	return tokenManager
+}
+
+func newCursorCodec(logger zerolog.Logger) *cursor.Codec {
+	if os.Getenv(constants.CursorSecret) == "" {
+		logger.Warn().Msgf("%s is not set, pagination cursors won't survive restarts", constants.CursorSecret)
+	}
+	cursorCodec, err := cursor.NewCodecFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create cursor codec")
+	}
+	return cursorCodec
+}
+
+func newPaymentProvider(logger zerolog.Logger) payments.Provider {
+	paymentProvider, err := payments.NewProviderFromEnv()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to create payment provider")
+	}
+	if paymentProvider.Name() == payments.FakeProviderName {
+		logger.Warn().Msg("Using fake payment provider, payments are not real")
+	}
+	return paymentProvider
+}
+
 func registerBodyEncoders() {
 	openapi3filter.RegisterBodyEncoder(
 		"image/jpeg",
@@ -0,0 +1,7 @@
+// Package migrations embeds Mongo migrations, so that binaries can apply them without the source tree
+package migrations
+
+import "embed"
+
+//go:embed *.json
+var FS embed.FS
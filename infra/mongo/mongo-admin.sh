@@ -54,6 +54,18 @@ if (!db.getRole("appRole")) {
     }, { w: "majority" })
 }
 
+// Granted outside createRole, so that re-running this script upgrades appRole of existing deployments.
+// Api server deletes cart items on removal, checkout, order placement & expiry of holds
+db.grantPrivilegesToRole("appRole", [
+    { resource: { db: "${DB_NAME}", collection: "cart_items" }, actions: [ "remove" ] }
+], { w: "majority" })
+
+// Api server deletes blobs of images stored in GridFS, i.e. on replacing or failing to add images
+db.grantPrivilegesToRole("appRole", [
+    { resource: { db: "${DB_NAME}", collection: "images.files" }, actions: [ "remove" ] },
+    { resource: { db: "${DB_NAME}", collection: "images.chunks" }, actions: [ "remove" ] }
+], { w: "majority" })
+
 if (!db.getRole("purgerRole")) {
     db.createRole({
       role: "purgerRole",
@@ -0,0 +1,4 @@
+-- Role used by api server, tables are owned by the role running migrations
+CREATE ROLE "apiUser" LOGIN PASSWORD 'postgres';
+GRANT USAGE ON SCHEMA public TO "apiUser";
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO "apiUser";
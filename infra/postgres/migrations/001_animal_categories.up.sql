@@ -0,0 +1,10 @@
+-- Fuzzy matching of category names, like the Atlas Search index on Mongo
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+CREATE TABLE animal_categories (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name       text        NOT NULL,
+    created_on timestamptz NOT NULL,
+    updated_on timestamptz,
+    CONSTRAINT animal_categories_name_unique UNIQUE (name)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE users (
+    id           bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    username     text        NOT NULL,
+    full_name    text        NOT NULL,
+    password     text        NOT NULL,
+    address      text        NOT NULL,
+    phone_number text        NOT NULL,
+    role         text        NOT NULL DEFAULT 'customer' CHECK (role IN ('customer', 'seller', 'admin')),
+    created_on   timestamptz NOT NULL,
+    updated_on   timestamptz,
+    deleted_on   timestamptz
+);
+
+-- Soft-deleted users free up their username & phone number
+CREATE UNIQUE INDEX users_username_unique_idx ON users (username) WHERE deleted_on IS NULL;
+CREATE UNIQUE INDEX users_phone_number_unique_idx ON users (phone_number) WHERE deleted_on IS NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE pets (
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name        text        NOT NULL,
+    category_id bigint      NOT NULL REFERENCES animal_categories (id),
+    user_id     bigint      NOT NULL REFERENCES users (id),
+    price       numeric     NOT NULL CHECK (price >= 0),
+    status      text        NOT NULL CHECK (status IN ('available', 'sold')),
+    tags        text[],
+    created_on  timestamptz NOT NULL,
+    updated_on  timestamptz,
+    deleted_on  timestamptz
+);
+
+CREATE UNIQUE INDEX pets_name_category_id_unique_idx ON pets (name, category_id) WHERE deleted_on IS NULL;
+CREATE INDEX pets_user_id_status_idx ON pets (user_id, status) WHERE deleted_on IS NULL;
+CREATE INDEX pets_tags_idx ON pets USING gin (tags);
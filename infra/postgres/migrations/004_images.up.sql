@@ -0,0 +1,9 @@
+CREATE TABLE images (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    pet_id     bigint NOT NULL REFERENCES pets (id),
+    user_id    bigint NOT NULL REFERENCES users (id),
+    image      bytea  NOT NULL,
+    deleted_on timestamptz
+);
+
+CREATE INDEX images_pet_id_idx ON images (pet_id) WHERE deleted_on IS NULL;
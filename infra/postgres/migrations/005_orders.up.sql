@@ -0,0 +1,28 @@
+CREATE TABLE orders (
+    id              bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id         bigint      NOT NULL REFERENCES users (id),
+    pet_id          bigint      NOT NULL REFERENCES pets (id),
+    seller_id       bigint      NOT NULL REFERENCES users (id),
+    price           numeric     NOT NULL,
+    shipped_date    timestamptz,
+    delivered_date  timestamptz,
+    tracking_number text,
+    carrier         text,
+    status          text        NOT NULL
+        CHECK (status IN ('placed', 'processing', 'shipped', 'delivered', 'cancelled')),
+    created_on      timestamptz NOT NULL,
+    updated_on      timestamptz
+);
+
+CREATE INDEX orders_user_id_idx ON orders (user_id, id);
+CREATE INDEX orders_seller_id_idx ON orders (seller_id, id);
+CREATE INDEX orders_pet_id_open_idx ON orders (pet_id) WHERE status NOT IN ('delivered', 'cancelled');
+
+-- Append-only history of order status changes
+CREATE TABLE order_status_history (
+    order_id   bigint      NOT NULL REFERENCES orders (id),
+    status     text        NOT NULL,
+    changed_on timestamptz NOT NULL
+);
+
+CREATE INDEX order_status_history_order_id_idx ON order_status_history (order_id, changed_on);
@@ -0,0 +1,12 @@
+-- A pet is held by atmost one user at a time
+CREATE TABLE cart_items (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    bigint      NOT NULL REFERENCES users (id),
+    pet_id     bigint      NOT NULL REFERENCES pets (id),
+    held_until timestamptz NOT NULL,
+    created_on timestamptz NOT NULL,
+    CONSTRAINT cart_items_pet_id_unique UNIQUE (pet_id)
+);
+
+CREATE INDEX cart_items_user_id_held_until_idx ON cart_items (user_id, held_until);
+CREATE INDEX cart_items_held_until_idx ON cart_items (held_until);
@@ -0,0 +1,13 @@
+CREATE TABLE payment_intents (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    order_id   bigint      NOT NULL REFERENCES orders (id),
+    provider   text        NOT NULL,
+    intent_id  text        NOT NULL,
+    status     text        NOT NULL CHECK (status IN ('authorized', 'captured', 'refunded')),
+    amount     bigint      NOT NULL,
+    currency   text        NOT NULL,
+    created_on timestamptz NOT NULL,
+    updated_on timestamptz,
+    CONSTRAINT payment_intents_order_id_unique UNIQUE (order_id),
+    CONSTRAINT payment_intents_provider_intent_id_unique UNIQUE (provider, intent_id)
+);
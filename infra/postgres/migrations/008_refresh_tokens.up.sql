@@ -0,0 +1,15 @@
+CREATE TABLE refresh_tokens (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    bigint      NOT NULL REFERENCES users (id),
+    family_id  uuid        NOT NULL,
+    token_hash text        NOT NULL,
+    expires_on timestamptz NOT NULL,
+    created_on timestamptz NOT NULL,
+    rotated_on timestamptz,
+    revoked_on timestamptz,
+    CONSTRAINT refresh_tokens_token_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+CREATE INDEX refresh_tokens_expires_on_idx ON refresh_tokens (expires_on);
@@ -0,0 +1,3 @@
+ALTER TABLE images
+    DROP COLUMN IF EXISTS image_small,
+    DROP COLUMN IF EXISTS image_medium;
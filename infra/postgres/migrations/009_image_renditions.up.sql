@@ -0,0 +1,4 @@
+-- Scaled down renditions of an image, NULL when the image is no larger than the rendition
+ALTER TABLE images
+    ADD COLUMN image_small  bytea,
+    ADD COLUMN image_medium bytea;
@@ -0,0 +1,196 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/vrv501/simple-api/internal/auth"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+const (
+	errMsgInvalidCredentials  = "invalid username or password"
+	errMsgInvalidRefreshToken = "invalid refresh token"
+	tokenTypeBearer           = "Bearer"
+)
+
+// dummyPasswordHash is compared against when username doesn't exist
+// so that response times don't reveal which usernames are registered
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := hashPassword("dummy-password")
+	return hash
+})
+
+// Login using username & password.
+// (POST /auth/login)
+func (a *APIHandler) Login(ctx context.Context,
+	request genRouter.LoginRequestObject) (genRouter.LoginResponseObject, error) {
+	logger := log.Ctx(ctx)
+	loginReq := request.Body
+
+	userID, hashedPswd, role, err := a.dbClient.GetUserCredentials(ctx, loginReq.Username)
+	if err != nil {
+		if errors.Is(err, dbErr.ErrNotFound) {
+			_ = comparePasswords(dummyPasswordHash(), loginReq.Password)
+			return genRouter.LogindefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCredentials,
+				},
+				StatusCode: http.StatusUnauthorized,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to get user credentials")
+		return genRouter.LogindefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	if err = comparePasswords(hashedPswd, loginReq.Password); err != nil {
+		return genRouter.LogindefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: errMsgInvalidCredentials,
+			},
+			StatusCode: http.StatusUnauthorized,
+		}, nil
+	}
+
+	refreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to generate refresh token")
+		return genRouter.LogindefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	err = a.dbClient.AddRefreshToken(ctx, userID, auth.HashRefreshToken(refreshToken),
+		time.Now().Add(a.tokenManager.RefreshTokenTTL()))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to store refresh token")
+		return genRouter.LogindefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	tokenRes, err := a.issueTokens(userID, role, refreshToken)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to issue tokens")
+		return genRouter.LogindefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("UserID %s logged in", userID)
+	return genRouter.Login200JSONResponse{TokenJSONResponse: *tokenRes}, nil
+}
+
+// Exchange refresh token for new tokens.
+// (POST /auth/refresh)
+func (a *APIHandler) RefreshToken(ctx context.Context,
+	request genRouter.RefreshTokenRequestObject) (genRouter.RefreshTokenResponseObject, error) {
+	logger := log.Ctx(ctx)
+
+	newRefreshToken, err := auth.NewRefreshToken()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to generate refresh token")
+		return genRouter.RefreshTokendefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	userID, role, err := a.dbClient.RotateRefreshToken(ctx,
+		auth.HashRefreshToken(request.Body.RefreshToken),
+		auth.HashRefreshToken(newRefreshToken),
+		time.Now().Add(a.tokenManager.RefreshTokenTTL()))
+	if err != nil {
+		var reuseErr *dbErr.HintError
+		switch {
+		case errors.As(err, &reuseErr) && errors.Is(reuseErr.Err, dbErr.ErrConflict):
+			logger.Warn().Msg("Refresh token reuse detected, revoked token family")
+			return genRouter.RefreshTokendefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidRefreshToken,
+				},
+				StatusCode: http.StatusUnauthorized,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.RefreshTokendefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidRefreshToken,
+				},
+				StatusCode: http.StatusUnauthorized,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to rotate refresh token")
+		return genRouter.RefreshTokendefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	tokenRes, err := a.issueTokens(userID, role, newRefreshToken)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to issue tokens")
+		return genRouter.RefreshTokendefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	return genRouter.RefreshToken200JSONResponse{TokenJSONResponse: *tokenRes}, nil
+}
+
+// Revoke refresh token.
+// (POST /auth/logout)
+func (a *APIHandler) Logout(ctx context.Context,
+	request genRouter.LogoutRequestObject) (genRouter.LogoutResponseObject, error) {
+	logger := log.Ctx(ctx)
+
+	err := a.dbClient.RevokeRefreshTokenFamily(ctx, auth.HashRefreshToken(request.Body.RefreshToken))
+	if err != nil && !errors.Is(err, dbErr.ErrNotFound) {
+		logger.Error().Err(err).Msg("Failed to revoke refresh token")
+		return genRouter.LogoutdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	// Unknown tokens are not reported so that logout stays idempotent
+	return genRouter.Logout204Response{}, nil
+}
+
+func (a *APIHandler) issueTokens(userID string, role genRouter.UserRole,
+	refreshToken string) (*genRouter.TokenJSONResponse, error) {
+	accessToken, ttl, err := a.tokenManager.IssueAccessToken(userID, string(role))
+	if err != nil {
+		return nil, err
+	}
+	return &genRouter.TokenJSONResponse{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(ttl.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,323 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vrv501/simple-api/internal/auth"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/generated/mockdb"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// newTestTokenManager creates HS256 token manager for testing
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	t.Helper()
+	tokenManager, err := auth.NewTokenManager(&auth.Config{
+		Algorithm: "HS256",
+		Secret:    []byte("0123456789abcdef0123456789abcdef"),
+		Issuer:    "test",
+		Audience:  "test",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create token manager: %v", err)
+	}
+	return tokenManager
+}
+
+func TestAPIHandler_Login(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	tokenManager := newTestTokenManager(t)
+	hashedPswd, _ := hashPassword("password123")
+	validReq := genRouter.LoginRequestObject{
+		Body: &genRouter.LoginJSONRequestBody{
+			Username: "tony",
+			Password: "password123",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		request     genRouter.LoginRequestObject
+		prepare     func()
+		want        genRouter.LoginResponseObject
+		wantSuccess bool
+	}{
+		{
+			name:    "user not found",
+			request: validReq,
+			prepare: func() {
+				mockDBClient.EXPECT().GetUserCredentials(gomock.Any(), "tony").
+					Return("", "", genRouter.UserRole(""), dbErr.ErrNotFound)
+			},
+			want: genRouter.LogindefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCredentials,
+				},
+				StatusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name:    "internal error",
+			request: validReq,
+			prepare: func() {
+				mockDBClient.EXPECT().GetUserCredentials(gomock.Any(), "tony").
+					Return("", "", genRouter.UserRole(""), errors.New(""))
+			},
+			want: genRouter.LogindefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "wrong password",
+			request: genRouter.LoginRequestObject{
+				Body: &genRouter.LoginJSONRequestBody{
+					Username: "tony",
+					Password: "password1234",
+				},
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetUserCredentials(gomock.Any(), "tony").
+					Return("68a5c2d7e4b0a1b2c3d4e5f6", hashedPswd, genRouter.Seller, nil)
+			},
+			want: genRouter.LogindefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCredentials,
+				},
+				StatusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name:    "failed to store refresh token",
+			request: validReq,
+			prepare: func() {
+				mockDBClient.EXPECT().GetUserCredentials(gomock.Any(), "tony").
+					Return("68a5c2d7e4b0a1b2c3d4e5f6", hashedPswd, genRouter.Seller, nil)
+				mockDBClient.EXPECT().AddRefreshToken(gomock.Any(), "68a5c2d7e4b0a1b2c3d4e5f6",
+					gomock.Any(), gomock.Any()).Return(errors.New(""))
+			},
+			want: genRouter.LogindefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "success",
+			request: validReq,
+			prepare: func() {
+				mockDBClient.EXPECT().GetUserCredentials(gomock.Any(), "tony").
+					Return("68a5c2d7e4b0a1b2c3d4e5f6", hashedPswd, genRouter.Seller, nil)
+				mockDBClient.EXPECT().AddRefreshToken(gomock.Any(), "68a5c2d7e4b0a1b2c3d4e5f6",
+					gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantSuccess: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient:     mockDBClient,
+				tokenManager: tokenManager,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.Login(context.Background(), tt.request)
+			if !tt.wantSuccess {
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("APIHandler.Login() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+
+			res, ok := got.(genRouter.Login200JSONResponse)
+			if !ok {
+				t.Fatalf("APIHandler.Login() = %v, want Login200JSONResponse", got)
+			}
+			claims, err := tokenManager.ParseAccessToken(res.AccessToken)
+			if err != nil || claims.Subject != "68a5c2d7e4b0a1b2c3d4e5f6" || claims.Role != string(genRouter.Seller) {
+				t.Errorf("APIHandler.Login() issued invalid access token, err = %v", err)
+			}
+			if res.TokenType != tokenTypeBearer || res.ExpiresIn <= 0 || res.RefreshToken == "" {
+				t.Errorf("APIHandler.Login() = %+v", res)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_RefreshToken(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	tokenManager := newTestTokenManager(t)
+	validReq := genRouter.RefreshTokenRequestObject{
+		Body: &genRouter.RefreshTokenJSONRequestBody{
+			RefreshToken: "old-refresh-token",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		prepare     func()
+		want        genRouter.RefreshTokenResponseObject
+		wantSuccess bool
+	}{
+		{
+			name: "unknown or expired token",
+			prepare: func() {
+				mockDBClient.EXPECT().RotateRefreshToken(gomock.Any(),
+					auth.HashRefreshToken("old-refresh-token"), gomock.Any(), gomock.Any()).
+					Return("", genRouter.UserRole(""), dbErr.ErrNotFound)
+			},
+			want: genRouter.RefreshTokendefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidRefreshToken,
+				},
+				StatusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "token reuse",
+			prepare: func() {
+				mockDBClient.EXPECT().RotateRefreshToken(gomock.Any(),
+					auth.HashRefreshToken("old-refresh-token"), gomock.Any(), gomock.Any()).
+					Return("", genRouter.UserRole(""), &dbErr.HintError{Err: dbErr.ErrConflict})
+			},
+			want: genRouter.RefreshTokendefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidRefreshToken,
+				},
+				StatusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "internal error",
+			prepare: func() {
+				mockDBClient.EXPECT().RotateRefreshToken(gomock.Any(),
+					gomock.Any(), gomock.Any(), gomock.Any()).
+					Return("", genRouter.UserRole(""), errors.New(""))
+			},
+			want: genRouter.RefreshTokendefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "success",
+			prepare: func() {
+				mockDBClient.EXPECT().RotateRefreshToken(gomock.Any(),
+					auth.HashRefreshToken("old-refresh-token"), gomock.Any(), gomock.Any()).
+					Return("68a5c2d7e4b0a1b2c3d4e5f6", genRouter.Admin, nil)
+			},
+			wantSuccess: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient:     mockDBClient,
+				tokenManager: tokenManager,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.RefreshToken(context.Background(), validReq)
+			if !tt.wantSuccess {
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("APIHandler.RefreshToken() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+
+			res, ok := got.(genRouter.RefreshToken200JSONResponse)
+			if !ok {
+				t.Fatalf("APIHandler.RefreshToken() = %v, want RefreshToken200JSONResponse", got)
+			}
+			if res.RefreshToken == "" || res.RefreshToken == "old-refresh-token" {
+				t.Errorf("APIHandler.RefreshToken() did not rotate refresh token")
+			}
+			if claims, err := tokenManager.ParseAccessToken(res.AccessToken); err != nil ||
+				claims.Role != string(genRouter.Admin) {
+				t.Errorf("APIHandler.RefreshToken() issued invalid access token, err = %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_Logout(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	validReq := genRouter.LogoutRequestObject{
+		Body: &genRouter.LogoutJSONRequestBody{
+			RefreshToken: "refresh-token",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		prepare func()
+		want    genRouter.LogoutResponseObject
+	}{
+		{
+			name: "internal error",
+			prepare: func() {
+				mockDBClient.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any()).
+					Return(errors.New(""))
+			},
+			want: genRouter.LogoutdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "unknown token",
+			prepare: func() {
+				mockDBClient.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), gomock.Any()).
+					Return(dbErr.ErrNotFound)
+			},
+			want: genRouter.Logout204Response{},
+		},
+		{
+			name: "success",
+			prepare: func() {
+				mockDBClient.EXPECT().RevokeRefreshTokenFamily(gomock.Any(),
+					auth.HashRefreshToken("refresh-token")).Return(nil)
+			},
+			want: genRouter.Logout204Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.Logout(context.Background(), validReq)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.Logout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+var errMissingUserID = errors.New(errMsgUserIDNotFound)
+
+// ownerLookup resolves userID owning the resource identified by id
+type ownerLookup func(ctx context.Context, id string) (string, error)
+
+// authorizeOwner verifies user in context owns the resource & returns owner's userID.
+// Resources owned by someone else are reported as [dbErr.ErrNotFound]
+// so that their existence isn't revealed. Admins can act on any resource
+func authorizeOwner(ctx context.Context, lookup ownerLookup, resourceID string) (string, error) {
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		return "", errMissingUserID
+	}
+
+	ownerID, err := lookup(ctx, resourceID)
+	if err != nil {
+		return "", err
+	}
+	if ownerID != userID {
+		if role, _ := contextKeys.UserRoleFromContext(ctx); role != string(genRouter.Admin) {
+			return "", dbErr.ErrNotFound
+		}
+	}
+	return ownerID, nil
+}
@@ -0,0 +1,94 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func Test_authorizeOwner(t *testing.T) {
+	t.Parallel()
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	invalidIDErr := &dbErr.HintError{Key: "_id", Err: dbErr.ErrInvalidValue}
+	ownedBy := func(ownerID string, err error) ownerLookup {
+		return func(_ context.Context, _ string) (string, error) {
+			return ownerID, err
+		}
+	}
+
+	type args struct {
+		ctx    context.Context
+		lookup ownerLookup
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "userID not in context",
+			args: args{
+				ctx:    context.Background(),
+				lookup: ownedBy("1", nil),
+			},
+			wantErr: errMissingUserID,
+		},
+		{
+			name: "resource not found",
+			args: args{
+				ctx:    ctxU,
+				lookup: ownedBy("", dbErr.ErrNotFound),
+			},
+			wantErr: dbErr.ErrNotFound,
+		},
+		{
+			name: "invalid resource id",
+			args: args{
+				ctx:    ctxU,
+				lookup: ownedBy("", invalidIDErr),
+			},
+			wantErr: invalidIDErr,
+		},
+		{
+			name: "owned by another user",
+			args: args{
+				ctx:    contextKeys.ContextWithUserRole(ctxU, string(genRouter.Seller)),
+				lookup: ownedBy("2", nil),
+			},
+			wantErr: dbErr.ErrNotFound,
+		},
+		{
+			name: "admin override",
+			args: args{
+				ctx:    contextKeys.ContextWithUserRole(ctxU, string(genRouter.Admin)),
+				lookup: ownedBy("2", nil),
+			},
+			want: "2",
+		},
+		{
+			name: "owner",
+			args: args{
+				ctx:    ctxU,
+				lookup: ownedBy("1", nil),
+			},
+			want: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authorizeOwner(tt.args.ctx, tt.args.lookup, "id")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("authorizeOwner() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("authorizeOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
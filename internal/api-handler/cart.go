@@ -0,0 +1,240 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// List pets held in user cart.
+// (GET /store/cart)
+func (a *APIHandler) ListCart(ctx context.Context,
+	_ genRouter.ListCartRequestObject) (genRouter.ListCartResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.ListCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	items, err := a.dbClient.FindCartItems(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to find cart items")
+		return genRouter.ListCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	res := genRouter.ListCart200JSONResponse{}
+	res.Count = len(items)
+	res.Items = items
+	return res, nil
+}
+
+// Add pet to user cart.
+// (POST /store/cart)
+func (a *APIHandler) AddToCart(ctx context.Context,
+	request genRouter.AddToCartRequestObject) (genRouter.AddToCartResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.AddToCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	item, err := a.dbClient.AddCartItem(ctx, userID, request.Body.PetId)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		if errors.As(err, &hintErr) {
+			errMsg, statusCode := petPlacementError(hintErr)
+			return genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to add pet %s to cart", request.Body.PetId)
+		return genRouter.AddToCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully held pet %s until %s", item.PetId, item.HeldUntil)
+	return genRouter.AddToCart201JSONResponse(*item), nil
+}
+
+// Remove pet from user cart.
+// (DELETE /store/cart/{petId})
+func (a *APIHandler) RemoveFromCart(ctx context.Context,
+	request genRouter.RemoveFromCartRequestObject) (genRouter.RemoveFromCartResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.RemoveFromCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	err := a.dbClient.DeleteCartItem(ctx, userID, request.PetId)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr):
+			return genRouter.RemoveFromCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.RemoveFromCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet not found in cart",
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to remove pet %s from cart", request.PetId)
+		return genRouter.RemoveFromCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully released hold on pet %s", request.PetId)
+	return genRouter.RemoveFromCart204Response{}, nil
+}
+
+// Checkout user cart.
+// (POST /store/cart/checkout)
+func (a *APIHandler) CheckoutCart(ctx context.Context,
+	_ genRouter.CheckoutCartRequestObject) (genRouter.CheckoutCartResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.CheckoutCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	orders, err := a.dbClient.CheckoutCart(ctx, userID)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr):
+			errMsg, statusCode := petPlacementError(hintErr)
+			return genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "cart is empty",
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to checkout cart")
+		return genRouter.CheckoutCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	if err = a.chargeOrders(ctx, userID, orders); err != nil {
+		a.restoreCart(ctx, userID, orders)
+		if errMsg, statusCode, ok := paymentError(err); ok {
+			return genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to pay for cart")
+		return genRouter.CheckoutCartdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully checked out cart with %d orders", len(orders))
+	res := genRouter.CheckoutCart201JSONResponse{}
+	res.Body.Count = len(orders)
+	res.Body.Orders = orders
+	return res, nil
+}
+
+// restoreCart holds pets of orders cancelled by a failed checkout in the cart of userID again,
+// so that checkout can be retried. Failures are only logged, same as cancelling the orders
+func (a *APIHandler) restoreCart(ctx context.Context, userID string, orders []genRouter.Order) {
+	logger := log.Ctx(ctx)
+	for i := range orders {
+		if _, err := a.dbClient.AddCartItem(ctx, userID, orders[i].PetId); err != nil {
+			logger.Error().Err(err).Msgf("Failed to restore pet %s in cart", orders[i].PetId)
+		}
+	}
+}
+
+// SweepExpiredHolds releases expired cart holds every interval until ctx is done
+func (a *APIHandler) SweepExpiredHolds(ctx context.Context, interval time.Duration) {
+	logger := log.Ctx(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := a.dbClient.ReleaseExpiredHolds(ctx)
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to release expired cart holds")
+				continue
+			}
+			if released > 0 {
+				logger.Info().Msgf("Released %d expired cart holds", released)
+			}
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/generated/mockdb"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+func TestAPIHandler_ListCart(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	items := []genRouter.CartItem{
+		{PetId: "68a5c2d7e4b0a1b2c3d4e5f6", HeldUntil: time.Date(2025, 8, 20, 10, 15, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		prepare func()
+		want    genRouter.ListCartResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.ListCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindCartItems(gomock.Any(), "1").Return(nil, errors.New(""))
+			},
+			want: genRouter.ListCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindCartItems(gomock.Any(), "1").Return(items, nil)
+			},
+			want: func() genRouter.ListCartResponseObject {
+				res := genRouter.ListCart200JSONResponse{}
+				res.Count = 1
+				res.Items = items
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.ListCart(tt.ctx, genRouter.ListCartRequestObject{})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.ListCart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_AddToCart(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	petID := "68a5c2d7e4b0a1b2c3d4e5f6"
+	item := &genRouter.CartItem{PetId: petID, HeldUntil: time.Date(2025, 8, 20, 10, 15, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		prepare func()
+		want    genRouter.AddToCartResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "pet not found",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", petID).
+					Return(nil, &dbErr.HintError{Key: "pet_id", Err: dbErr.ErrNotFound})
+			},
+			want: genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pet held by another user",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", petID).
+					Return(nil, &dbErr.HintError{Key: "status", Err: dbErr.ErrConflict})
+			},
+			want: genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet is not available",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "cart full",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", petID).
+					Return(nil, &dbErr.HintError{Key: "cart_items", Err: dbErr.ErrLimitExceeded})
+			},
+			want: genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "cart can have at most 10 pets",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", petID).Return(nil, errors.New(""))
+			},
+			want: genRouter.AddToCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", petID).Return(item, nil)
+			},
+			want: genRouter.AddToCart201JSONResponse(*item),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.AddToCart(tt.ctx, genRouter.AddToCartRequestObject{
+				Body: &genRouter.AddToCartJSONRequestBody{PetId: petID},
+			})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.AddToCart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_RemoveFromCart(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		prepare func()
+		want    genRouter.RemoveFromCartResponseObject
+	}{
+		{
+			name: "invalid pet id",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().DeleteCartItem(gomock.Any(), "1", gomock.Any()).
+					Return(&dbErr.HintError{Key: "pet_id", Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.RemoveFromCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "pet not in cart",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().DeleteCartItem(gomock.Any(), "1", gomock.Any()).Return(dbErr.ErrNotFound)
+			},
+			want: genRouter.RemoveFromCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet not found in cart",
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().DeleteCartItem(gomock.Any(), "1", "68a5c2d7e4b0a1b2c3d4e5f6").Return(nil)
+			},
+			want: genRouter.RemoveFromCart204Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.RemoveFromCart(tt.ctx, genRouter.RemoveFromCartRequestObject{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.RemoveFromCart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_CheckoutCart(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	orders := []genRouter.Order{
+		{Id: "68a5c2d7e4b0a1b2c3d4e5f7", PetId: "68a5c2d7e4b0a1b2c3d4e5f6", Price: "29.99", Status: genRouter.Placed},
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		outcomes []payments.Outcome
+		prepare  func()
+		want     genRouter.CheckoutCartResponseObject
+	}{
+		{
+			name: "empty cart",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().CheckoutCart(gomock.Any(), "1").Return(nil, dbErr.ErrNotFound)
+			},
+			want: genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "cart is empty",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "pet sold meanwhile",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().CheckoutCart(gomock.Any(), "1").
+					Return(nil, &dbErr.HintError{Key: "status", Err: dbErr.ErrConflict})
+			},
+			want: genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet is not available",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().CheckoutCart(gomock.Any(), "1").Return(nil, errors.New(""))
+			},
+			want: genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:     "payment declined",
+			ctx:      ctxU,
+			outcomes: []payments.Outcome{payments.Decline},
+			prepare: func() {
+				mockDBClient.EXPECT().CheckoutCart(gomock.Any(), "1").Return(orders, nil)
+				cancel := mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orders[0].Id).Return(nil)
+				// Pet is held in cart again only once its order is cancelled
+				mockDBClient.EXPECT().AddCartItem(gomock.Any(), "1", orders[0].PetId).
+					Return(&genRouter.CartItem{PetId: orders[0].PetId}, nil).After(cancel)
+			},
+			want: genRouter.CheckoutCartdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "payment was declined",
+				},
+				StatusCode: http.StatusPaymentRequired,
+			},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().CheckoutCart(gomock.Any(), "1").Return(orders, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orders[0].Id, gomock.Any()).Return(nil).Times(2)
+			},
+			want: func() genRouter.CheckoutCartResponseObject {
+				res := genRouter.CheckoutCart201JSONResponse{}
+				res.Body.Count = 1
+				res.Body.Orders = orders
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentProvider := payments.NewFake()
+			paymentProvider.Script(tt.outcomes...)
+			a := &APIHandler{
+				dbClient:        mockDBClient,
+				paymentProvider: paymentProvider,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.CheckoutCart(tt.ctx, genRouter.CheckoutCartRequestObject{})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.CheckoutCart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_SweepExpiredHolds(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Sweeper keeps going after a failed sweep & stops once ctx is done
+	gomock.InOrder(
+		mockDBClient.EXPECT().ReleaseExpiredHolds(gomock.Any()).Return(int64(0), errors.New("")),
+		mockDBClient.EXPECT().ReleaseExpiredHolds(gomock.Any()).DoAndReturn(func(context.Context) (int64, error) {
+			cancel()
+			return 2, nil
+		}),
+	)
+	// A tick racing with cancellation may sweep once more
+	mockDBClient.EXPECT().ReleaseExpiredHolds(gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+	a := &APIHandler{
+		dbClient: mockDBClient,
+	}
+	done := make(chan struct{})
+	go func() {
+		a.SweepExpiredHolds(ctx, time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("APIHandler.SweepExpiredHolds() did not stop after ctx was done")
+	}
+}
@@ -3,17 +3,29 @@ package apihandler
 import (
 	"context"
 
+	"github.com/vrv501/simple-api/internal/auth"
 	"github.com/vrv501/simple-api/internal/constants"
+	"github.com/vrv501/simple-api/internal/cursor"
 	"github.com/vrv501/simple-api/internal/db"
+	"github.com/vrv501/simple-api/internal/payments"
 )
 
 type APIHandler struct {
-	dbClient db.Handler
+	dbClient        db.Handler
+	tokenManager    *auth.TokenManager
+	cursorCodec     *cursor.Codec
+	paymentProvider payments.Provider
+	basePath        string // Prefix of every route, used to build URLs sent to clients
 }
 
-func NewAPIHandler(ctx context.Context) *APIHandler {
+func NewAPIHandler(ctx context.Context, tokenManager *auth.TokenManager,
+	cursorCodec *cursor.Codec, paymentProvider payments.Provider, basePath string) *APIHandler {
 	return &APIHandler{
-		dbClient: db.NewDBHandler(ctx),
+		dbClient:        db.NewDBHandler(ctx),
+		tokenManager:    tokenManager,
+		cursorCodec:     cursorCodec,
+		paymentProvider: paymentProvider,
+		basePath:        basePath,
 	}
 }
 
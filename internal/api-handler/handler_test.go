@@ -27,7 +27,7 @@ func TestNewAPIHandler(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewAPIHandler(context.Background()); !cmp.Equal(got, tt.want,
+			if got := NewAPIHandler(context.Background(), nil, nil, nil, ""); !cmp.Equal(got, tt.want,
 				cmpopts.IgnoreUnexported(APIHandler{})) {
 				t.Errorf("NewAPIHandler() = %v, want %v", got, tt.want)
 			}
@@ -2,34 +2,399 @@ package apihandler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+)
+
+const (
+	errMsgOrderNotFound  = "order not found"
+	errMsgInvalidOrderID = "invalid order ID"
+	errMsgDuplicatePetID = "duplicate pet id"
+
+	ordersCursorScope       = "orders"
+	sellerOrdersCursorScope = "seller-orders"
 )
 
 // Find user Orders using status.
 // (GET /store/orders)
-func (a *APIHandler) FindOrders(_ context.Context,
-	_ genRouter.FindOrdersRequestObject) (genRouter.FindOrdersResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) FindOrders(ctx context.Context,
+	request genRouter.FindOrdersRequestObject) (genRouter.FindOrdersResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.FindOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	params := request.Params
+	afterID, limit, err := a.decodePage(ordersCursorScope, params.Cursor, params.Limit)
+	if err != nil {
+		return genRouter.FindOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: errMsgInvalidCursor,
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	orders, count, nextID, err := a.dbClient.FindOrders(ctx, userID, &params, afterID, limit)
+	if err != nil {
+		if errors.Is(err, dbErr.ErrInvalidValue) {
+			return genRouter.FindOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to find orders")
+		return genRouter.FindOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	res := genRouter.FindOrders200JSONResponse{}
+	res.Body.Count = count
+	res.Body.Orders = orders
+	if nextID != "" {
+		res.Headers.XNextCursor = a.cursorCodec.Encode(ordersCursorScope, nextID)
+	}
+	return res, nil
 }
 
 // Place orders for pets.
 // (POST /store/orders)
-func (a *APIHandler) PlaceOrders(_ context.Context,
-	_ genRouter.PlaceOrdersRequestObject) (genRouter.PlaceOrdersResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) PlaceOrders(ctx context.Context,
+	request genRouter.PlaceOrdersRequestObject) (genRouter.PlaceOrdersResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.PlaceOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	petIDs := make([]string, len(*request.Body))
+	seen := make(map[string]struct{}, len(*request.Body))
+	for i, item := range *request.Body {
+		// A pet can be ordered only once, db would otherwise report the repeated one as not found
+		if _, ok = seen[item.PetId]; ok {
+			return genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgDuplicatePetID,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		}
+		seen[item.PetId] = struct{}{}
+		petIDs[i] = item.PetId
+	}
+
+	orders, err := a.dbClient.PlaceOrders(ctx, userID, petIDs)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		if errors.As(err, &hintErr) {
+			errMsg, statusCode := petPlacementError(hintErr)
+			return genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to place orders")
+		return genRouter.PlaceOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	if err = a.chargeOrders(ctx, userID, orders); err != nil {
+		if errMsg, statusCode, ok := paymentError(err); ok {
+			return genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to pay for orders")
+		return genRouter.PlaceOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully placed %d orders", len(orders))
+	res := genRouter.PlaceOrders201JSONResponse{}
+	res.Body.Count = len(orders)
+	res.Body.Orders = orders
+	return res, nil
 }
 
 // Delete user order by identifier.
 // (DELETE /store/orders/{orderId})
-func (a *APIHandler) DeleteOrder(_ context.Context,
-	_ genRouter.DeleteOrderRequestObject) (genRouter.DeleteOrderResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) DeleteOrder(ctx context.Context,
+	request genRouter.DeleteOrderRequestObject) (genRouter.DeleteOrderResponseObject, error) {
+	logger := log.Ctx(ctx)
+	ownerID, err := authorizeOwner(ctx, a.dbClient.GetOrderOwner, request.OrderId)
+	if err == nil {
+		err = a.dbClient.DeleteOrder(ctx, ownerID, request.OrderId)
+		err = a.refundCancelledOrder(ctx, request.OrderId, err)
+	}
+	if err != nil {
+		var (
+			hintErr       *dbErr.HintError
+			transitionErr *orderstate.TransitionError
+		)
+		if errMsg, statusCode, ok := paymentError(err); ok {
+			return genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsg,
+				},
+				StatusCode: statusCode,
+			}, nil
+		}
+		switch {
+		case errors.As(err, &transitionErr):
+			return genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: transitionErr.Error(),
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		case errors.As(err, &hintErr):
+			return genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidOrderID,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to cancel order %s", request.OrderId)
+		return genRouter.DeleteOrderdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully cancelled order %s", request.OrderId)
+	return genRouter.DeleteOrder204Response{}, nil
 }
 
 // Find user order by ID.
 // (GET /store/orders/{orderId})
-func (a *APIHandler) GetOrderByID(_ context.Context,
-	_ genRouter.GetOrderByIDRequestObject) (genRouter.GetOrderByIDResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) GetOrderByID(ctx context.Context,
+	request genRouter.GetOrderByIDRequestObject) (genRouter.GetOrderByIDResponseObject, error) {
+	logger := log.Ctx(ctx)
+	_, err := authorizeOwner(ctx, a.dbClient.GetOrderOwner, request.OrderId)
+	var res *genRouter.Order
+	if err == nil {
+		res, err = a.dbClient.GetOrder(ctx, request.OrderId)
+	}
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr), errors.Is(err, dbErr.ErrInvalidValue):
+			return genRouter.GetOrderByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidOrderID,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.GetOrderByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to get order %s", request.OrderId)
+		return genRouter.GetOrderByIDdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	return genRouter.GetOrderByID200JSONResponse(*res), nil
+}
+
+// Advance order fulfilment.
+// (PATCH /store/orders/{orderId}/status)
+func (a *APIHandler) UpdateOrderStatus(ctx context.Context,
+	request genRouter.UpdateOrderStatusRequestObject) (genRouter.UpdateOrderStatusResponseObject, error) {
+	logger := log.Ctx(ctx)
+	if (request.Body.TrackingNumber != nil || request.Body.Carrier != nil) &&
+		request.Body.Status != genRouter.FulfilmentShipped {
+		return genRouter.UpdateOrderStatusdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: "tracking number & carrier can only be set when order is shipped",
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	sellerID, err := authorizeOwner(ctx, a.dbClient.GetOrderSeller, request.OrderId)
+	var res *genRouter.Order
+	if err == nil {
+		res, err = a.dbClient.UpdateOrderStatus(ctx, sellerID, request.OrderId, request.Body)
+	}
+	if err != nil {
+		var (
+			hintErr       *dbErr.HintError
+			transitionErr *orderstate.TransitionError
+		)
+		switch {
+		case errors.As(err, &transitionErr):
+			return genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: transitionErr.Error(),
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		case errors.As(err, &hintErr):
+			return genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidOrderID,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to update status of order %s", request.OrderId)
+		return genRouter.UpdateOrderStatusdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully moved order %s to %s", request.OrderId, res.Status)
+	return genRouter.UpdateOrderStatus200JSONResponse(*res), nil
+}
+
+// Find orders placed against pets of the user.
+// (GET /sellers/me/orders)
+func (a *APIHandler) FindSellerOrders(ctx context.Context,
+	request genRouter.FindSellerOrdersRequestObject) (genRouter.FindSellerOrdersResponseObject, error) {
+	logger := log.Ctx(ctx)
+	sellerID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.FindSellerOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	params := genRouter.FindOrdersParams(request.Params)
+	afterID, limit, err := a.decodePage(sellerOrdersCursorScope, params.Cursor, params.Limit)
+	if err != nil {
+		return genRouter.FindSellerOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: errMsgInvalidCursor,
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	orders, count, nextID, err := a.dbClient.FindSellerOrders(ctx, sellerID, &params, afterID, limit)
+	if err != nil {
+		if errors.Is(err, dbErr.ErrInvalidValue) {
+			return genRouter.FindSellerOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to find seller orders")
+		return genRouter.FindSellerOrdersdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	res := genRouter.FindSellerOrders200JSONResponse{}
+	res.Body.Count = count
+	res.Body.Orders = orders
+	if nextID != "" {
+		res.Headers.XNextCursor = a.cursorCodec.Encode(sellerOrdersCursorScope, nextID)
+	}
+	return res, nil
+}
+
+// decodePage resolves ID to resume a listing of scope from & the page size
+func (a *APIHandler) decodePage(scope string, cursor *genRouter.Cursor, limit *genRouter.Limit) (string, int, error) {
+	pageSize := constants.DefaultPageSize
+	if limit != nil {
+		pageSize = *limit
+	}
+	if cursor == nil {
+		return "", pageSize, nil
+	}
+	afterID, err := a.cursorCodec.Decode(scope, *cursor)
+	return afterID, pageSize, err
+}
+
+// petPlacementError maps errors of ordering or holding pets to message & status code for clients
+func petPlacementError(hintErr *dbErr.HintError) (string, int) {
+	switch {
+	case errors.Is(hintErr.Err, dbErr.ErrNotFound):
+		return errMsgPetNotFound, http.StatusNotFound
+	case errors.Is(hintErr.Err, dbErr.ErrOwnPet):
+		return "own pets cannot be ordered", http.StatusUnprocessableEntity
+	case errors.Is(hintErr.Err, dbErr.ErrConflict):
+		return "pet is not available", http.StatusConflict
+	case errors.Is(hintErr.Err, dbErr.ErrLimitExceeded):
+		return fmt.Sprintf("cart can have at most %d pets", constants.MaxCartItems), http.StatusConflict
+	}
+	return "invalid pet ID", http.StatusBadRequest
 }
@@ -0,0 +1,865 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/generated/mockdb"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+func TestAPIHandler_FindOrders(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	cursorCodec := newTestCursorCodec(t)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	limit := 10
+	statuses := []genRouter.OrderStatus{genRouter.Placed}
+	validCursor := cursorCodec.Encode(ordersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	petsCursor := cursorCodec.Encode(petsCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	orders := []genRouter.Order{{Id: "68a5c2d7e4b0a1b2c3d4e5f7", PetId: "68a5c2d7e4b0a1b2c3d4e5f8", Status: genRouter.Placed}}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		params  genRouter.FindOrdersParams
+		prepare func()
+		want    genRouter.FindOrdersResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.FindOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:   "cursor of another listing",
+			ctx:    ctxU,
+			params: genRouter.FindOrdersParams{Cursor: &petsCursor},
+			want: genRouter.FindOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindOrders(gomock.Any(), "1", gomock.Any(), "", constants.DefaultPageSize).
+					Return(nil, 0, "", errors.New(""))
+			},
+			want: genRouter.FindOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:   "last page",
+			ctx:    ctxU,
+			params: genRouter.FindOrdersParams{Status: &statuses, Cursor: &validCursor, Limit: &limit},
+			prepare: func() {
+				mockDBClient.EXPECT().FindOrders(gomock.Any(), "1",
+					&genRouter.FindOrdersParams{Status: &statuses, Cursor: &validCursor, Limit: &limit},
+					"68a5c2d7e4b0a1b2c3d4e5f6", limit).
+					Return(orders, 11, "", nil)
+			},
+			want: func() genRouter.FindOrdersResponseObject {
+				res := genRouter.FindOrders200JSONResponse{}
+				res.Body.Count = 11
+				res.Body.Orders = orders
+				return res
+			}(),
+		},
+		{
+			name: "more pages",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindOrders(gomock.Any(), "1", gomock.Any(), "", constants.DefaultPageSize).
+					Return(orders, 21, "68a5c2d7e4b0a1b2c3d4e5f7", nil)
+			},
+			want: func() genRouter.FindOrdersResponseObject {
+				res := genRouter.FindOrders200JSONResponse{}
+				res.Body.Count = 21
+				res.Body.Orders = orders
+				res.Headers.XNextCursor = cursorCodec.Encode(ordersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f7")
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient:    mockDBClient,
+				cursorCodec: cursorCodec,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.FindOrders(tt.ctx, genRouter.FindOrdersRequestObject{Params: tt.params})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.FindOrders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_PlaceOrders(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	body := genRouter.PlaceOrdersJSONRequestBody{
+		{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"},
+		{PetId: "68a5c2d7e4b0a1b2c3d4e5f7"},
+	}
+	petIDs := []string{"68a5c2d7e4b0a1b2c3d4e5f6", "68a5c2d7e4b0a1b2c3d4e5f7"}
+	orders := []genRouter.Order{
+		{Id: "68a5c2d7e4b0a1b2c3d4e5f8", PetId: petIDs[0], Price: "29.99", Status: genRouter.Placed},
+		{Id: "68a5c2d7e4b0a1b2c3d4e5f9", PetId: petIDs[1], Price: "10", Status: genRouter.Placed},
+	}
+	freeOrders := []genRouter.Order{
+		{Id: "68a5c2d7e4b0a1b2c3d4e5f8", PetId: petIDs[0], Price: "0", Status: genRouter.Placed},
+		{Id: "68a5c2d7e4b0a1b2c3d4e5f9", PetId: petIDs[1], Price: "0.00", Status: genRouter.Placed},
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		body     genRouter.PlaceOrdersJSONRequestBody
+		outcomes []payments.Outcome
+		prepare  func()
+		want     genRouter.PlaceOrdersResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "duplicate pet id",
+			ctx:  ctxU,
+			body: genRouter.PlaceOrdersJSONRequestBody{body[0], body[1], body[0]},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgDuplicatePetID,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "invalid pet id",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).
+					Return(nil, &dbErr.HintError{Key: "pet_id", Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "pet not found",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).
+					Return(nil, &dbErr.HintError{Key: "pet_id", Err: dbErr.ErrNotFound})
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "own pet",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).
+					Return(nil, &dbErr.HintError{Key: "user_id", Err: dbErr.ErrOwnPet})
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "own pets cannot be ordered",
+				},
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name: "pet not available",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).
+					Return(nil, &dbErr.HintError{Key: "status", Err: dbErr.ErrConflict})
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet is not available",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).Return(nil, errors.New(""))
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:     "payment declined",
+			ctx:      ctxU,
+			outcomes: []payments.Outcome{payments.Succeed, payments.Succeed, payments.Decline},
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).Return(orders, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orders[0].Id, gomock.Any()).Return(nil).Times(2)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orders[0].Id).Return(nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orders[1].Id).Return(nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orders[0].Id, &payments.Intent{
+					ID:       "fake_pi_000001",
+					Provider: payments.FakeProviderName,
+					Status:   payments.StatusRefunded,
+					Amount:   2999,
+					Currency: constants.PaymentCurrency,
+				}).Return(nil)
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "payment was declined",
+				},
+				StatusCode: http.StatusPaymentRequired,
+			},
+		},
+		{
+			name:     "payment timed out",
+			ctx:      ctxU,
+			outcomes: []payments.Outcome{payments.Timeout},
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).Return(orders, nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orders[0].Id).Return(nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orders[1].Id).Return(nil)
+			},
+			want: genRouter.PlaceOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "payment provider timed out, try again",
+				},
+				StatusCode: http.StatusGatewayTimeout,
+			},
+		},
+		{
+			name: "free pets are not charged",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).Return(freeOrders, nil)
+			},
+			want: func() genRouter.PlaceOrdersResponseObject {
+				res := genRouter.PlaceOrders201JSONResponse{}
+				res.Body.Count = 2
+				res.Body.Orders = freeOrders
+				return res
+			}(),
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().PlaceOrders(gomock.Any(), "1", petIDs).Return(orders, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+			},
+			want: func() genRouter.PlaceOrdersResponseObject {
+				res := genRouter.PlaceOrders201JSONResponse{}
+				res.Body.Count = 2
+				res.Body.Orders = orders
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentProvider := payments.NewFake()
+			paymentProvider.Script(tt.outcomes...)
+			a := &APIHandler{
+				dbClient:        mockDBClient,
+				paymentProvider: paymentProvider,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			reqBody := body
+			if tt.body != nil {
+				reqBody = tt.body
+			}
+			got, _ := a.PlaceOrders(tt.ctx, genRouter.PlaceOrdersRequestObject{Body: &reqBody})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.PlaceOrders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_GetOrderByID(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	order := &genRouter.Order{Id: "68a5c2d7e4b0a1b2c3d4e5f6", PetId: "68a5c2d7e4b0a1b2c3d4e5f7", Status: genRouter.Placed}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		prepare func()
+		want    genRouter.GetOrderByIDResponseObject
+	}{
+		{
+			name: "invalid order id",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).
+					Return("", &dbErr.HintError{Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.GetOrderByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidOrderID,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "order of another user",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("2", nil)
+			},
+			want: genRouter.GetOrderByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().GetOrder(gomock.Any(), gomock.Any()).Return(nil, errors.New(""))
+			},
+			want: genRouter.GetOrderByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "admin reads order of another user",
+			ctx:  contextKeys.ContextWithUserRole(ctxU, string(genRouter.Admin)),
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("2", nil)
+				mockDBClient.EXPECT().GetOrder(gomock.Any(), order.Id).Return(order, nil)
+			},
+			want: genRouter.GetOrderByID200JSONResponse(*order),
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().GetOrder(gomock.Any(), order.Id).Return(order, nil)
+			},
+			want: genRouter.GetOrderByID200JSONResponse(*order),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.GetOrderByID(tt.ctx, genRouter.GetOrderByIDRequestObject{OrderId: order.Id})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.GetOrderByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_DeleteOrder(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	orderID := "68a5c2d7e4b0a1b2c3d4e5f6"
+	// Each test case starts with the order paid using this intent
+	paidIntent := &payments.Intent{
+		ID:       "fake_pi_000001",
+		Provider: payments.FakeProviderName,
+		Status:   payments.StatusCaptured,
+		Amount:   2999,
+		Currency: constants.PaymentCurrency,
+	}
+	refundedIntent := *paidIntent
+	refundedIntent.Status = payments.StatusRefunded
+	// Intent of a provider which lost it, as the fake provider does on restart
+	lostIntent := *paidIntent
+	lostIntent.ID = "fake_pi_000099"
+	lostRefundedIntent := lostIntent
+	lostRefundedIntent.Status = payments.StatusRefunded
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		outcomes []payments.Outcome
+		prepare  func()
+		want     genRouter.DeleteOrderResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "order of another user",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("2", nil)
+			},
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "shipped order cannot be cancelled",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", gomock.Any()).
+					Return(&orderstate.TransitionError{From: genRouter.Shipped, To: genRouter.Cancelled})
+			},
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "order cannot move from shipped to cancelled",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", gomock.Any()).Return(errors.New(""))
+			},
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:     "refund timed out",
+			ctx:      ctxU,
+			outcomes: []payments.Outcome{payments.Timeout},
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).Return(nil)
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(paidIntent, nil)
+			},
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "payment provider timed out, try again",
+				},
+				StatusCode: http.StatusGatewayTimeout,
+			},
+		},
+		{
+			name: "cancelling again retries refund",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).
+					Return(&orderstate.TransitionError{From: genRouter.Cancelled, To: genRouter.Cancelled})
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(paidIntent, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orderID, &refundedIntent).Return(nil)
+			},
+			want: genRouter.DeleteOrder204Response{},
+		},
+		{
+			name: "cancelled order already refunded",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).
+					Return(&orderstate.TransitionError{From: genRouter.Cancelled, To: genRouter.Cancelled})
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(&refundedIntent, nil)
+			},
+			want: genRouter.DeleteOrderdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "order cannot move from cancelled to cancelled",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "intent lost by provider is treated as refunded",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).Return(nil)
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(&lostIntent, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orderID, &lostRefundedIntent).Return(nil)
+			},
+			want: genRouter.DeleteOrder204Response{},
+		},
+		{
+			name: "success without payment",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).Return(nil)
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(nil, dbErr.ErrNotFound)
+			},
+			want: genRouter.DeleteOrder204Response{},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeleteOrder(gomock.Any(), "1", orderID).Return(nil)
+				mockDBClient.EXPECT().GetPaymentIntent(gomock.Any(), orderID).Return(paidIntent, nil)
+				mockDBClient.EXPECT().SavePaymentIntent(gomock.Any(), orderID, &refundedIntent).Return(nil)
+			},
+			want: genRouter.DeleteOrder204Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentProvider := payments.NewFake()
+			intent, _ := paymentProvider.Authorize(context.Background(), payments.AuthorizeRequest{
+				OrderID:  orderID,
+				Amount:   paidIntent.Amount,
+				Currency: paidIntent.Currency,
+			})
+			_, _ = paymentProvider.Capture(context.Background(), intent.ID)
+			paymentProvider.Script(tt.outcomes...)
+			a := &APIHandler{
+				dbClient:        mockDBClient,
+				paymentProvider: paymentProvider,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.DeleteOrder(tt.ctx, genRouter.DeleteOrderRequestObject{OrderId: orderID})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.DeleteOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_UpdateOrderStatus(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	trackingNumber, carrier := "1Z999AA10123456784", "UPS"
+	shipped := &genRouter.UpdateOrderStatusJSONRequestBody{
+		Status:         genRouter.FulfilmentShipped,
+		TrackingNumber: &trackingNumber,
+		Carrier:        &carrier,
+	}
+	processing := &genRouter.UpdateOrderStatusJSONRequestBody{Status: genRouter.FulfilmentProcessing}
+	order := &genRouter.Order{
+		Id:             "68a5c2d7e4b0a1b2c3d4e5f6",
+		PetId:          "68a5c2d7e4b0a1b2c3d4e5f7",
+		Status:         genRouter.Shipped,
+		TrackingNumber: &trackingNumber,
+		Carrier:        &carrier,
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		body    *genRouter.UpdateOrderStatusJSONRequestBody
+		prepare func()
+		want    genRouter.UpdateOrderStatusResponseObject
+	}{
+		{
+			name: "tracking details without shipping",
+			ctx:  ctxU,
+			body: &genRouter.UpdateOrderStatusJSONRequestBody{
+				Status:  genRouter.FulfilmentDelivered,
+				Carrier: &carrier,
+			},
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "tracking number & carrier can only be set when order is shipped",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			body: processing,
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "invalid order id",
+			ctx:  ctxU,
+			body: processing,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).
+					Return("", &dbErr.HintError{Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidOrderID,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "buyer cannot update order",
+			ctx:  ctxU,
+			body: processing,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).Return("2", nil)
+			},
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgOrderNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "illegal transition",
+			ctx:  ctxU,
+			body: shipped,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().UpdateOrderStatus(gomock.Any(), "1", gomock.Any(), shipped).
+					Return(nil, &orderstate.TransitionError{From: genRouter.Placed, To: genRouter.Shipped})
+			},
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "order cannot move from placed to shipped",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			body: processing,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().UpdateOrderStatus(gomock.Any(), "1", gomock.Any(), processing).
+					Return(nil, errors.New(""))
+			},
+			want: genRouter.UpdateOrderStatusdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "admin updates order of another seller",
+			ctx:  contextKeys.ContextWithUserRole(ctxU, string(genRouter.Admin)),
+			body: shipped,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).Return("2", nil)
+				mockDBClient.EXPECT().UpdateOrderStatus(gomock.Any(), "2", order.Id, shipped).Return(order, nil)
+			},
+			want: genRouter.UpdateOrderStatus200JSONResponse(*order),
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			body: shipped,
+			prepare: func() {
+				mockDBClient.EXPECT().GetOrderSeller(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().UpdateOrderStatus(gomock.Any(), "1", order.Id, shipped).Return(order, nil)
+			},
+			want: genRouter.UpdateOrderStatus200JSONResponse(*order),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.UpdateOrderStatus(tt.ctx, genRouter.UpdateOrderStatusRequestObject{
+				OrderId: order.Id,
+				Body:    tt.body,
+			})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.UpdateOrderStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_FindSellerOrders(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	cursorCodec := newTestCursorCodec(t)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	statuses := []genRouter.OrderStatus{genRouter.Processing}
+	validCursor := cursorCodec.Encode(sellerOrdersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	buyerCursor := cursorCodec.Encode(ordersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	orders := []genRouter.Order{{Id: "68a5c2d7e4b0a1b2c3d4e5f7", PetId: "68a5c2d7e4b0a1b2c3d4e5f8", Status: genRouter.Processing}}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		params  genRouter.FindSellerOrdersParams
+		prepare func()
+		want    genRouter.FindSellerOrdersResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.FindSellerOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:   "cursor of buyer listing",
+			ctx:    ctxU,
+			params: genRouter.FindSellerOrdersParams{Cursor: &buyerCursor},
+			want: genRouter.FindSellerOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindSellerOrders(gomock.Any(), "1", gomock.Any(), "", constants.DefaultPageSize).
+					Return(nil, 0, "", errors.New(""))
+			},
+			want: genRouter.FindSellerOrdersdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:   "last page",
+			ctx:    ctxU,
+			params: genRouter.FindSellerOrdersParams{Status: &statuses, Cursor: &validCursor},
+			prepare: func() {
+				mockDBClient.EXPECT().FindSellerOrders(gomock.Any(), "1",
+					&genRouter.FindOrdersParams{Status: &statuses, Cursor: &validCursor},
+					"68a5c2d7e4b0a1b2c3d4e5f6", constants.DefaultPageSize).
+					Return(orders, 21, "", nil)
+			},
+			want: func() genRouter.FindSellerOrdersResponseObject {
+				res := genRouter.FindSellerOrders200JSONResponse{}
+				res.Body.Count = 21
+				res.Body.Orders = orders
+				return res
+			}(),
+		},
+		{
+			name: "more pages",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().FindSellerOrders(gomock.Any(), "1", gomock.Any(), "", constants.DefaultPageSize).
+					Return(orders, 21, "68a5c2d7e4b0a1b2c3d4e5f7", nil)
+			},
+			want: func() genRouter.FindSellerOrdersResponseObject {
+				res := genRouter.FindSellerOrders200JSONResponse{}
+				res.Body.Count = 21
+				res.Body.Orders = orders
+				res.Headers.XNextCursor = cursorCodec.Encode(sellerOrdersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f7")
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient:    mockDBClient,
+				cursorCodec: cursorCodec,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.FindSellerOrders(tt.ctx, genRouter.FindSellerOrdersRequestObject{Params: tt.params})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.FindSellerOrders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package apihandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+// chargeOrders authorizes & captures payment of each of the orders just placed by userID.
+// Either all orders are paid or all of them are cancelled, refunding payments made so far
+func (a *APIHandler) chargeOrders(ctx context.Context, userID string, orders []genRouter.Order) error {
+	intents := make(map[string]*payments.Intent, len(orders))
+	for i := range orders {
+		intent, err := a.chargeOrder(ctx, &orders[i])
+		if intent != nil {
+			intents[orders[i].Id] = intent
+		}
+		if err != nil {
+			a.rollbackOrders(ctx, userID, orders, intents)
+			return err
+		}
+	}
+	return nil
+}
+
+// chargeOrder returns the intent of the order once authorized, even if capturing it failed.
+// Free pets have nothing to charge, hence their orders have no intent
+func (a *APIHandler) chargeOrder(ctx context.Context, o *genRouter.Order) (*payments.Intent, error) {
+	amount, err := payments.ParseAmount(o.Price)
+	if err != nil || amount == 0 {
+		return nil, err
+	}
+	intent, err := a.paymentProvider.Authorize(ctx, payments.AuthorizeRequest{
+		OrderID:  o.Id,
+		Amount:   amount,
+		Currency: constants.PaymentCurrency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = a.dbClient.SavePaymentIntent(ctx, o.Id, intent); err != nil {
+		return intent, err
+	}
+
+	captured, err := a.paymentProvider.Capture(ctx, intent.ID)
+	if err != nil {
+		return intent, err
+	}
+	return captured, a.dbClient.SavePaymentIntent(ctx, o.Id, captured)
+}
+
+// rollbackOrders cancels orders & refunds their intents. Failures are only logged,
+// since the client is already being told that placing orders failed
+func (a *APIHandler) rollbackOrders(ctx context.Context, userID string, orders []genRouter.Order,
+	intents map[string]*payments.Intent) {
+	logger := log.Ctx(ctx)
+	for i := range orders {
+		if err := a.dbClient.DeleteOrder(ctx, userID, orders[i].Id); err != nil {
+			logger.Error().Err(err).Msgf("Failed to cancel unpaid order %s", orders[i].Id)
+		}
+	}
+	for orderID, intent := range intents {
+		refunded, err := a.paymentProvider.Refund(ctx, intent.ID)
+		if err == nil {
+			err = a.dbClient.SavePaymentIntent(ctx, orderID, refunded)
+		}
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to refund payment %s of order %s", intent.ID, orderID)
+		}
+	}
+}
+
+// refundCancelledOrder refunds payment of an order once cancelErr shows it got cancelled.
+// Cancelling an already cancelled order retries its refund, so refunds which failed earlier aren't lost
+func (a *APIHandler) refundCancelledOrder(ctx context.Context, orderID string, cancelErr error) error {
+	var transitionErr *orderstate.TransitionError
+	if cancelErr != nil &&
+		(!errors.As(cancelErr, &transitionErr) || transitionErr.From != genRouter.Cancelled) {
+		return cancelErr
+	}
+
+	intent, err := a.dbClient.GetPaymentIntent(ctx, orderID)
+	if errors.Is(err, dbErr.ErrNotFound) || (err == nil && intent.Status == payments.StatusRefunded) {
+		// Nothing to refund, cancelling again stays a conflict
+		return cancelErr
+	}
+	if err != nil {
+		return err
+	}
+
+	refunded, err := a.paymentProvider.Refund(ctx, intent.ID)
+	if errors.Is(err, payments.ErrIntentNotFound) {
+		// Provider no longer knows the intent, for instance fake provider lost it on restart,
+		// hence there is nothing left to refund
+		lost := *intent
+		lost.Status = payments.StatusRefunded
+		refunded, err = &lost, nil
+	}
+	if err != nil {
+		return err
+	}
+	return a.dbClient.SavePaymentIntent(ctx, orderID, refunded)
+}
+
+// paymentError maps errors of payment provider to message & status code for clients.
+// Returns false for errors which aren't caused by the provider
+func paymentError(err error) (string, int, bool) {
+	switch {
+	case errors.Is(err, payments.ErrDeclined):
+		return "payment was declined", http.StatusPaymentRequired, true
+	case errors.Is(err, payments.ErrTimeout):
+		return "payment provider timed out, try again", http.StatusGatewayTimeout, true
+	}
+	return "", 0, false
+}
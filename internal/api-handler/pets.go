@@ -5,138 +5,191 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 
-	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/rs/zerolog/log"
 
 	"github.com/vrv501/simple-api/internal/constants"
 	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
 	dbErr "github.com/vrv501/simple-api/internal/db/errors"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/rendition"
 )
 
 const (
 	errMsgIncorrectReqEncoding = "request body is not properly encoded"
+	errMsgInvalidCursor        = "invalid cursor"
+	errMsgPetNotFound          = "pet not found"
+
+	petsCursorScope = "pets"
 )
 
 // Find Pets using name, status, tags.
 // (GET /pets)
-func (a *APIHandler) FindPets(_ context.Context,
-	_ genRouter.FindPetsRequestObject) (genRouter.FindPetsResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) FindPets(ctx context.Context,
+	request genRouter.FindPetsRequestObject) (genRouter.FindPetsResponseObject, error) {
+	logger := log.Ctx(ctx)
+	params := request.Params
+	if params.Name == nil && params.Status == nil && params.Tags == nil {
+		return genRouter.FindPetsdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: "atleast one of name, status or tags is required",
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	afterID, limit, err := a.decodePage(petsCursorScope, params.Cursor, params.Limit)
+	if err != nil {
+		return genRouter.FindPetsdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: errMsgInvalidCursor,
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	pets, count, nextID, err := a.dbClient.FindPets(ctx, &params, afterID, limit)
+	if err != nil {
+		if errors.Is(err, dbErr.ErrInvalidValue) {
+			return genRouter.FindPetsdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to find pets")
+		return genRouter.FindPetsdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	res := genRouter.FindPets200JSONResponse{}
+	res.Body.Count = count
+	for i := range pets {
+		a.setPhotoURLs(&pets[i])
+	}
+	res.Body.Pets = pets
+	if nextID != "" {
+		res.Headers.XNextCursor = a.cursorCodec.Encode(petsCursorScope, nextID)
+	}
+	return res, nil
 }
 
-func validateImage(r io.Reader) ([]byte, error) {
+// validateImage reads a JPEG within limits of size & resolution from r along with its decoded image
+func validateImage(r io.Reader) ([]byte, image.Image, error) {
 	imgData := make([]byte, 1+constants.MaxImgSize)
 	n, err := io.ReadFull(r, imgData)
 	if err != nil &&
 		!errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
-		return nil, errors.New("image data is corrupted")
+		return nil, nil, errors.New("image data is corrupted")
 	}
 	if n == 0 || n > constants.MaxImgSize {
-		return nil, errors.New("images should have min size 1B and max size 250KB")
+		return nil, nil, errors.New("images should have min size 1B and max size 250KB")
 	}
 	imgData = imgData[:n]
 
 	reader := bytes.NewReader(imgData)
 	imgDetails, _, err := image.DecodeConfig(reader)
 	if err != nil {
-		return nil, errors.New("jpeg image is corrupted")
+		return nil, nil, errors.New("jpeg image is corrupted")
 	}
 	if imgDetails.Width < 256 || imgDetails.Width > 1920 ||
 		imgDetails.Height < 256 || imgDetails.Height > 1080 {
-		return nil,
+		return nil, nil,
 			errors.New("supported min resolution for images is 256x256px & max is 1920x1080px")
 	}
 
 	reader.Seek(0, io.SeekStart)
-	_, err = jpeg.Decode(reader)
+	img, err := jpeg.Decode(reader)
 	if err != nil {
-		return nil, errors.New("jpeg image is corrupted")
+		return nil, nil, errors.New("jpeg image is corrupted")
 	}
-	return imgData, nil
+	return imgData, img, nil
 }
 
-// Add new pet to the store.
-// (POST /pets)
-func (a *APIHandler) AddPet(ctx context.Context,
-	request genRouter.AddPetRequestObject) (genRouter.AddPetResponseObject, error) {
-	logger := log.Ctx(ctx)
-	userID, ok := contextKeys.UserIDFromContext(ctx)
-	if !ok {
-		logger.Error().Msg(errMsgUserIDNotFound)
-		return genRouter.AddPetdefaultJSONResponse{
-			Body: genRouter.Generic{
-				Message: http.StatusText(http.StatusInternalServerError),
-			},
-			StatusCode: http.StatusInternalServerError,
-		}, nil
-	}
-
+// readPetMultipart reads pet details & renditions of photos shared by AddPet, ReplacePet & UploadPetImage.
+// pet field is rejected unless withPet is set. Returned errors are safe to be sent to clients
+func readPetMultipart(body *multipart.Reader, withPet bool) (*genRouter.Pet, []rendition.Set, error) {
 	var (
-		part     *multipart.Part
-		err      error
-		petData  genRouter.Pet
-		oapifile openapi_types.File
-		mpReq    = genRouter.AddPetMultipartBody{Photos: genRouter.PetPhotos{}}
+		part    *multipart.Part
+		err     error
+		petData genRouter.Pet
+		photos  = []rendition.Set{}
 	)
 
 	// Read multipart form data
 	for {
-		part, err = request.Body.NextPart()
+		part, err = body.NextPart()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			logger.Error().Err(err).Msg("failed to read multipart data")
-			return genRouter.AddPetdefaultJSONResponse{
-				Body: genRouter.Generic{
-					Message: errMsgIncorrectReqEncoding,
-				},
-				StatusCode: http.StatusBadRequest,
-			}, nil
+			return nil, nil, errors.New(errMsgIncorrectReqEncoding)
 		}
 
 		// Decode based on form Name
-		switch part.FormName() {
-		case "pet":
+		switch formName := part.FormName(); {
+		case formName == "pet" && withPet:
 			if err = json.NewDecoder(part).Decode(&petData); err != nil {
-				return genRouter.AddPetdefaultJSONResponse{
-					Body: genRouter.Generic{
-						Message: errMsgIncorrectReqEncoding,
-					},
-					StatusCode: http.StatusBadRequest,
-				}, nil
+				return nil, nil, errors.New(errMsgIncorrectReqEncoding)
+			}
+		case formName == "photos":
+			imgData, img, errS := validateImage(part)
+			if errS != nil {
+				return nil, nil, errS
 			}
-			mpReq.Pet = petData
-		case "photos":
-			imgData, errS := validateImage(part)
+			// Renditions are scaled down from the image already decoded for validation
+			set, errS := rendition.New(imgData, img)
 			if errS != nil {
-				return genRouter.AddPetdefaultJSONResponse{
-					Body: genRouter.Generic{
-						Message: errS.Error(),
-					},
-					StatusCode: http.StatusBadRequest,
-				}, nil
+				return nil, nil, errors.New("image could not be scaled down")
 			}
-			oapifile.InitFromBytes(imgData, part.FileName())
-			mpReq.Photos = append(mpReq.Photos, oapifile)
+			photos = append(photos, set)
 		default:
-			return genRouter.AddPetdefaultJSONResponse{
-				Body: genRouter.Generic{
-					Message: "unknown multipart field " + part.FormName(),
-				},
-				StatusCode: http.StatusBadRequest,
-			}, nil
+			return nil, nil, errors.New("unknown multipart field " + formName)
 		}
 	}
+	return &petData, photos, nil
+}
 
-	err = a.dbClient.AddPet(ctx, userID, &mpReq)
+// Add new pet to the store.
+// (POST /pets)
+func (a *APIHandler) AddPet(ctx context.Context,
+	request genRouter.AddPetRequestObject) (genRouter.AddPetResponseObject, error) {
+	logger := log.Ctx(ctx)
+	userID, ok := contextKeys.UserIDFromContext(ctx)
+	if !ok {
+		logger.Error().Msg(errMsgUserIDNotFound)
+		return genRouter.AddPetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	petReq, photos, err := readPetMultipart(request.Body, true)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Rejected pet multipart data")
+		return genRouter.AddPetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: err.Error(),
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	err = a.dbClient.AddPet(ctx, userID, petReq, photos)
 	if err != nil {
 		var dberr *dbErr.HintError
 		if errors.As(err, &dberr) {
@@ -180,41 +233,275 @@ func (a *APIHandler) AddPet(ctx context.Context,
 
 // Delete a pet.
 // (DELETE /pets/{petId})
-func (a *APIHandler) DeletePet(_ context.Context,
-	_ genRouter.DeletePetRequestObject) (genRouter.DeletePetResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) DeletePet(ctx context.Context,
+	request genRouter.DeletePetRequestObject) (genRouter.DeletePetResponseObject, error) {
+	logger := log.Ctx(ctx)
+	ownerID, err := authorizeOwner(ctx, a.dbClient.GetPetOwner, request.PetId)
+	if err == nil {
+		err = a.dbClient.DeletePet(ctx, ownerID, request.PetId)
+	}
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr) && errors.Is(hintErr.Err, dbErr.ErrForeignKeyViolation):
+			return genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "Pet cannot be deleted as there are pending " + hintErr.Key,
+				},
+				StatusCode: http.StatusUnprocessableEntity,
+			}, nil
+		case errors.As(err, &hintErr):
+			return genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to soft-delete pet %s", request.PetId)
+		return genRouter.DeletePetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully soft-deleted pet %s", request.PetId)
+	return genRouter.DeletePet204Response{}, nil
 }
 
 // Find pet by ID.
 // (GET /pets/{petId})
-func (a *APIHandler) GetPetByID(_ context.Context,
-	_ genRouter.GetPetByIDRequestObject) (genRouter.GetPetByIDResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) GetPetByID(ctx context.Context,
+	request genRouter.GetPetByIDRequestObject) (genRouter.GetPetByIDResponseObject, error) {
+	logger := log.Ctx(ctx)
+	res, err := a.dbClient.GetPet(ctx, request.PetId)
+	if err != nil {
+		switch {
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.GetPetByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		case errors.Is(err, dbErr.ErrInvalidValue):
+			return genRouter.GetPetByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("failed to get pet")
+		return genRouter.GetPetByIDdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+	a.setPhotoURLs(res)
+	return genRouter.GetPetByID200JSONResponse(*res), nil
+}
+
+// setPhotoURLs sets URLs of every rendition of photos of pet, so that clients can pick one to download
+func (a *APIHandler) setPhotoURLs(pet *genRouter.PetWithMetadata) {
+	pet.Photos = make([]genRouter.PhotoUrls, len(pet.PhotoIds))
+	for i, imageID := range pet.PhotoIds {
+		imageURL := a.basePath + "/images/" + url.PathEscape(imageID) + "?size="
+		pet.Photos[i] = genRouter.PhotoUrls{
+			Small:    imageURL + string(genRouter.Small),
+			Medium:   imageURL + string(genRouter.Medium),
+			Original: imageURL + string(genRouter.Original),
+		}
+	}
 }
 
 // Replace existing pet data using Id.
 // (PUT /pets/{petId})
-func (a *APIHandler) ReplacePet(_ context.Context,
-	_ genRouter.ReplacePetRequestObject) (genRouter.ReplacePetResponseObject, error) {
-	panic("not implemented") // TODO: Implement
+func (a *APIHandler) ReplacePet(ctx context.Context,
+	request genRouter.ReplacePetRequestObject) (genRouter.ReplacePetResponseObject, error) {
+	logger := log.Ctx(ctx)
+	ownerID, err := authorizeOwner(ctx, a.dbClient.GetPetOwner, request.PetId)
+	if err != nil {
+		var invalidErr *dbErr.HintError
+		switch {
+		case errors.As(err, &invalidErr):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to authorize pet owner")
+		return genRouter.ReplacePetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	petReq, photos, err := readPetMultipart(request.Body, true)
+	if err == nil && petReq.Status != nil && *petReq.Status == genRouter.Sold {
+		// Sold pets cannot be replaced, hence an owner could never undo it
+		err = errors.New("pets are marked sold only by placing orders")
+	}
+	if err != nil {
+		logger.Debug().Err(err).Msg("Rejected pet multipart data")
+		return genRouter.ReplacePetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: err.Error(),
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	err = a.dbClient.ReplacePet(ctx, ownerID, request.PetId, petReq, photos)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr) && errors.Is(hintErr.Err, dbErr.ErrInvalidValue):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid value for " + hintErr.Key,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.As(err, &hintErr) && errors.Is(hintErr.Err, dbErr.ErrNotFound):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: hintErr.Key + " not found",
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		case errors.As(err, &hintErr) && errors.Is(hintErr.Err, dbErr.ErrConflict):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "sold pets cannot be replaced",
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		case errors.Is(err, dbErr.ErrConflict):
+			return genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "Similar pet already exists",
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to replace pet %s", request.PetId)
+		return genRouter.ReplacePetdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	logger.Info().Msgf("Successfully replaced pet %s", request.PetId)
+	return genRouter.ReplacePet202Response{}, nil
 }
 
 // Upload a new image for a pet.
 // (POST /pets/{petId}/images)
-func (a *APIHandler) UploadPetImage(_ context.Context,
-	_ genRouter.UploadPetImageRequestObject) (genRouter.UploadPetImageResponseObject, error) {
-	panic("not implemented") // TODO: Implement
-}
-
-// Delete a pet image.
-// (DELETE /images/{imageId})
-func (a *APIHandler) DeletePetImage(ctx context.Context,
-	request genRouter.DeletePetImageRequestObject) (genRouter.DeletePetImageResponseObject, error) {
+func (a *APIHandler) UploadPetImage(ctx context.Context,
+	request genRouter.UploadPetImageRequestObject) (genRouter.UploadPetImageResponseObject, error) {
 	logger := log.Ctx(ctx)
-	userID, ok := contextKeys.UserIDFromContext(ctx)
-	if !ok {
-		logger.Error().Msg(errMsgUserIDNotFound)
-		return genRouter.DeletePetImagedefaultJSONResponse{
+	ownerID, err := authorizeOwner(ctx, a.dbClient.GetPetOwner, request.PetId)
+	if err != nil {
+		var invalidErr *dbErr.HintError
+		switch {
+		case errors.As(err, &invalidErr):
+			return genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msg("Failed to authorize pet owner")
+		return genRouter.UploadPetImagedefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+			StatusCode: http.StatusInternalServerError,
+		}, nil
+	}
+
+	_, photos, err := readPetMultipart(request.Body, false)
+	if err == nil && len(photos) == 0 {
+		err = errors.New("atleast one photo is required")
+	}
+	if err != nil {
+		logger.Debug().Err(err).Msg("Rejected pet image multipart data")
+		return genRouter.UploadPetImagedefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: err.Error(),
+			},
+			StatusCode: http.StatusBadRequest,
+		}, nil
+	}
+
+	imageIDs, err := a.dbClient.AddPetImages(ctx, ownerID, request.PetId, photos)
+	if err != nil {
+		var hintErr *dbErr.HintError
+		switch {
+		case errors.As(err, &hintErr) && errors.Is(hintErr.Err, dbErr.ErrLimitExceeded):
+			return genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: fmt.Sprintf("pet can have at most %d images", constants.MaxPetImages),
+				},
+				StatusCode: http.StatusConflict,
+			}, nil
+		case errors.As(err, &hintErr):
+			return genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid value for " + hintErr.Key,
+				},
+				StatusCode: http.StatusBadRequest,
+			}, nil
+		case errors.Is(err, dbErr.ErrNotFound):
+			return genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			}, nil
+		}
+		logger.Error().Err(err).Msgf("Failed to add images to pet %s", request.PetId)
+		return genRouter.UploadPetImagedefaultJSONResponse{
 			Body: genRouter.Generic{
 				Message: http.StatusText(http.StatusInternalServerError),
 			},
@@ -222,7 +509,19 @@ func (a *APIHandler) DeletePetImage(ctx context.Context,
 		}, nil
 	}
 
-	err := a.dbClient.DeletePetImage(ctx, userID, request.ImageId)
+	logger.Info().Msgf("Successfully added %d images to pet %s", len(imageIDs), request.PetId)
+	return genRouter.UploadPetImage201JSONResponse{PhotoIds: imageIDs}, nil
+}
+
+// Delete a pet image.
+// (DELETE /images/{imageId})
+func (a *APIHandler) DeletePetImage(ctx context.Context,
+	request genRouter.DeletePetImageRequestObject) (genRouter.DeletePetImageResponseObject, error) {
+	logger := log.Ctx(ctx)
+	ownerID, err := authorizeOwner(ctx, a.dbClient.GetImageOwner, request.ImageId)
+	if err == nil {
+		err = a.dbClient.DeletePetImage(ctx, ownerID, request.ImageId)
+	}
 	if err != nil {
 		var invalidErr *dbErr.HintError
 		if errors.As(err, &invalidErr) {
@@ -258,7 +557,11 @@ func (a *APIHandler) DeletePetImage(ctx context.Context,
 func (a *APIHandler) GetImageByID(ctx context.Context,
 	request genRouter.GetImageByIDRequestObject) (genRouter.GetImageByIDResponseObject, error) {
 	logger := log.Ctx(ctx)
-	reader, contentLength, err := a.dbClient.GetPetImage(ctx, request.ImageId)
+	size := genRouter.Original
+	if request.Params.Size != nil {
+		size = *request.Params.Size
+	}
+	reader, contentLength, err := a.dbClient.GetPetImage(ctx, request.ImageId, size)
 	if err != nil {
 		switch {
 		case errors.Is(err, dbErr.ErrNotFound):
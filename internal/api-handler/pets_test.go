@@ -10,6 +10,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strings"
 	"testing"
 
@@ -18,9 +19,11 @@ import (
 
 	"github.com/vrv501/simple-api/internal/constants"
 	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+	"github.com/vrv501/simple-api/internal/cursor"
 	dbErr "github.com/vrv501/simple-api/internal/db/errors"
 	"github.com/vrv501/simple-api/internal/generated/mockdb"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/rendition"
 )
 
 // createTestJPEG creates a simple JPEG image with the specified dimensions for testing
@@ -101,11 +104,249 @@ func createMultipartReader(t *testing.T, stringFields map[string]string, fileFie
 	return multipart.NewReader(&body, writer.Boundary())
 }
 
+// newTestCursorCodec creates cursor codec with a fixed key for testing
+func newTestCursorCodec(t *testing.T) *cursor.Codec {
+	t.Helper()
+	codec, err := cursor.NewCodec([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Failed to create cursor codec: %v", err)
+	}
+	return codec
+}
+
+func TestAPIHandler_FindPets(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	cursorCodec := newTestCursorCodec(t)
+	name := "max"
+	limit := 10
+	validCursor := cursorCodec.Encode(petsCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	ordersCursor := cursorCodec.Encode(ordersCursorScope, "68a5c2d7e4b0a1b2c3d4e5f6")
+	pets := []genRouter.PetWithMetadata{{Id: "68a5c2d7e4b0a1b2c3d4e5f7", Name: name, PhotoIds: []string{"1"}}}
+	wantPets := []genRouter.PetWithMetadata{{
+		Id:       "68a5c2d7e4b0a1b2c3d4e5f7",
+		Name:     name,
+		PhotoIds: []string{"1"},
+		Photos: []genRouter.PhotoUrls{{
+			Small:    "/api/v1/images/1?size=small",
+			Medium:   "/api/v1/images/1?size=medium",
+			Original: "/api/v1/images/1?size=original",
+		}},
+	}}
+
+	tests := []struct {
+		name    string
+		request genRouter.FindPetsRequestObject
+		prepare func()
+		want    genRouter.FindPetsResponseObject
+	}{
+		{
+			name: "no filter",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Limit: &limit},
+			},
+			want: genRouter.FindPetsdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "atleast one of name, status or tags is required",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "tampered cursor",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Name: &name, Cursor: func() *string {
+					c := validCursor[:len(validCursor)-1] + "A"
+					return &c
+				}()},
+			},
+			want: genRouter.FindPetsdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "cursor of another listing",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Name: &name, Cursor: &ordersCursor},
+			},
+			want: genRouter.FindPetsdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgInvalidCursor,
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "internal error",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Name: &name},
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().FindPets(gomock.Any(), gomock.Any(), "", constants.DefaultPageSize).
+					Return(nil, 0, "", errors.New(""))
+			},
+			want: genRouter.FindPetsdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "last page",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Name: &name, Cursor: &validCursor, Limit: &limit},
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().FindPets(gomock.Any(), gomock.Any(), "68a5c2d7e4b0a1b2c3d4e5f6", limit).
+					Return(slices.Clone(pets), 11, "", nil)
+			},
+			want: func() genRouter.FindPetsResponseObject {
+				res := genRouter.FindPets200JSONResponse{}
+				res.Body.Count = 11
+				res.Body.Pets = wantPets
+				return res
+			}(),
+		},
+		{
+			name: "more pages",
+			request: genRouter.FindPetsRequestObject{
+				Params: genRouter.FindPetsParams{Name: &name},
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().FindPets(gomock.Any(), gomock.Any(), "", constants.DefaultPageSize).
+					Return(slices.Clone(pets), 21, "68a5c2d7e4b0a1b2c3d4e5f7", nil)
+			},
+			want: func() genRouter.FindPetsResponseObject {
+				res := genRouter.FindPets200JSONResponse{}
+				res.Body.Count = 21
+				res.Body.Pets = wantPets
+				res.Headers.XNextCursor = cursorCodec.Encode(petsCursorScope, "68a5c2d7e4b0a1b2c3d4e5f7")
+				return res
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient:    mockDBClient,
+				cursorCodec: cursorCodec,
+				basePath:    "/api/v1",
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.FindPets(context.Background(), tt.request)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.FindPets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_GetPetByID(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	status := genRouter.Available
+	pet := &genRouter.PetWithMetadata{
+		Id:       "68a5c2d7e4b0a1b2c3d4e5f6",
+		Name:     "max",
+		Category: "dogs",
+		Price:    "29.99",
+		Status:   &status,
+		PhotoIds: []string{"68a5c2d7e4b0a1b2c3d4e5f7"},
+	}
+	wantPet := *pet
+	wantPet.Photos = []genRouter.PhotoUrls{{
+		Small:    "/api/v1/images/68a5c2d7e4b0a1b2c3d4e5f7?size=small",
+		Medium:   "/api/v1/images/68a5c2d7e4b0a1b2c3d4e5f7?size=medium",
+		Original: "/api/v1/images/68a5c2d7e4b0a1b2c3d4e5f7?size=original",
+	}}
+
+	tests := []struct {
+		name    string
+		request genRouter.GetPetByIDRequestObject
+		prepare func()
+		want    genRouter.GetPetByIDResponseObject
+	}{
+		{
+			name:    "invalid pet id",
+			request: genRouter.GetPetByIDRequestObject{PetId: "1"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPet(gomock.Any(), "1").Return(nil, dbErr.ErrInvalidValue)
+			},
+			want: genRouter.GetPetByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "pet not found",
+			request: genRouter.GetPetByIDRequestObject{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPet(gomock.Any(), gomock.Any()).Return(nil, dbErr.ErrNotFound)
+			},
+			want: genRouter.GetPetByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "internal error",
+			request: genRouter.GetPetByIDRequestObject{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPet(gomock.Any(), gomock.Any()).Return(nil, errors.New(""))
+			},
+			want: genRouter.GetPetByIDdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "success",
+			request: genRouter.GetPetByIDRequestObject{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPet(gomock.Any(), "68a5c2d7e4b0a1b2c3d4e5f6").Return(pet, nil)
+			},
+			want: genRouter.GetPetByID200JSONResponse(wantPet),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+				basePath: "/api/v1",
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.GetPetByID(context.Background(), tt.request)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.GetPetByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAPIHandler_GetImageByID(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockDBClient := mockdb.NewMockHandler(ctrl)
+	small := genRouter.Small
 
 	tests := []struct {
 		name    string
@@ -116,7 +357,7 @@ func TestAPIHandler_GetImageByID(t *testing.T) {
 		{
 			name: "image not found",
 			prepare: func() {
-				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil, int64(0), dbErr.ErrNotFound)
 			},
 			want: genRouter.GetImageByIDdefaultJSONResponse{
@@ -129,7 +370,7 @@ func TestAPIHandler_GetImageByID(t *testing.T) {
 		{
 			name: "invalid imageid",
 			prepare: func() {
-				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil, int64(0), dbErr.ErrInvalidValue)
 			},
 			want: genRouter.GetImageByIDdefaultJSONResponse{
@@ -142,7 +383,7 @@ func TestAPIHandler_GetImageByID(t *testing.T) {
 		{
 			name: "internal error",
 			prepare: func() {
-				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil, int64(0), errors.New(""))
 			},
 			want: genRouter.GetImageByIDdefaultJSONResponse{
@@ -155,7 +396,22 @@ func TestAPIHandler_GetImageByID(t *testing.T) {
 		{
 			name: "success",
 			prepare: func() {
-				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetPetImage(gomock.Any(), gomock.Any(), genRouter.Original).
+					Return(nil, int64(0), nil)
+			},
+			want: genRouter.GetImageByID200ImagejpegResponse{
+				Body:          nil,
+				ContentLength: 0,
+			},
+		},
+		{
+			name: "success with size",
+			request: genRouter.GetImageByIDRequestObject{
+				ImageId: "1",
+				Params:  genRouter.GetImageByIDParams{Size: &small},
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetImage(gomock.Any(), "1", genRouter.Small).
 					Return(nil, int64(0), nil)
 			},
 			want: genRouter.GetImageByID200ImagejpegResponse{
@@ -263,7 +519,7 @@ func Test_validateImage(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, gotErr := validateImage(tt.r)
+			got, img, gotErr := validateImage(tt.r)
 			if (gotErr != nil) != tt.wantErr {
 				t.Errorf("validateImage() failed: %v", gotErr)
 				return
@@ -271,6 +527,9 @@ func Test_validateImage(t *testing.T) {
 			if !cmp.Equal(got, tt.want) {
 				t.Errorf("validateImage() = %v, want %v", got, tt.want)
 			}
+			if (img != nil) != (tt.want != nil) {
+				t.Errorf("validateImage() decoded image = %v, want decoded: %v", img != nil, tt.want != nil)
+			}
 		})
 	}
 }
@@ -371,7 +630,7 @@ func TestAPIHandler_AddPet(t *testing.T) {
 				Body: validRequestBody,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(errors.New("db error"))
 			},
 			want: genRouter.AddPetdefaultJSONResponse{
@@ -388,7 +647,7 @@ func TestAPIHandler_AddPet(t *testing.T) {
 				Body: validRequestBody,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&dbErr.HintError{Err: dbErr.ErrInvalidValue})
 			},
 			want: genRouter.AddPetdefaultJSONResponse{
@@ -405,7 +664,7 @@ func TestAPIHandler_AddPet(t *testing.T) {
 				Body: validRequestBody,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(&dbErr.HintError{Err: dbErr.ErrNotFound})
 			},
 			want: genRouter.AddPetdefaultJSONResponse{
@@ -422,7 +681,7 @@ func TestAPIHandler_AddPet(t *testing.T) {
 				Body: validRequestBody,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(dbErr.ErrConflict)
 			},
 			want: genRouter.AddPetdefaultJSONResponse{
@@ -439,7 +698,7 @@ func TestAPIHandler_AddPet(t *testing.T) {
 				Body: validRequestBody,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().AddPet(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil)
 			},
 			want: genRouter.AddPet202Response{},
@@ -461,6 +720,468 @@ func TestAPIHandler_AddPet(t *testing.T) {
 	}
 }
 
+func TestAPIHandler_DeletePet(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		prepare func()
+		want    genRouter.DeletePetResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "invalid pet id",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).
+					Return("", &dbErr.HintError{Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "pet owned by another user",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).Return("2", nil)
+			},
+			want: genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pending orders",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeletePet(gomock.Any(), "1", gomock.Any()).
+					Return(&dbErr.HintError{Key: "orders", Err: dbErr.ErrForeignKeyViolation})
+			},
+			want: genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "Pet cannot be deleted as there are pending orders",
+				},
+				StatusCode: http.StatusUnprocessableEntity,
+			},
+		},
+		{
+			name: "internal error",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeletePet(gomock.Any(), "1", gomock.Any()).Return(errors.New(""))
+			},
+			want: genRouter.DeletePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "admin deletes pet of another user",
+			ctx:  contextKeys.ContextWithUserRole(ctxU, string(genRouter.Admin)),
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).Return("2", nil)
+				mockDBClient.EXPECT().DeletePet(gomock.Any(), "2", gomock.Any()).Return(nil)
+			},
+			want: genRouter.DeletePet204Response{},
+		},
+		{
+			name: "success",
+			ctx:  ctxU,
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), gomock.Any()).Return("1", nil)
+				mockDBClient.EXPECT().DeletePet(gomock.Any(), "1", gomock.Any()).Return(nil)
+			},
+			want: genRouter.DeletePet204Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.DeletePet(tt.ctx, genRouter.DeletePetRequestObject{PetId: "68a5c2d7e4b0a1b2c3d4e5f6"})
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.DeletePet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_ReplacePet(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	validBody := func() *multipart.Reader {
+		return createMultipartReader(t,
+			map[string]string{
+				"pet": `{"name":"test","category":"dogs","price":"10"}`,
+			},
+			map[string][]byte{
+				"photos": createTestJPEG(t, 256, 256),
+			})
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		request genRouter.ReplacePetRequestObject
+		prepare func()
+		want    genRouter.ReplacePetResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "invalid pet id",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "1"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "1").
+					Return("", &dbErr.HintError{Err: dbErr.ErrInvalidValue})
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "invalid pet ID",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "pet owned by another user",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("2", nil)
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "sold status",
+			ctx:  ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: createMultipartReader(t,
+				map[string]string{
+					"pet": `{"name":"test","category":"dogs","price":"10","status":"sold"}`,
+				}, nil)},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pets are marked sold only by placing orders",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "invalid image in photos field",
+			ctx:  ctxU,
+			request: genRouter.ReplacePetRequestObject{
+				PetId: "2",
+				Body: createMultipartReader(t, nil, map[string][]byte{
+					"photos": []byte("not an image"),
+				}),
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "jpeg image is corrupted",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "pet sold",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().ReplacePet(gomock.Any(), "1", "2", gomock.Any(), gomock.Any()).
+					Return(&dbErr.HintError{Key: "status", Err: dbErr.ErrConflict})
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "sold pets cannot be replaced",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:    "category not found",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().ReplacePet(gomock.Any(), "1", "2", gomock.Any(), gomock.Any()).
+					Return(&dbErr.HintError{Key: "animal_categories", Err: dbErr.ErrNotFound})
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "animal_categories not found",
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "similar pet exists",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().ReplacePet(gomock.Any(), "1", "2", gomock.Any(), gomock.Any()).
+					Return(dbErr.ErrConflict)
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "Similar pet already exists",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:    "internal error",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().ReplacePet(gomock.Any(), "1", "2", gomock.Any(), gomock.Any()).
+					Return(errors.New(""))
+			},
+			want: genRouter.ReplacePetdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "success",
+			ctx:     ctxU,
+			request: genRouter.ReplacePetRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().ReplacePet(gomock.Any(), "1", "2", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ string, petReq *genRouter.Pet, photos []rendition.Set) error {
+						if petReq.Name != "test" || len(photos) != 1 || len(photos[0]) != 3 {
+							t.Errorf("APIHandler.ReplacePet() passed %+v with %d photos", petReq, len(photos))
+						}
+						return nil
+					})
+			},
+			want: genRouter.ReplacePet202Response{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.ReplacePet(tt.ctx, tt.request)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.ReplacePet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_UploadPetImage(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDBClient := mockdb.NewMockHandler(ctrl)
+	ctxU := contextKeys.ContextWithUserID(context.Background(), "1")
+	validBody := func() *multipart.Reader {
+		return createMultipartReader(t, nil, map[string][]byte{
+			"photos": createTestJPEG(t, 256, 256),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		request genRouter.UploadPetImageRequestObject
+		prepare func()
+		want    genRouter.UploadPetImageResponseObject
+	}{
+		{
+			name: "userID not in context",
+			ctx:  context.Background(),
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "pet owned by another user",
+			ctx:     ctxU,
+			request: genRouter.UploadPetImageRequestObject{PetId: "2"},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("2", nil)
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "pet field not allowed",
+			ctx:  ctxU,
+			request: genRouter.UploadPetImageRequestObject{
+				PetId: "2",
+				Body:  createMultipartReader(t, map[string]string{"pet": `{"name":"test"}`}, nil),
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "unknown multipart field pet",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "no photos",
+			ctx:  ctxU,
+			request: genRouter.UploadPetImageRequestObject{
+				PetId: "2",
+				Body:  createMultipartReader(t, nil, nil),
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "atleast one photo is required",
+				},
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "image limit exceeded",
+			ctx:     ctxU,
+			request: genRouter.UploadPetImageRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().AddPetImages(gomock.Any(), "1", "2", gomock.Any()).
+					Return(nil, &dbErr.HintError{Key: "images", Err: dbErr.ErrLimitExceeded})
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "pet can have at most 10 images",
+				},
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:    "pet deleted meanwhile",
+			ctx:     ctxU,
+			request: genRouter.UploadPetImageRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().AddPetImages(gomock.Any(), "1", "2", gomock.Any()).
+					Return(nil, dbErr.ErrNotFound)
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgPetNotFound,
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:    "internal error",
+			ctx:     ctxU,
+			request: genRouter.UploadPetImageRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().AddPetImages(gomock.Any(), "1", "2", gomock.Any()).
+					Return(nil, errors.New(""))
+			},
+			want: genRouter.UploadPetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: http.StatusText(http.StatusInternalServerError),
+				},
+				StatusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name:    "success",
+			ctx:     ctxU,
+			request: genRouter.UploadPetImageRequestObject{PetId: "2", Body: validBody()},
+			prepare: func() {
+				mockDBClient.EXPECT().GetPetOwner(gomock.Any(), "2").Return("1", nil)
+				mockDBClient.EXPECT().AddPetImages(gomock.Any(), "1", "2", gomock.Len(1)).
+					Return([]string{"3"}, nil)
+			},
+			want: genRouter.UploadPetImage201JSONResponse{PhotoIds: []string{"3"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &APIHandler{
+				dbClient: mockDBClient,
+			}
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			got, _ := a.UploadPetImage(tt.ctx, tt.request)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("APIHandler.UploadPetImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAPIHandler_DeletePetImage(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
@@ -491,13 +1212,13 @@ func TestAPIHandler_DeletePetImage(t *testing.T) {
 			},
 		},
 		{
-			name: "invalid user id",
+			name: "invalid image id",
 			args: args{
 				ctx: ctxU,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(&dbErr.HintError{Err: dbErr.ErrInvalidValue})
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("", &dbErr.HintError{Err: dbErr.ErrInvalidValue})
 			},
 			want: genRouter.DeletePetImagedefaultJSONResponse{
 				Body: genRouter.Generic{
@@ -506,13 +1227,31 @@ func TestAPIHandler_DeletePetImage(t *testing.T) {
 				StatusCode: http.StatusBadRequest,
 			},
 		},
+		{
+			name: "image owned by another user",
+			args: args{
+				ctx: ctxU,
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("2", nil)
+			},
+			want: genRouter.DeletePetImagedefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: "image not found",
+				},
+				StatusCode: http.StatusNotFound,
+			},
+		},
 		{
 			name: "image not found",
 			args: args{
 				ctx: ctxU,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("1", nil)
+				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), "1", gomock.Any()).
 					Return(dbErr.ErrNotFound)
 			},
 			want: genRouter.DeletePetImagedefaultJSONResponse{
@@ -528,6 +1267,8 @@ func TestAPIHandler_DeletePetImage(t *testing.T) {
 				ctx: ctxU,
 			},
 			prepare: func() {
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("1", nil)
 				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(errors.New(""))
 			},
@@ -538,13 +1279,28 @@ func TestAPIHandler_DeletePetImage(t *testing.T) {
 				StatusCode: http.StatusInternalServerError,
 			},
 		},
+		{
+			name: "admin deletes image of another user",
+			args: args{
+				ctx: contextKeys.ContextWithUserRole(ctxU, string(genRouter.Admin)),
+			},
+			prepare: func() {
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("2", nil)
+				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), "2", gomock.Any()).
+					Return(nil)
+			},
+			want: genRouter.DeletePetImage204Response{},
+		},
 		{
 			name: "success",
 			args: args{
 				ctx: ctxU,
 			},
 			prepare: func() {
-				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), gomock.Any(), gomock.Any()).
+				mockDBClient.EXPECT().GetImageOwner(gomock.Any(), gomock.Any()).
+					Return("1", nil)
+				mockDBClient.EXPECT().DeletePetImage(gomock.Any(), "1", gomock.Any()).
 					Return(nil)
 			},
 			want: genRouter.DeletePetImage204Response{},
@@ -18,6 +18,8 @@ const (
 	errMsgUserNotFound   = "user not found"
 	errMsgInvalidUserID  = "Invalid user ID"
 	errMsgUserIDNotFound = "userID not found in context"
+
+	errMsgAdminSelfAssign = "admin role cannot be self-assigned"
 )
 
 func hashPassword(password string) (string, error) {
@@ -25,7 +27,7 @@ func hashPassword(password string) (string, error) {
 	return string(hash), err
 }
 
-func comparePasswords(hashedPswd, plainTextPswd string) error { //nolint:unused //TODO: remove me
+func comparePasswords(hashedPswd, plainTextPswd string) error {
 	return bcrypt.CompareHashAndPassword(
 		[]byte(hashedPswd),
 		[]byte(plainTextPswd),
@@ -38,6 +40,14 @@ func (a *APIHandler) CreateUser(ctx context.Context,
 	request genRouter.CreateUserRequestObject) (genRouter.CreateUserResponseObject, error) {
 	logger := log.Ctx(ctx)
 	userReq := request.Body
+	if userReq.Role != nil && *userReq.Role == genRouter.Admin {
+		return genRouter.CreateUserdefaultJSONResponse{
+			Body: genRouter.Generic{
+				Message: errMsgAdminSelfAssign,
+			},
+			StatusCode: http.StatusForbidden,
+		}, nil
+	}
 
 	hashedPswd, err := hashPassword(userReq.Password)
 	if err != nil {
@@ -68,6 +68,23 @@ func TestAPIHandler_CreateUser(t *testing.T) {
 		prepFunc func()
 		want     genRouter.CreateUserResponseObject
 	}{
+		{
+			name: "admin role self-assigned",
+			args: args{
+				request: genRouter.CreateUserRequestObject{
+					Body: &genRouter.CreateUserJSONRequestBody{
+						Password: "test",
+						Role:     func() *genRouter.UserRole { r := genRouter.Admin; return &r }(),
+					},
+				},
+			},
+			want: genRouter.CreateUserdefaultJSONResponse{
+				Body: genRouter.Generic{
+					Message: errMsgAdminSelfAssign,
+				},
+				StatusCode: http.StatusForbidden,
+			},
+		},
 		{
 			name: "password too long",
 			args: args{
@@ -541,3 +558,32 @@ func TestAPIHandler_PatchUser(t *testing.T) {
 		})
 	}
 }
+
+// PatchUser relies on request validation to reject role, since user cannot change own role
+func TestPatchUserSchema(t *testing.T) {
+	t.Parallel()
+	spec, err := genRouter.GetSwagger()
+	if err != nil {
+		t.Fatalf("GetSwagger() error = %v", err)
+	}
+	schema := spec.Paths.Value("/users").Patch.RequestBody.Value.
+		Content.Get("application/merge-patch+json").Schema.Value
+
+	tests := []struct {
+		name    string
+		body    map[string]any
+		wantErr bool
+	}{
+		{name: "role", body: map[string]any{"role": "seller"}, wantErr: true},
+		{name: "unknown property", body: map[string]any{"email": "a@b.c"}, wantErr: true},
+		{name: "full name", body: map[string]any{"full_name": "alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if errS := schema.VisitJSON(tt.body); (errS != nil) != tt.wantErr {
+				t.Errorf("VisitJSON() error = %v, wantErr %v", errS, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+)
+
+const (
+	bearerAuthScheme = "bearerAuth"
+	bearerPrefix     = "Bearer "
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+// Config holds everything needed to sign & verify access tokens.
+// Secret is used with HS256 while key pairs are used with RS256 & EdDSA.
+type Config struct {
+	Algorithm       string
+	Secret          []byte
+	PrivateKey      crypto.PrivateKey
+	PublicKey       crypto.PublicKey
+	Issuer          string
+	Audience        string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// ConfigFromEnv builds token configuration using JWT_* env vars
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		Algorithm:       os.Getenv(constants.JWTAlgorithm),
+		Issuer:          os.Getenv(constants.JWTIssuer),
+		Audience:        os.Getenv(constants.JWTAudience),
+		AccessTokenTTL:  constants.DefaultAccessTokenTTL,
+		RefreshTokenTTL: constants.DefaultRefreshTokenTTL,
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = jwt.SigningMethodHS256.Alg()
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = constants.DefaultJWTIssuer
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = constants.DefaultJWTAudience
+	}
+	if ttl := os.Getenv(constants.JWTAccessTokenTTL); ttl != "" {
+		var err error
+		cfg.AccessTokenTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", constants.JWTAccessTokenTTL, err)
+		}
+	}
+	if ttl := os.Getenv(constants.RefreshTokenTTL); ttl != "" {
+		var err error
+		cfg.RefreshTokenTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", constants.RefreshTokenTTL, err)
+		}
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		cfg.Secret = []byte(os.Getenv(constants.JWTSecret))
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg():
+		keyFile := os.Getenv(constants.JWTPrivateKeyFile)
+		if keyFile == "" {
+			return nil, fmt.Errorf("%s is required for %s", constants.JWTPrivateKeyFile, cfg.Algorithm)
+		}
+		pemData, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.PrivateKey, err = parsePrivateKey(cfg.Algorithm, pemData)
+		if err != nil {
+			return nil, err
+		}
+
+		// Public key is optional & derived from private key when absent
+		if keyFile = os.Getenv(constants.JWTPublicKeyFile); keyFile != "" {
+			pemData, err = os.ReadFile(keyFile)
+			if err != nil {
+				return nil, err
+			}
+			cfg.PublicKey, err = parsePublicKey(cfg.Algorithm, pemData)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s %s", constants.JWTAlgorithm, cfg.Algorithm)
+	}
+	return cfg, nil
+}
+
+func parsePrivateKey(alg string, pemData []byte) (crypto.PrivateKey, error) {
+	if alg == jwt.SigningMethodRS256.Alg() {
+		return jwt.ParseRSAPrivateKeyFromPEM(pemData)
+	}
+	return jwt.ParseEdPrivateKeyFromPEM(pemData)
+}
+
+func parsePublicKey(alg string, pemData []byte) (crypto.PublicKey, error) {
+	if alg == jwt.SigningMethodRS256.Alg() {
+		return jwt.ParseRSAPublicKeyFromPEM(pemData)
+	}
+	return jwt.ParseEdPublicKeyFromPEM(pemData)
+}
+
+// Claims are the access token claims understood by the api
+type Claims struct {
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type TokenManager struct {
+	method     jwt.SigningMethod
+	signKey    any
+	verifyKey  any
+	parser     *jwt.Parser
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(cfg *Config) (*TokenManager, error) {
+	var (
+		method    jwt.SigningMethod
+		signKey   any
+		verifyKey any
+	)
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if len(cfg.Secret) < 32 {
+			return nil, fmt.Errorf("%s should be atleast 32 bytes long", constants.JWTSecret)
+		}
+		method, signKey, verifyKey = jwt.SigningMethodHS256, cfg.Secret, cfg.Secret
+	case jwt.SigningMethodRS256.Alg():
+		privKey, ok := cfg.PrivateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("RS256 requires a RSA private key")
+		}
+		pubKey := &privKey.PublicKey
+		if cfg.PublicKey != nil {
+			if pubKey, ok = cfg.PublicKey.(*rsa.PublicKey); !ok {
+				return nil, errors.New("RS256 requires a RSA public key")
+			}
+		}
+		method, signKey, verifyKey = jwt.SigningMethodRS256, privKey, pubKey
+	case jwt.SigningMethodEdDSA.Alg():
+		privKey, ok := cfg.PrivateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA requires an ed25519 private key")
+		}
+		pubKey, _ := privKey.Public().(ed25519.PublicKey)
+		if cfg.PublicKey != nil {
+			if pubKey, ok = cfg.PublicKey.(ed25519.PublicKey); !ok {
+				return nil, errors.New("EdDSA requires an ed25519 public key")
+			}
+		}
+		method, signKey, verifyKey = jwt.SigningMethodEdDSA, privKey, pubKey
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %s", cfg.Algorithm)
+	}
+
+	accessTTL := cfg.AccessTokenTTL
+	if accessTTL <= 0 {
+		accessTTL = constants.DefaultAccessTokenTTL
+	}
+	refreshTTL := cfg.RefreshTokenTTL
+	if refreshTTL <= 0 {
+		refreshTTL = constants.DefaultRefreshTokenTTL
+	}
+	return &TokenManager{
+		method:    method,
+		signKey:   signKey,
+		verifyKey: verifyKey,
+		parser: jwt.NewParser(
+			// Pinning the algorithm rules out alg=none & key confusion attacks
+			jwt.WithValidMethods([]string{method.Alg()}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(constants.JWTLeeway),
+		),
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}, nil
+}
+
+// IssueAccessToken signs a short-lived access token for the user carrying their role.
+// Returns the token along with its lifetime
+func (t *TokenManager) IssueAccessToken(userID, role string) (string, time.Duration, error) {
+	now := time.Now()
+	jti := make([]byte, 16)
+	_, _ = rand.Read(jti)
+	claims := &Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+			Subject:   userID,
+			Issuer:    t.issuer,
+			Audience:  jwt.ClaimStrings{t.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.accessTTL)),
+		},
+	}
+	token, err := jwt.NewWithClaims(t.method, claims).SignedString(t.signKey)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, t.accessTTL, nil
+}
+
+// RefreshTokenTTL is the lifetime of every refresh token issued
+func (t *TokenManager) RefreshTokenTTL() time.Duration {
+	return t.refreshTTL
+}
+
+// ParseAccessToken verifies signature, exp, nbf, iss & aud of the token
+func (t *TokenManager) ParseAccessToken(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := t.parser.ParseWithClaims(tokenStr, claims, func(_ *jwt.Token) (any, error) {
+		return t.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, jwt.ErrTokenInvalidSubject
+	}
+	return claims, nil
+}
+
+// Authenticate is an [openapi3filter.AuthenticationFunc].
+// On success the token subject & role are stored as userID & userRole in the request context
+func (t *TokenManager) Authenticate(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	if input.SecuritySchemeName != bearerAuthScheme {
+		return input.NewError(fmt.Errorf("unsupported security scheme %s", input.SecuritySchemeName))
+	}
+
+	req := input.RequestValidationInput.Request
+	authHeader := req.Header.Get("Authorization")
+	if len(authHeader) <= len(bearerPrefix) ||
+		!strings.EqualFold(authHeader[:len(bearerPrefix)], bearerPrefix) {
+		return ErrMissingToken
+	}
+
+	claims, err := t.ParseAccessToken(strings.TrimSpace(authHeader[len(bearerPrefix):]))
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Rejected bearer token")
+		return ErrInvalidToken
+	}
+
+	// Validation middleware keeps serving the same request pointer
+	// hence the context needs to be swapped in-place for handlers to see it
+	reqCtx := contextKeys.ContextWithUserID(req.Context(), claims.Subject)
+	*req = *req.WithContext(contextKeys.ContextWithUserRole(reqCtx, claims.Role))
+	return nil
+}
+
+// NewRefreshToken generates an opaque, url-safe refresh token
+func NewRefreshToken() (string, error) {
+	buf := make([]byte, constants.RefreshTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashRefreshToken returns the digest under which refresh tokens are persisted.
+// Plain refresh tokens are never stored
+func HashRefreshToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,335 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign test token: %v", err)
+	}
+	return token
+}
+
+func validTestClaims() *Claims {
+	now := time.Now()
+	return &Claims{Role: "seller", RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   "68a5c2d7e4b0a1b2c3d4e5f6",
+		Issuer:    constants.DefaultJWTIssuer,
+		Audience:  jwt.ClaimStrings{constants.DefaultJWTAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}}
+}
+
+func newAuthInput(authHeader string) *openapi3filter.AuthenticationInput {
+	req := httptest.NewRequest("GET", "/", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	return &openapi3filter.AuthenticationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req},
+		SecuritySchemeName:     bearerAuthScheme,
+	}
+}
+
+func TestNewTokenManager(t *testing.T) {
+	t.Parallel()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{
+			name:    "short HS256 secret",
+			cfg:     &Config{Algorithm: "HS256", Secret: []byte("short")},
+			wantErr: true,
+		},
+		{
+			name:    "RS256 with ed25519 key",
+			cfg:     &Config{Algorithm: "RS256", PrivateKey: edPriv},
+			wantErr: true,
+		},
+		{
+			name:    "RS256 with mismatched public key type",
+			cfg:     &Config{Algorithm: "RS256", PrivateKey: rsaKey, PublicKey: edPriv.Public()},
+			wantErr: true,
+		},
+		{
+			name:    "EdDSA with RSA key",
+			cfg:     &Config{Algorithm: "EdDSA", PrivateKey: rsaKey},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported algorithm",
+			cfg:     &Config{Algorithm: "none"},
+			wantErr: true,
+		},
+		{
+			name: "HS256",
+			cfg:  &Config{Algorithm: "HS256", Secret: testSecret},
+		},
+		{
+			name: "RS256",
+			cfg:  &Config{Algorithm: "RS256", PrivateKey: rsaKey},
+		},
+		{
+			name: "EdDSA",
+			cfg:  &Config{Algorithm: "EdDSA", PrivateKey: edPriv},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenManager(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTokenManager() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenManager_Authenticate(t *testing.T) {
+	t.Parallel()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hsManager, _ := NewTokenManager(&Config{
+		Algorithm: "HS256",
+		Secret:    testSecret,
+		Issuer:    constants.DefaultJWTIssuer,
+		Audience:  constants.DefaultJWTAudience,
+	})
+	rsManager, _ := NewTokenManager(&Config{
+		Algorithm:  "RS256",
+		PrivateKey: rsaKey,
+		Issuer:     constants.DefaultJWTIssuer,
+		Audience:   constants.DefaultJWTAudience,
+	})
+	edManager, _ := NewTokenManager(&Config{
+		Algorithm:  "EdDSA",
+		PrivateKey: edPriv,
+		PublicKey:  edPub,
+		Issuer:     constants.DefaultJWTIssuer,
+		Audience:   constants.DefaultJWTAudience,
+	})
+
+	expired := validTestClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	notYetValid := validTestClaims()
+	notYetValid.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	wrongIssuer := validTestClaims()
+	wrongIssuer.Issuer = "someone-else"
+	wrongAudience := validTestClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"someone-else"}
+	noExpiry := validTestClaims()
+	noExpiry.ExpiresAt = nil
+	noSubject := validTestClaims()
+	noSubject.Subject = ""
+
+	tests := []struct {
+		name       string
+		manager    *TokenManager
+		input      *openapi3filter.AuthenticationInput
+		wantErr    error
+		wantUserID string
+		wantRole   string
+	}{
+		{
+			name:    "missing authorization header",
+			manager: hsManager,
+			input:   newAuthInput(""),
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "non bearer authorization header",
+			manager: hsManager,
+			input:   newAuthInput("Basic dXNlcjpwYXNz"),
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "malformed token",
+			manager: hsManager,
+			input:   newAuthInput("Bearer not-a-jwt"),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong signature",
+			manager: hsManager,
+			input: newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256,
+				[]byte("fedcba9876543210fedcba9876543210"), validTestClaims())),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "algorithm mismatch",
+			manager: rsManager,
+			input: newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256,
+				testSecret, validTestClaims())),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "expired token",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, expired)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "token not valid yet",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, notYetValid)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong issuer",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, wrongIssuer)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong audience",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, wrongAudience)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "missing expiry",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, noExpiry)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "missing subject",
+			manager: hsManager,
+			input:   newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, noSubject)),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:       "valid HS256 token",
+			manager:    hsManager,
+			input:      newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodHS256, testSecret, validTestClaims())),
+			wantUserID: validTestClaims().Subject,
+			wantRole:   validTestClaims().Role,
+		},
+		{
+			name:       "valid RS256 token",
+			manager:    rsManager,
+			input:      newAuthInput("Bearer " + signTestToken(t, jwt.SigningMethodRS256, rsaKey, validTestClaims())),
+			wantUserID: validTestClaims().Subject,
+			wantRole:   validTestClaims().Role,
+		},
+		{
+			name:       "valid EdDSA token",
+			manager:    edManager,
+			input:      newAuthInput("bearer " + signTestToken(t, jwt.SigningMethodEdDSA, edPriv, validTestClaims())),
+			wantUserID: validTestClaims().Subject,
+			wantRole:   validTestClaims().Role,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.manager.Authenticate(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			userID, _ := contextKeys.UserIDFromContext(tt.input.RequestValidationInput.Request.Context())
+			if userID != tt.wantUserID {
+				t.Errorf("Authenticate() userID = %v, want %v", userID, tt.wantUserID)
+			}
+			role, _ := contextKeys.UserRoleFromContext(tt.input.RequestValidationInput.Request.Context())
+			if role != tt.wantRole {
+				t.Errorf("Authenticate() role = %v, want %v", role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestTokenManager_IssueAccessToken(t *testing.T) {
+	t.Parallel()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{
+			name: "HS256",
+			cfg:  &Config{Algorithm: "HS256", Secret: testSecret, AccessTokenTTL: time.Minute},
+		},
+		{
+			name: "RS256",
+			cfg:  &Config{Algorithm: "RS256", PrivateKey: rsaKey},
+		},
+		{
+			name: "EdDSA",
+			cfg:  &Config{Algorithm: "EdDSA", PrivateKey: edPriv},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Issuer = constants.DefaultJWTIssuer
+			tt.cfg.Audience = constants.DefaultJWTAudience
+			manager, err := NewTokenManager(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewTokenManager() error = %v", err)
+			}
+
+			token, ttl, err := manager.IssueAccessToken("68a5c2d7e4b0a1b2c3d4e5f6", "admin")
+			if err != nil {
+				t.Fatalf("IssueAccessToken() error = %v", err)
+			}
+			if ttl <= 0 {
+				t.Errorf("IssueAccessToken() ttl = %v, want positive", ttl)
+			}
+			claims, err := manager.ParseAccessToken(token)
+			if err != nil {
+				t.Fatalf("ParseAccessToken() error = %v", err)
+			}
+			if claims.Subject != "68a5c2d7e4b0a1b2c3d4e5f6" || claims.Role != "admin" || claims.ID == "" {
+				t.Errorf("ParseAccessToken() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	t.Parallel()
+	first, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("NewRefreshToken() error = %v", err)
+	}
+	second, _ := NewRefreshToken()
+	if first == second || len(first) < constants.RefreshTokenSize {
+		t.Errorf("NewRefreshToken() = %v, %v; want distinct high entropy tokens", first, second)
+	}
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	t.Parallel()
+	if HashRefreshToken("token") != HashRefreshToken("token") {
+		t.Errorf("HashRefreshToken() is not deterministic")
+	}
+	if got := HashRefreshToken("token"); len(got) != 64 || got == HashRefreshToken("token2") {
+		t.Errorf("HashRefreshToken() = %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Package blobstore keeps binary blobs, such as pet images, outside of db documents.
+// Documents refer to a blob with its key
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/vrv501/simple-api/internal/constants"
+)
+
+const (
+	GridFSStoreName = "gridfs"
+	FSStoreName     = "fs"
+
+	DefaultBucket        = "images" // GridFS bucket of api server, where migrations put images of older versions
+	defaultDir    string = "blobs"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+
+	// Keys are also file names of FS store, hence no path separators
+	keyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+)
+
+// Store keeps blobs by key. Keys are never reused, hence Put need not replace an existing blob
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens blob of key for reading along with its size in bytes. Caller closes the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, int64, error)
+
+	// Delete removes blob of key, a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStoreFromEnv returns store selected by BLOB_STORE, GridFS bucket in db by default.
+// FS store keeps blobs in BLOB_STORE_DIR
+func NewStoreFromEnv(db *mongo.Database) (Store, error) {
+	switch name := os.Getenv(constants.BlobStore); name {
+	case "", GridFSStoreName:
+		return NewGridFS(db, DefaultBucket), nil
+	case FSStoreName:
+		dir := os.Getenv(constants.BlobStoreDir)
+		if dir == "" {
+			dir = defaultDir
+		}
+		return NewFS(dir)
+	default:
+		return nil, fmt.Errorf("unknown %s %s", constants.BlobStore, name)
+	}
+}
+
+// Copy copies blob of key from src to dst unless dst already has it, & returns whether it was copied
+func Copy(ctx context.Context, dst, src Store, key string) (bool, error) {
+	existing, _, err := dst.Get(ctx, key)
+	if err == nil {
+		return false, existing.Close()
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+
+	blob, _, err := src.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer blob.Close()
+	if err = dst.Put(ctx, key, blob); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func validateKey(key string) error {
+	if !keyRegex.MatchString(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package blobstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, f *FS)
+	}{
+		{
+			name: "put, get & delete",
+			run: func(t *testing.T, f *FS) {
+				want := []byte("blob")
+				if err := f.Put(t.Context(), "a.jpg", bytes.NewReader(want)); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+				r, size, err := f.Get(t.Context(), "a.jpg")
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				got, err := io.ReadAll(r)
+				_ = r.Close()
+				if err != nil || !bytes.Equal(got, want) || size != int64(len(want)) {
+					t.Fatalf("Get() = %q, size %d, error %v, want %q", got, size, err, want)
+				}
+
+				if err = f.Delete(t.Context(), "a.jpg"); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+				if _, _, err = f.Get(t.Context(), "a.jpg"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get() of deleted blob error = %v, want %v", err, ErrNotFound)
+				}
+			},
+		},
+		{
+			name: "missing blob",
+			run: func(t *testing.T, f *FS) {
+				if _, _, err := f.Get(t.Context(), "missing"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+				}
+				if err := f.Delete(t.Context(), "missing"); err != nil {
+					t.Errorf("Delete() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "failed put leaves nothing behind",
+			run: func(t *testing.T, f *FS) {
+				errRead := errors.New("read failed")
+				if err := f.Put(t.Context(), "a", io.MultiReader(bytes.NewReader([]byte("partial")),
+					&failingReader{err: errRead})); !errors.Is(err, errRead) {
+					t.Fatalf("Put() error = %v, want %v", err, errRead)
+				}
+				entries, err := os.ReadDir(f.dir)
+				if err != nil || len(entries) != 0 {
+					t.Errorf("directory has %d entries, error %v, want none", len(entries), err)
+				}
+			},
+		},
+		{
+			name: "invalid key",
+			run: func(t *testing.T, f *FS) {
+				for _, key := range []string{"", "../a", "a/b", ".a"} {
+					if err := f.Put(t.Context(), key, bytes.NewReader(nil)); !errors.Is(err, ErrInvalidKey) {
+						t.Errorf("Put(%q) error = %v, want %v", key, err, ErrInvalidKey)
+					}
+					if _, _, err := f.Get(t.Context(), key); !errors.Is(err, ErrInvalidKey) {
+						t.Errorf("Get(%q) error = %v, want %v", key, err, ErrInvalidKey)
+					}
+					if err := f.Delete(t.Context(), key); !errors.Is(err, ErrInvalidKey) {
+						t.Errorf("Delete(%q) error = %v, want %v", key, err, ErrInvalidKey)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := NewFS(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewFS() error = %v", err)
+			}
+			tt.run(t, f)
+		})
+	}
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	src, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+	dst, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFS() error = %v", err)
+	}
+	if err = src.Put(t.Context(), "a", bytes.NewReader([]byte("blob"))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	for _, want := range []bool{true, false} {
+		copied, errS := Copy(t.Context(), dst, src, "a")
+		if errS != nil || copied != want {
+			t.Fatalf("Copy() = %v, error %v, want %v", copied, errS, want)
+		}
+	}
+	r, _, err := dst.Get(t.Context(), "a")
+	if err != nil {
+		t.Fatalf("Get() of copied blob error = %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(got) != "blob" {
+		t.Errorf("copied blob = %q, want %q", got, "blob")
+	}
+
+	if _, err = Copy(t.Context(), dst, src, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Copy() of missing blob error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,58 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GridFS keeps blobs in chunks of a GridFS bucket, key is the file ID. Files & chunks collections are
+// created by migrations along with their indexes, since api user cannot create indexes
+type GridFS struct {
+	db     *mongo.Database
+	bucket string
+}
+
+func NewGridFS(db *mongo.Database, bucket string) *GridFS {
+	return &GridFS{db: db, bucket: bucket}
+}
+
+// open returns a new handle of bucket, since a handle shares its buffers across uploads
+func (g *GridFS) open() *mongo.GridFSBucket {
+	return g.db.GridFSBucket(options.GridFSBucket().SetName(g.bucket))
+}
+
+func (g *GridFS) Put(ctx context.Context, key string, r io.Reader) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return g.open().UploadFromStreamWithID(ctx, key, key, r)
+}
+
+func (g *GridFS) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if err := validateKey(key); err != nil {
+		return nil, 0, err
+	}
+	stream, err := g.open().OpenDownloadStream(ctx, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrFileNotFound) {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, err
+	}
+	return stream, stream.GetFile().Length, nil
+}
+
+func (g *GridFS) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := g.open().Delete(ctx, key)
+	if errors.Is(err, mongo.ErrFileNotFound) {
+		return nil
+	}
+	return err
+}
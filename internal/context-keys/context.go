@@ -6,6 +6,7 @@ type contextKey uint
 
 const (
 	userID contextKey = iota
+	userRole
 )
 
 func ContextWithUserID(ctx context.Context, userIDStr string) context.Context {
@@ -16,3 +17,12 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	userIDStr, ok := ctx.Value(userID).(string)
 	return userIDStr, ok
 }
+
+func ContextWithUserRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, userRole, role)
+}
+
+func UserRoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(userRole).(string)
+	return role, ok
+}
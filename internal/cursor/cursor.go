@@ -0,0 +1,71 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/vrv501/simple-api/internal/constants"
+)
+
+// macSize is the number of HMAC bytes kept in a cursor.
+// Cursors are capped at 64 chars by the spec
+const macSize = 12
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Codec converts the last seen ID of a page into an opaque, tamper-evident cursor & back.
+// Cursors are bound to a scope so that a cursor issued for one listing is rejected by others
+type Codec struct {
+	key []byte
+}
+
+// NewCodecFromEnv uses CURSOR_SECRET as signing key.
+// When unset a random key is generated, which invalidates issued cursors on restart
+func NewCodecFromEnv() (*Codec, error) {
+	if secret := os.Getenv(constants.CursorSecret); secret != "" {
+		return NewCodec([]byte(secret))
+	}
+	key := make([]byte, constants.CursorSecretSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return NewCodec(key)
+}
+
+func NewCodec(key []byte) (*Codec, error) {
+	if len(key) < constants.CursorSecretSize {
+		return nil, fmt.Errorf("%s should be atleast %d bytes long", constants.CursorSecret, constants.CursorSecretSize)
+	}
+	return &Codec{key: key}, nil
+}
+
+func (c *Codec) Encode(scope, id string) string {
+	payload := []byte(id)
+	return base64.RawURLEncoding.EncodeToString(append(payload, c.sign(scope, payload)...))
+}
+
+// Decode verifies cursor was issued for the scope & returns ID embedded in it
+func (c *Codec) Decode(scope, cursor string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(data) <= macSize {
+		return "", ErrInvalidCursor
+	}
+	payload, mac := data[:len(data)-macSize], data[len(data)-macSize:]
+	if !hmac.Equal(mac, c.sign(scope, payload)) {
+		return "", ErrInvalidCursor
+	}
+	return string(payload), nil
+}
+
+func (c *Codec) sign(scope string, payload []byte) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
@@ -0,0 +1,86 @@
+package cursor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+	codec, err := NewCodec([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewCodec() error = %v", err)
+	}
+	otherCodec, _ := NewCodec([]byte("fedcba9876543210fedcba9876543210"))
+	valid := codec.Encode("pets", "68a5c2d7e4b0a1b2c3d4e5f6")
+	tampered := []byte(valid)
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name    string
+		scope   string
+		cursor  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "not base64",
+			scope:   "pets",
+			cursor:  "%%%",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "too short",
+			scope:   "pets",
+			cursor:  "YWJj",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "tampered",
+			scope:   "pets",
+			cursor:  string(tampered),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "different scope",
+			scope:   "orders",
+			cursor:  valid,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "different key",
+			scope:   "pets",
+			cursor:  otherCodec.Encode("pets", "68a5c2d7e4b0a1b2c3d4e5f6"),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:   "valid",
+			scope:  "pets",
+			cursor: valid,
+			want:   "68a5c2d7e4b0a1b2c3d4e5f6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Decode(tt.scope, tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCodec(t *testing.T) {
+	t.Parallel()
+	if _, err := NewCodec([]byte("short")); err == nil {
+		t.Errorf("NewCodec() accepted short key")
+	}
+	codec, _ := NewCodec(make([]byte, 32))
+	if got := codec.Encode("pets", "123e4567-e89b-12d3-a456-426614174000"); len(got) > 64 {
+		t.Errorf("Encode() length = %v, want <= 64", len(got))
+	}
+}
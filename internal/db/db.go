@@ -5,15 +5,25 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/vrv501/simple-api/internal/db/memory"
 	"github.com/vrv501/simple-api/internal/db/mongodb"
+	"github.com/vrv501/simple-api/internal/db/postgres"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/payments"
+	"github.com/vrv501/simple-api/internal/rendition"
 )
 
 type Handler interface {
 	animalCategoryHandler
 	userHandler
 	petsHandler
+	ordersHandler
+	cartHandler
+	paymentsHandler
+	refreshTokenHandler
+	ownershipHandler
 	Close(ctx context.Context) error
 }
 
@@ -28,27 +38,81 @@ type userHandler interface {
 		userReq *genRouter.CreateUserJSONRequestBody) (*genRouter.UserJSONResponse, error)
 	GetUser(ctx context.Context,
 		userID string) (*genRouter.UserJSONResponse, error)
+	// PatchUser updates given fields of userID. Changing password revokes every refresh token of the user,
+	// including the one of the session changing it
 	PatchUser(ctx context.Context, userID string,
 		userReq *genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody) (*genRouter.UserJSONResponse, error)
 	DeleteUser(ctx context.Context, userID string) error
+	GetUserCredentials(ctx context.Context, username string) (string, string, genRouter.UserRole, error)
 }
 
 type petsHandler interface {
+	FindPets(ctx context.Context, params *genRouter.FindPetsParams,
+		afterID string, limit int) ([]genRouter.PetWithMetadata, int, string, error)
+	GetPet(ctx context.Context, petID string) (*genRouter.PetWithMetadata, error)
+	ReplacePet(ctx context.Context, userID, petID string,
+		petReq *genRouter.Pet, photos []rendition.Set) error
+	DeletePet(ctx context.Context, userID, petID string) error
+	AddPetImages(ctx context.Context, userID, petID string, photos []rendition.Set) ([]string, error)
 	AddPet(ctx context.Context, userID string,
-		petReq *genRouter.AddPetMultipartBody) error
-	GetPetImage(ctx context.Context, imageID string) (io.Reader, int64, error)
+		petReq *genRouter.Pet, photos []rendition.Set) error
+	// GetPetImage returns rendition of an image in size, original when the image has no such rendition
+	GetPetImage(ctx context.Context, imageID string, size genRouter.ImageSize) (io.Reader, int64, error)
 	DeletePetImage(ctx context.Context, userID, imageID string) error
 }
 
+type ordersHandler interface {
+	PlaceOrders(ctx context.Context, userID string, petIDs []string) ([]genRouter.Order, error)
+	FindOrders(ctx context.Context, userID string, params *genRouter.FindOrdersParams,
+		afterID string, limit int) ([]genRouter.Order, int, string, error)
+	FindSellerOrders(ctx context.Context, sellerID string, params *genRouter.FindOrdersParams,
+		afterID string, limit int) ([]genRouter.Order, int, string, error)
+	UpdateOrderStatus(ctx context.Context, sellerID, orderID string,
+		statusReq *genRouter.UpdateOrderStatusJSONRequestBody) (*genRouter.Order, error)
+	GetOrder(ctx context.Context, orderID string) (*genRouter.Order, error)
+	DeleteOrder(ctx context.Context, userID, orderID string) error
+}
+
+type cartHandler interface {
+	AddCartItem(ctx context.Context, userID, petID string) (*genRouter.CartItem, error)
+	FindCartItems(ctx context.Context, userID string) ([]genRouter.CartItem, error)
+	DeleteCartItem(ctx context.Context, userID, petID string) error
+	CheckoutCart(ctx context.Context, userID string) ([]genRouter.Order, error)
+	ReleaseExpiredHolds(ctx context.Context) (int64, error)
+}
+
+type paymentsHandler interface {
+	SavePaymentIntent(ctx context.Context, orderID string, intent *payments.Intent) error
+	GetPaymentIntent(ctx context.Context, orderID string) (*payments.Intent, error)
+}
+
+type refreshTokenHandler interface {
+	AddRefreshToken(ctx context.Context, userID, tokenHash string, expiresOn time.Time) error
+	RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string,
+		expiresOn time.Time) (string, genRouter.UserRole, error)
+	RevokeRefreshTokenFamily(ctx context.Context, tokenHash string) error
+}
+
+// ownershipHandler resolves userID owning a resource
+type ownershipHandler interface {
+	GetPetOwner(ctx context.Context, petID string) (string, error)
+	GetImageOwner(ctx context.Context, imageID string) (string, error)
+	GetOrderOwner(ctx context.Context, orderID string) (string, error)
+	GetOrderSeller(ctx context.Context, orderID string) (string, error)
+}
+
 func NewDBHandler(ctx context.Context) Handler {
 	switch dbEnv := os.Getenv("DB_TYPE"); dbEnv {
 	case "mongodb":
 		return mongodb.NewInstance(ctx)
 	case "postgres":
-		return nil
+		return postgres.NewInstance(ctx)
+	case "memory":
+		return memory.NewInstance(ctx)
 	default:
+		// Tests cannot rely on a running Mongo replica set
 		if testing.Testing() {
-			return nil
+			return memory.NewInstance(ctx)
 		}
 		return mongodb.NewInstance(ctx)
 	}
@@ -0,0 +1,58 @@
+package db_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/vrv501/simple-api/internal/db"
+	"github.com/vrv501/simple-api/internal/db/dbtest"
+	"github.com/vrv501/simple-api/internal/db/mongodb"
+	"github.com/vrv501/simple-api/internal/db/postgres"
+)
+
+const (
+	// ENV vars pointing at migrated db infra, respective backend is skipped when unset
+	postgresTestDSNEnvVar string = "POSTGRES_TEST_DSN" // Should be postgres://{{ rest of the URI }}
+	mongoTestURIEnvVar    string = "MONGO_TEST_URI"    // Should be mongodb://{{ rest of the URI }}
+)
+
+// TestConformance runs dbtest suite against backend selected with DB_TYPE, in-memory backend by default
+func TestConformance(t *testing.T) {
+	t.Parallel()
+	dbtest.Run(t, func(t *testing.T) db.Handler {
+		h := db.NewDBHandler(t.Context())
+		t.Cleanup(func() { _ = h.Close(context.Background()) })
+		return h
+	})
+}
+
+// Backends below read connection ENV vars same as api server, hence tests cannot be parallel.
+// Their cases still run in parallel with each other, ENV vars are restored once all of them complete
+
+func TestConformance_Postgres(t *testing.T) {
+	dsn := os.Getenv(postgresTestDSNEnvVar)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresTestDSNEnvVar)
+	}
+	t.Setenv("POSTGRES_URI", dsn)
+	dbtest.Run(t, func(t *testing.T) db.Handler {
+		h := postgres.NewInstance(t.Context())
+		t.Cleanup(func() { _ = h.Close(context.Background()) })
+		return h
+	})
+}
+
+// Mongo has to be a replica set, since transactions are used
+func TestConformance_Mongo(t *testing.T) {
+	uri := os.Getenv(mongoTestURIEnvVar)
+	if uri == "" {
+		t.Skipf("%s is not set", mongoTestURIEnvVar)
+	}
+	t.Setenv("MONGO_APPLY_URI", uri)
+	dbtest.Run(t, func(t *testing.T) db.Handler {
+		h := mongodb.NewInstance(t.Context())
+		t.Cleanup(func() { _ = h.Close(context.Background()) })
+		return h
+	})
+}
@@ -0,0 +1,896 @@
+// Package dbtest verifies a [db.Handler] honours the contract api handlers rely on,
+// so that every backend behaves the same irrespective of how it stores data.
+//
+// Cases create uniquely named records & never assume an empty db, hence the suite
+// can also be run against a shared Mongo or Postgres instance.
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	"github.com/vrv501/simple-api/internal/db"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+	"github.com/vrv501/simple-api/internal/payments"
+	"github.com/vrv501/simple-api/internal/rendition"
+)
+
+// invalidID is never handed out by any backend
+const invalidID = "not-an-id"
+
+// Factory returns a handler backed by a ready to use db. Handler may be shared by cases,
+// closing it is up to the factory, for instance with [testing.T.Cleanup]
+type Factory func(t *testing.T) db.Handler
+
+// Run runs every case of the suite in parallel against handlers returned by newHandler
+func Run(t *testing.T, newHandler Factory) {
+	t.Helper()
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.run(&fixture{
+				t:   t,
+				ctx: t.Context(),
+				h:   newHandler(t),
+				id:  rand.Text(),
+			})
+		})
+	}
+}
+
+var cases = []struct {
+	name string
+	run  func(f *fixture)
+}{
+	{
+		name: "AddUser conflicts on username",
+		run: func(f *fixture) {
+			f.addUser("alice")
+			_, err := f.h.AddUser(f.ctx, f.newUser("alice", "other"))
+			f.wantHint(err, "username", dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "AddUser conflicts on phone number",
+		run: func(f *fixture) {
+			f.addUser("alice")
+			_, err := f.h.AddUser(f.ctx, f.newUser("bob", "alice"))
+			f.wantHint(err, "phone_number", dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "AddUser defaults role to customer",
+		run: func(f *fixture) {
+			res, err := f.h.AddUser(f.ctx, f.newUser("alice", "alice"))
+			f.must(err)
+			if res.Role == nil || *res.Role != genRouter.Customer {
+				f.t.Errorf("AddUser() role = %v, want %v", res.Role, genRouter.Customer)
+			}
+			userID := f.userID("alice")
+			got, err := f.h.GetUser(f.ctx, userID)
+			f.must(err)
+			if got.Username != f.name("alice") || got.Role == nil || *got.Role != genRouter.Customer {
+				f.t.Errorf("GetUser() = %+v", got)
+			}
+		},
+	},
+	{
+		name: "GetUser rejects invalid ID",
+		run: func(f *fixture) {
+			_, err := f.h.GetUser(f.ctx, invalidID)
+			f.wantErr(err, dbErr.ErrInvalidValue)
+		},
+	},
+	{
+		name: "Deleted user is invisible & frees username",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			f.must(f.h.DeleteUser(f.ctx, userID))
+
+			_, err := f.h.GetUser(f.ctx, userID)
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, _, _, err = f.h.GetUserCredentials(f.ctx, f.name("alice"))
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, err = f.h.PatchUser(f.ctx, userID, &genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody{})
+			f.wantErr(err, dbErr.ErrNotFound)
+			f.wantErr(f.h.DeleteUser(f.ctx, userID), dbErr.ErrNotFound)
+
+			if newUserID := f.addUser("alice"); newUserID == userID {
+				f.t.Errorf("AddUser() reused ID %v of deleted user", userID)
+			}
+		},
+	},
+	{
+		name: "DeleteUser fails with available pets",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			f.addPet(userID, f.addCategory("dog"), "rex")
+			f.wantHint(f.h.DeleteUser(f.ctx, userID), "pets", dbErr.ErrForeignKeyViolation)
+		},
+	},
+	{
+		name: "DeleteUser fails with open orders",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			petID := f.addPet(sellerID, f.addCategory("dog"), "rex")
+			_, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+			f.wantHint(f.h.DeleteUser(f.ctx, buyerID), "orders", dbErr.ErrForeignKeyViolation)
+		},
+	},
+	{
+		name: "PatchUser updates given fields only",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			address := "New Address"
+			got, err := f.h.PatchUser(f.ctx, userID, &genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody{
+				Address: &address,
+			})
+			f.must(err)
+			if got.Address != address || got.FullName != "Full Name" || got.Username != f.name("alice") {
+				f.t.Errorf("PatchUser() = %+v", got)
+			}
+		},
+	},
+	{
+		name: "PatchUser conflicts on phone number",
+		run: func(f *fixture) {
+			f.addUser("alice")
+			userID := f.addUser("bob")
+			phoneNumber := f.name("alice")
+			_, err := f.h.PatchUser(f.ctx, userID, &genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody{
+				PhoneNumber: &phoneNumber,
+			})
+			f.wantErr(err, dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "GetUserCredentials returns ID & role",
+		run: func(f *fixture) {
+			userReq := f.newUser("alice", "alice")
+			role := genRouter.Seller
+			userReq.Role = &role
+			_, err := f.h.AddUser(f.ctx, userReq)
+			f.must(err)
+
+			userID, password, gotRole, err := f.h.GetUserCredentials(f.ctx, userReq.Username)
+			f.must(err)
+			if password != userReq.Password || gotRole != role {
+				f.t.Errorf("GetUserCredentials() = %v, %v, want %v, %v", password, gotRole, userReq.Password, role)
+			}
+			_, err = f.h.GetUser(f.ctx, userID)
+			f.must(err)
+		},
+	},
+	{
+		name: "AnimalCategory names are unique",
+		run: func(f *fixture) {
+			name := f.addCategory("dog")
+			_, err := f.h.AddAnimalCategory(f.ctx, name)
+			f.wantErr(err, dbErr.ErrConflict)
+
+			other, err := f.h.AddAnimalCategory(f.ctx, f.name("cat"))
+			f.must(err)
+			_, err = f.h.UpdateAnimalCategory(f.ctx, other.Id, name)
+			f.wantErr(err, dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "AnimalCategory can be found & renamed",
+		run: func(f *fixture) {
+			added, err := f.h.AddAnimalCategory(f.ctx, f.name("dog"))
+			f.must(err)
+			found, err := f.h.FindAnimalCategory(f.ctx, added.Name)
+			f.must(err)
+			if *found != *added {
+				f.t.Errorf("FindAnimalCategory() = %v, want %v", found, added)
+			}
+
+			renamed, err := f.h.UpdateAnimalCategory(f.ctx, added.Id, f.name("hound"))
+			f.must(err)
+			found, err = f.h.FindAnimalCategory(f.ctx, renamed.Name)
+			f.must(err)
+			if found.Id != added.Id {
+				f.t.Errorf("FindAnimalCategory() ID = %v, want %v", found.Id, added.Id)
+			}
+			_, err = f.h.UpdateAnimalCategory(f.ctx, invalidID, f.name("cat"))
+			f.wantErr(err, dbErr.ErrInvalidValue)
+		},
+	},
+	{
+		name: "AddPet validates price, category & owner",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			category := f.addCategory("dog")
+
+			err := f.h.AddPet(f.ctx, userID, f.newPet(category, "rex", "not-a-price"), nil)
+			f.wantHint(err, "price", dbErr.ErrInvalidValue)
+			err = f.h.AddPet(f.ctx, userID, f.newPet(f.name("unknown"), "rex", "10"), nil)
+			f.wantHint(err, "animal_categories", dbErr.ErrNotFound)
+			err = f.h.AddPet(f.ctx, invalidID, f.newPet(category, "rex", "10"), nil)
+			f.wantHint(err, "user_id", dbErr.ErrInvalidValue)
+
+			f.must(f.h.DeleteUser(f.ctx, userID))
+			err = f.h.AddPet(f.ctx, userID, f.newPet(category, "rex", "10"), nil)
+			f.wantHint(err, "user_id", dbErr.ErrNotFound)
+		},
+	},
+	{
+		name: "Pet names are unique within a category",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			dog, cat := f.addCategory("dog"), f.addCategory("cat")
+			petID := f.addPet(userID, dog, "rex")
+			f.addPet(userID, cat, "rex")
+
+			err := f.h.AddPet(f.ctx, userID, f.newPet(dog, "rex", "10"), nil)
+			f.wantErr(err, dbErr.ErrConflict)
+
+			// Deleted pets free up their name
+			f.must(f.h.DeletePet(f.ctx, userID, petID))
+			f.addPet(userID, dog, "rex")
+		},
+	},
+	{
+		name: "GetPet returns pet with metadata",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			category := f.addCategory("dog")
+			petReq := f.newPet(category, "rex", "29.99")
+			petReq.Tags = &[]string{"friendly"}
+			f.must(f.h.AddPet(f.ctx, userID, petReq, photos(2)))
+
+			petID := f.petID("rex")
+			got, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+			if got.Id != petID || got.Name != f.name("rex") || got.Category != category || got.Price != "29.99" ||
+				got.Status == nil || *got.Status != genRouter.Available ||
+				got.Tags == nil || len(*got.Tags) != 1 || len(got.PhotoIds) != 2 {
+				f.t.Errorf("GetPet() = %+v", got)
+			}
+
+			owner, err := f.h.GetPetOwner(f.ctx, petID)
+			f.must(err)
+			if owner != userID {
+				f.t.Errorf("GetPetOwner() = %v, want %v", owner, userID)
+			}
+			_, err = f.h.GetPet(f.ctx, invalidID)
+			f.wantErr(err, dbErr.ErrInvalidValue)
+		},
+	},
+	{
+		name: "Deleted pet & its images are invisible",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			f.must(f.h.AddPet(f.ctx, userID, f.newPet(f.addCategory("dog"), "rex", "10"), photos(1)))
+			petID := f.petID("rex")
+			pet, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+
+			otherID := f.addUser("bob")
+			f.wantErr(f.h.DeletePet(f.ctx, otherID, petID), dbErr.ErrNotFound)
+			f.must(f.h.DeletePet(f.ctx, userID, petID))
+
+			_, err = f.h.GetPet(f.ctx, petID)
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, err = f.h.GetPetOwner(f.ctx, petID)
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, _, err = f.h.GetPetImage(f.ctx, pet.PhotoIds[0], genRouter.Original)
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, err = f.h.GetImageOwner(f.ctx, pet.PhotoIds[0])
+			f.wantErr(err, dbErr.ErrNotFound)
+			f.wantErr(f.h.DeletePet(f.ctx, userID, petID), dbErr.ErrNotFound)
+
+			name := f.name("rex")
+			_, count, _, err := f.h.FindPets(f.ctx, &genRouter.FindPetsParams{Name: &name}, "", 10)
+			f.must(err)
+			if count != 0 {
+				f.t.Errorf("FindPets() count = %v, want 0", count)
+			}
+		},
+	},
+	{
+		name: "ReplacePet replaces details & photos",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			category := f.addCategory("dog")
+			f.must(f.h.AddPet(f.ctx, userID, f.newPet(category, "rex", "10"), photos(2)))
+			petID := f.petID("rex")
+			before, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+
+			replaceReq := f.newPet(category, "max", "12.50")
+			f.must(f.h.ReplacePet(f.ctx, userID, petID, replaceReq, photos(1)))
+
+			after, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+			if after.Name != f.name("max") || after.Price != "12.50" || len(after.PhotoIds) != 1 {
+				f.t.Errorf("GetPet() after ReplacePet() = %+v", after)
+			}
+			_, _, err = f.h.GetPetImage(f.ctx, before.PhotoIds[0], genRouter.Original)
+			f.wantErr(err, dbErr.ErrNotFound)
+
+			otherID := f.addUser("bob")
+			f.wantErr(f.h.ReplacePet(f.ctx, otherID, petID, replaceReq, photos(1)), dbErr.ErrNotFound)
+		},
+	},
+	{
+		name: "ReplacePet fails for sold pets",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			category := f.addCategory("dog")
+			petID := f.addPet(sellerID, category, "rex")
+			_, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+
+			f.wantHint(f.h.ReplacePet(f.ctx, sellerID, petID, f.newPet(category, "rex", "10"), nil),
+				"status", dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "Pet images are limited",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			petID := f.addPet(userID, f.addCategory("dog"), "rex")
+
+			imageIDs, err := f.h.AddPetImages(f.ctx, userID, petID, photos(constants.MaxPetImages))
+			f.must(err)
+			if len(imageIDs) != constants.MaxPetImages {
+				f.t.Fatalf("AddPetImages() = %v images, want %v", len(imageIDs), constants.MaxPetImages)
+			}
+			_, err = f.h.AddPetImages(f.ctx, userID, petID, photos(1))
+			f.wantHint(err, "images", dbErr.ErrLimitExceeded)
+
+			// Deleted images make room for new ones
+			f.must(f.h.DeletePetImage(f.ctx, userID, imageIDs[0]))
+			_, err = f.h.AddPetImages(f.ctx, userID, petID, photos(1))
+			f.must(err)
+		},
+	},
+	{
+		name: "Pet images can be read & deleted by owner",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			petID := f.addPet(userID, f.addCategory("dog"), "rex")
+			imageIDs, err := f.h.AddPetImages(f.ctx, userID, petID, photos(1))
+			f.must(err)
+
+			for size, want := range photos(1)[0] {
+				r, length, err := f.h.GetPetImage(f.ctx, imageIDs[0], size)
+				f.must(err)
+				got, err := io.ReadAll(r)
+				f.must(err)
+				if string(got) != string(want) || length != int64(len(want)) {
+					f.t.Errorf("GetPetImage(%s) = %v bytes, length %v, want %v bytes", size, len(got), length, len(want))
+				}
+			}
+			owner, err := f.h.GetImageOwner(f.ctx, imageIDs[0])
+			f.must(err)
+			if owner != userID {
+				f.t.Errorf("GetImageOwner() = %v, want %v", owner, userID)
+			}
+
+			otherID := f.addUser("bob")
+			f.wantErr(f.h.DeletePetImage(f.ctx, otherID, imageIDs[0]), dbErr.ErrNotFound)
+			f.must(f.h.DeletePetImage(f.ctx, userID, imageIDs[0]))
+			f.wantErr(f.h.DeletePetImage(f.ctx, userID, imageIDs[0]), dbErr.ErrNotFound)
+		},
+	},
+	{
+		name: "FindPets pages in order of ID",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			for _, category := range []string{"dog", "cat", "bird"} {
+				f.addPet(userID, f.addCategory(category), "rex")
+			}
+
+			name := f.name("rex")
+			params := &genRouter.FindPetsParams{Name: &name}
+			first, count, nextID, err := f.h.FindPets(f.ctx, params, "", 2)
+			f.must(err)
+			if len(first) != 2 || count != 3 || nextID != first[1].Id {
+				f.t.Fatalf("FindPets() = %v pets, count %v, next %v", len(first), count, nextID)
+			}
+			second, count, nextID, err := f.h.FindPets(f.ctx, params, nextID, 2)
+			f.must(err)
+			if len(second) != 1 || count != 3 || nextID != "" {
+				f.t.Fatalf("FindPets() after cursor = %v pets, count %v, next %v", len(second), count, nextID)
+			}
+			seen := map[string]bool{first[0].Id: true, first[1].Id: true}
+			if seen[second[0].Id] {
+				f.t.Errorf("FindPets() returned %v on both pages", second[0].Id)
+			}
+
+			sold := []genRouter.PetStatus{genRouter.Sold}
+			params.Status = &sold
+			pets, count, _, err := f.h.FindPets(f.ctx, params, "", 2)
+			f.must(err)
+			if len(pets) != 0 || count != 0 {
+				f.t.Errorf("FindPets() sold = %v pets, count %v", len(pets), count)
+			}
+		},
+	},
+	{
+		name: "PlaceOrders marks pets sold at current price",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			category := f.addCategory("dog")
+			f.must(f.h.AddPet(f.ctx, sellerID, f.newPet(category, "rex", "29.99"), nil))
+			petID := f.petID("rex")
+
+			orders, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+			if len(orders) != 1 || orders[0].PetId != petID || orders[0].Price != "29.99" ||
+				orders[0].Status != genRouter.Placed {
+				f.t.Fatalf("PlaceOrders() = %+v", orders)
+			}
+			pet, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+			if pet.Status == nil || *pet.Status != genRouter.Sold {
+				f.t.Errorf("GetPet() status = %v, want %v", pet.Status, genRouter.Sold)
+			}
+
+			got, err := f.h.GetOrder(f.ctx, orders[0].Id)
+			f.must(err)
+			if got.Id != orders[0].Id || got.Price != "29.99" {
+				f.t.Errorf("GetOrder() = %+v", got)
+			}
+			owner, err := f.h.GetOrderOwner(f.ctx, orders[0].Id)
+			f.must(err)
+			seller, err := f.h.GetOrderSeller(f.ctx, orders[0].Id)
+			f.must(err)
+			if owner != buyerID || seller != sellerID {
+				f.t.Errorf("GetOrderOwner(), GetOrderSeller() = %v, %v, want %v, %v", owner, seller, buyerID, sellerID)
+			}
+
+			_, err = f.h.PlaceOrders(f.ctx, f.addUser("other"), []string{petID})
+			f.wantHint(err, "status", dbErr.ErrConflict)
+		},
+	},
+	{
+		name: "PlaceOrders sells a pet to one of concurrent buyers",
+		run: func(f *fixture) {
+			const buyers = 8
+			petID := f.addPet(f.addUser("seller"), f.addCategory("dog"), "rex")
+			buyerIDs := make([]string, buyers)
+			for i := range buyerIDs {
+				buyerIDs[i] = f.addUser("buyer" + string(rune('a'+i)))
+			}
+
+			errs := make(chan error, buyers)
+			var wg sync.WaitGroup
+			for _, buyerID := range buyerIDs {
+				wg.Go(func() {
+					_, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+					errs <- err
+				})
+			}
+			wg.Wait()
+			close(errs)
+
+			placed := 0
+			for err := range errs {
+				if err == nil {
+					placed++
+					continue
+				}
+				f.wantHint(err, "status", dbErr.ErrConflict)
+			}
+			if placed != 1 {
+				f.t.Errorf("PlaceOrders() placed %d orders, want 1", placed)
+			}
+		},
+	},
+	{
+		name: "PlaceOrders validates pets",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			category := f.addCategory("dog")
+			petID := f.addPet(sellerID, category, "rex")
+
+			_, err := f.h.PlaceOrders(f.ctx, sellerID, []string{petID})
+			f.wantHint(err, "user_id", dbErr.ErrOwnPet)
+			_, err = f.h.PlaceOrders(f.ctx, buyerID, []string{invalidID})
+			f.wantHint(err, "pet_id", dbErr.ErrInvalidValue)
+
+			deletedID := f.addPet(sellerID, category, "max")
+			f.must(f.h.DeletePet(f.ctx, sellerID, deletedID))
+			// Orders are placed all or none
+			_, err = f.h.PlaceOrders(f.ctx, buyerID, []string{petID, deletedID})
+			f.wantHint(err, "pet_id", dbErr.ErrNotFound)
+			pet, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+			if pet.Status == nil || *pet.Status != genRouter.Available {
+				f.t.Errorf("GetPet() status = %v, want %v", pet.Status, genRouter.Available)
+			}
+		},
+	},
+	{
+		name: "DeleteOrder cancels order & restores pet",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			petID := f.addPet(sellerID, f.addCategory("dog"), "rex")
+			orders, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+
+			f.wantErr(f.h.DeleteOrder(f.ctx, sellerID, orders[0].Id), dbErr.ErrNotFound)
+			f.must(f.h.DeleteOrder(f.ctx, buyerID, orders[0].Id))
+
+			got, err := f.h.GetOrder(f.ctx, orders[0].Id)
+			f.must(err)
+			if got.Status != genRouter.Cancelled {
+				f.t.Errorf("GetOrder() status = %v, want %v", got.Status, genRouter.Cancelled)
+			}
+			pet, err := f.h.GetPet(f.ctx, petID)
+			f.must(err)
+			if pet.Status == nil || *pet.Status != genRouter.Available {
+				f.t.Errorf("GetPet() status = %v, want %v", pet.Status, genRouter.Available)
+			}
+
+			var transitionErr *orderstate.TransitionError
+			if err = f.h.DeleteOrder(f.ctx, buyerID, orders[0].Id); !errors.As(err, &transitionErr) ||
+				transitionErr.From != genRouter.Cancelled {
+				f.t.Errorf("DeleteOrder() again error = %v, want transition error from %v", err, genRouter.Cancelled)
+			}
+		},
+	},
+	{
+		name: "UpdateOrderStatus follows order state machine",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			petID := f.addPet(sellerID, f.addCategory("dog"), "rex")
+			orders, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+			orderID := orders[0].Id
+
+			statusReq := func(status genRouter.UpdateOrderStatusJSONBodyStatus) *genRouter.UpdateOrderStatusJSONRequestBody {
+				return &genRouter.UpdateOrderStatusJSONRequestBody{Status: status}
+			}
+			_, err = f.h.UpdateOrderStatus(f.ctx, buyerID, orderID, statusReq("processing"))
+			f.wantErr(err, dbErr.ErrNotFound)
+			var transitionErr *orderstate.TransitionError
+			if _, err = f.h.UpdateOrderStatus(f.ctx, sellerID, orderID, statusReq("delivered")); !errors.As(err,
+				&transitionErr) {
+				f.t.Errorf("UpdateOrderStatus() error = %v, want transition error", err)
+			}
+
+			_, err = f.h.UpdateOrderStatus(f.ctx, sellerID, orderID, statusReq("processing"))
+			f.must(err)
+			trackingNumber, carrier := "TRACK-1", "UPS"
+			shipReq := statusReq("shipped")
+			shipReq.TrackingNumber, shipReq.Carrier = &trackingNumber, &carrier
+			got, err := f.h.UpdateOrderStatus(f.ctx, sellerID, orderID, shipReq)
+			f.must(err)
+			if got.Status != genRouter.Shipped || got.ShippedDate == nil ||
+				got.TrackingNumber == nil || *got.TrackingNumber != trackingNumber ||
+				got.Carrier == nil || *got.Carrier != carrier {
+				f.t.Errorf("UpdateOrderStatus() = %+v", got)
+			}
+
+			// Pets with orders in flight cannot be deleted
+			f.wantHint(f.h.DeletePet(f.ctx, sellerID, petID), "orders", dbErr.ErrForeignKeyViolation)
+
+			got, err = f.h.UpdateOrderStatus(f.ctx, sellerID, orderID, statusReq("delivered"))
+			f.must(err)
+			if got.Status != genRouter.Delivered || got.DeliveredDate == nil ||
+				got.TrackingNumber == nil || *got.TrackingNumber != trackingNumber {
+				f.t.Errorf("UpdateOrderStatus() = %+v", got)
+			}
+			f.must(f.h.DeletePet(f.ctx, sellerID, petID))
+		},
+	},
+	{
+		name: "FindOrders & FindSellerOrders filter by user",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			category := f.addCategory("dog")
+			petIDs := []string{f.addPet(sellerID, category, "rex"), f.addPet(sellerID, category, "max")}
+			placed, err := f.h.PlaceOrders(f.ctx, buyerID, petIDs)
+			f.must(err)
+			f.must(f.h.DeleteOrder(f.ctx, buyerID, placed[0].Id))
+
+			orders, count, nextID, err := f.h.FindOrders(f.ctx, buyerID, &genRouter.FindOrdersParams{}, "", 1)
+			f.must(err)
+			if len(orders) != 1 || count != 2 || nextID != orders[0].Id {
+				f.t.Errorf("FindOrders() = %v orders, count %v, next %v", len(orders), count, nextID)
+			}
+			cancelled := []genRouter.OrderStatus{genRouter.Cancelled}
+			orders, count, _, err = f.h.FindSellerOrders(f.ctx, sellerID,
+				&genRouter.FindOrdersParams{Status: &cancelled}, "", 10)
+			f.must(err)
+			if len(orders) != 1 || count != 1 || orders[0].Id != placed[0].Id {
+				f.t.Errorf("FindSellerOrders() = %+v, count %v", orders, count)
+			}
+			future := time.Now().Add(time.Hour)
+			_, count, _, err = f.h.FindOrders(f.ctx, buyerID, &genRouter.FindOrdersParams{AfterDate: &future}, "", 10)
+			f.must(err)
+			if count != 0 {
+				f.t.Errorf("FindOrders() after date count = %v, want 0", count)
+			}
+			_, count, _, err = f.h.FindOrders(f.ctx, sellerID, &genRouter.FindOrdersParams{}, "", 10)
+			f.must(err)
+			if count != 0 {
+				f.t.Errorf("FindOrders() of seller count = %v, want 0", count)
+			}
+		},
+	},
+	{
+		name: "Cart holds pets for one user at a time",
+		run: func(f *fixture) {
+			sellerID, buyerID, otherID := f.addUser("seller"), f.addUser("buyer"), f.addUser("other")
+			petID := f.addPet(sellerID, f.addCategory("dog"), "rex")
+
+			_, err := f.h.AddCartItem(f.ctx, sellerID, petID)
+			f.wantHint(err, "user_id", dbErr.ErrOwnPet)
+			item, err := f.h.AddCartItem(f.ctx, buyerID, petID)
+			f.must(err)
+			if item.PetId != petID || !item.HeldUntil.After(time.Now()) {
+				f.t.Errorf("AddCartItem() = %+v", item)
+			}
+			// Holding again renews the hold
+			_, err = f.h.AddCartItem(f.ctx, buyerID, petID)
+			f.must(err)
+
+			_, err = f.h.AddCartItem(f.ctx, otherID, petID)
+			f.wantHint(err, "status", dbErr.ErrConflict)
+			_, err = f.h.PlaceOrders(f.ctx, otherID, []string{petID})
+			f.wantHint(err, "status", dbErr.ErrConflict)
+
+			f.wantErr(f.h.DeleteCartItem(f.ctx, otherID, petID), dbErr.ErrNotFound)
+			f.must(f.h.DeleteCartItem(f.ctx, buyerID, petID))
+			_, err = f.h.AddCartItem(f.ctx, otherID, petID)
+			f.must(err)
+		},
+	},
+	{
+		name: "CheckoutCart places orders & empties cart",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			category := f.addCategory("dog")
+			petIDs := []string{f.addPet(sellerID, category, "rex"), f.addPet(sellerID, category, "max")}
+
+			_, err := f.h.CheckoutCart(f.ctx, buyerID)
+			f.wantErr(err, dbErr.ErrNotFound)
+			for _, petID := range petIDs {
+				_, err = f.h.AddCartItem(f.ctx, buyerID, petID)
+				f.must(err)
+			}
+			items, err := f.h.FindCartItems(f.ctx, buyerID)
+			f.must(err)
+			if len(items) != 2 || items[0].PetId != petIDs[0] || items[1].PetId != petIDs[1] {
+				f.t.Errorf("FindCartItems() = %+v", items)
+			}
+
+			orders, err := f.h.CheckoutCart(f.ctx, buyerID)
+			f.must(err)
+			if len(orders) != 2 {
+				f.t.Errorf("CheckoutCart() = %v orders, want 2", len(orders))
+			}
+			items, err = f.h.FindCartItems(f.ctx, buyerID)
+			f.must(err)
+			if len(items) != 0 {
+				f.t.Errorf("FindCartItems() after checkout = %+v", items)
+			}
+			_, err = f.h.AddCartItem(f.ctx, buyerID, petIDs[0])
+			f.wantHint(err, "status", dbErr.ErrConflict)
+
+			// Pets of a checkout cancelled when payment fails are held in cart again
+			f.must(f.h.DeleteOrder(f.ctx, buyerID, orders[0].Id))
+			_, err = f.h.AddCartItem(f.ctx, buyerID, orders[0].PetId)
+			f.must(err)
+		},
+	},
+	{
+		name: "PaymentIntent is saved per order",
+		run: func(f *fixture) {
+			sellerID, buyerID := f.addUser("seller"), f.addUser("buyer")
+			petID := f.addPet(sellerID, f.addCategory("dog"), "rex")
+			orders, err := f.h.PlaceOrders(f.ctx, buyerID, []string{petID})
+			f.must(err)
+			orderID := orders[0].Id
+
+			_, err = f.h.GetPaymentIntent(f.ctx, orderID)
+			f.wantErr(err, dbErr.ErrNotFound)
+			intent := &payments.Intent{
+				ID:       f.name("pi"),
+				Provider: "conformance",
+				Status:   payments.StatusAuthorized,
+				Amount:   1000,
+				Currency: constants.PaymentCurrency,
+			}
+			f.must(f.h.SavePaymentIntent(f.ctx, orderID, intent))
+			captured := *intent
+			captured.Status = payments.StatusCaptured
+			f.must(f.h.SavePaymentIntent(f.ctx, orderID, &captured))
+
+			got, err := f.h.GetPaymentIntent(f.ctx, orderID)
+			f.must(err)
+			if *got != captured {
+				f.t.Errorf("GetPaymentIntent() = %+v, want %+v", got, captured)
+			}
+			_, err = f.h.GetPaymentIntent(f.ctx, invalidID)
+			f.wantHint(err, "order_id", dbErr.ErrInvalidValue)
+		},
+	},
+	{
+		name: "RefreshToken rotation detects reuse",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			expiresOn := time.Now().Add(time.Hour)
+			first, second, third := f.name("first"), f.name("second"), f.name("third")
+			f.must(f.h.AddRefreshToken(f.ctx, userID, first, expiresOn))
+			f.wantErr(f.h.AddRefreshToken(f.ctx, userID, first, expiresOn), dbErr.ErrConflict)
+
+			gotUserID, role, err := f.h.RotateRefreshToken(f.ctx, first, second, expiresOn)
+			f.must(err)
+			if gotUserID != userID || role != genRouter.Customer {
+				f.t.Errorf("RotateRefreshToken() = %v, %v, want %v, %v", gotUserID, role, userID, genRouter.Customer)
+			}
+
+			// Presenting a rotated token revokes the whole family
+			_, _, err = f.h.RotateRefreshToken(f.ctx, first, third, expiresOn)
+			f.wantHint(err, "token_hash", dbErr.ErrConflict)
+			_, _, err = f.h.RotateRefreshToken(f.ctx, second, third, expiresOn)
+			f.wantErr(err, dbErr.ErrNotFound)
+			_, _, err = f.h.RotateRefreshToken(f.ctx, f.name("unknown"), third, expiresOn)
+			f.wantErr(err, dbErr.ErrNotFound)
+		},
+	},
+	{
+		name: "RefreshTokens are revoked on logout, password change & delete",
+		run: func(f *fixture) {
+			userID := f.addUser("alice")
+			expiresOn := time.Now().Add(time.Hour)
+
+			f.must(f.h.AddRefreshToken(f.ctx, userID, f.name("logout"), expiresOn))
+			f.must(f.h.RevokeRefreshTokenFamily(f.ctx, f.name("logout")))
+			_, _, err := f.h.RotateRefreshToken(f.ctx, f.name("logout"), f.name("next"), expiresOn)
+			f.wantErr(err, dbErr.ErrNotFound)
+			f.wantErr(f.h.RevokeRefreshTokenFamily(f.ctx, f.name("unknown")), dbErr.ErrNotFound)
+
+			// Token of the session changing password is revoked as well
+			f.must(f.h.AddRefreshToken(f.ctx, userID, f.name("password"), expiresOn))
+			password := "new-hashed"
+			_, err = f.h.PatchUser(f.ctx, userID, &genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody{
+				Password: &password,
+			})
+			f.must(err)
+			_, _, err = f.h.RotateRefreshToken(f.ctx, f.name("password"), f.name("next"), expiresOn)
+			f.wantErr(err, dbErr.ErrNotFound)
+
+			f.must(f.h.AddRefreshToken(f.ctx, userID, f.name("delete"), expiresOn))
+			f.must(f.h.DeleteUser(f.ctx, userID))
+			_, _, err = f.h.RotateRefreshToken(f.ctx, f.name("delete"), f.name("next"), expiresOn)
+			f.wantErr(err, dbErr.ErrNotFound)
+		},
+	},
+}
+
+// photoBytes & smallPhotoBytes are content of every photo added by the suite. Backends store renditions
+// as given, medium rendition is the original itself as for photos no larger than it
+var (
+	photoBytes      = []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0xff, 0xd9}
+	smallPhotoBytes = []byte{0xff, 0xd8, 0xff, 0xd9}
+)
+
+func photos(n int) []rendition.Set {
+	res := make([]rendition.Set, n)
+	for i := range res {
+		res[i] = rendition.Set{
+			genRouter.Original: photoBytes,
+			genRouter.Small:    smallPhotoBytes,
+			genRouter.Medium:   photoBytes,
+		}
+	}
+	return res
+}
+
+// fixture is state of a single case. Names given to helpers are suffixed with id,
+// so that cases never collide with each other or with data of earlier runs
+type fixture struct {
+	t   *testing.T
+	ctx context.Context
+	h   db.Handler
+	id  string
+}
+
+func (f *fixture) name(s string) string {
+	return s + "-" + f.id
+}
+
+func (f *fixture) must(err error) {
+	f.t.Helper()
+	if err != nil {
+		f.t.Fatalf("unexpected error = %v", err)
+	}
+}
+
+// wantErr fails unless err is want
+func (f *fixture) wantErr(err, want error) {
+	f.t.Helper()
+	if !errors.Is(err, want) {
+		f.t.Errorf("error = %v, want %v", err, want)
+	}
+}
+
+// wantHint fails unless err is a [dbErr.HintError] with key & want
+func (f *fixture) wantHint(err error, key string, want error) {
+	f.t.Helper()
+	var hintErr *dbErr.HintError
+	if !errors.As(err, &hintErr) || hintErr.Key != key || !errors.Is(hintErr.Err, want) {
+		f.t.Errorf("error = %#v, want hint %v with %v", err, key, want)
+	}
+}
+
+// newUser returns request for a user whose username & phone number are derived from given names
+func (f *fixture) newUser(username, phoneNumber string) *genRouter.CreateUserJSONRequestBody {
+	return &genRouter.CreateUserJSONRequestBody{
+		Username:    f.name(username),
+		PhoneNumber: f.name(phoneNumber),
+		FullName:    "Full Name",
+		Address:     "Address",
+		Password:    "hashed",
+	}
+}
+
+// addUser adds a user & returns its ID
+func (f *fixture) addUser(username string) string {
+	f.t.Helper()
+	_, err := f.h.AddUser(f.ctx, f.newUser(username, username))
+	f.must(err)
+	return f.userID(username)
+}
+
+func (f *fixture) userID(username string) string {
+	f.t.Helper()
+	userID, _, _, err := f.h.GetUserCredentials(f.ctx, f.name(username))
+	f.must(err)
+	return userID
+}
+
+// addCategory adds an animal category & returns its name
+func (f *fixture) addCategory(name string) string {
+	f.t.Helper()
+	res, err := f.h.AddAnimalCategory(f.ctx, f.name(name))
+	f.must(err)
+	return res.Name
+}
+
+func (f *fixture) newPet(category, name, price string) *genRouter.Pet {
+	return &genRouter.Pet{
+		Category: category,
+		Name:     f.name(name),
+		Price:    price,
+	}
+}
+
+// addPet adds a pet to category for userID & returns its ID. Name must be unique within the case
+func (f *fixture) addPet(userID, category, name string) string {
+	f.t.Helper()
+	f.must(f.h.AddPet(f.ctx, userID, f.newPet(category, name, "10"), nil))
+	petName := f.name(name)
+	pets, _, _, err := f.h.FindPets(f.ctx, &genRouter.FindPetsParams{Name: &petName}, "", 10)
+	f.must(err)
+	// Same name may be used across categories, the newest pet is the one just added
+	return pets[len(pets)-1].Id
+}
+
+// petID returns ID of the only pet named name
+func (f *fixture) petID(name string) string {
+	f.t.Helper()
+	petName := f.name(name)
+	pets, _, _, err := f.h.FindPets(f.ctx, &genRouter.FindPetsParams{Name: &petName}, "", 10)
+	f.must(err)
+	if len(pets) != 1 {
+		f.t.Fatalf("FindPets() = %v pets named %v, want 1", len(pets), name)
+	}
+	return pets[0].Id
+}
@@ -10,6 +10,11 @@ var (
 	ErrConflict = errors.New("conflict")
 
 	ErrForeignKeyViolation = errors.New("foreign key constraint failed")
+
+	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrOwnPet is returned when a user orders or holds a pet they sell
+	ErrOwnPet = errors.New("own pet")
 )
 
 type HintError struct {
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// AddCartItem holds an available pet for userID until [constants.CartHoldTTL] from now.
+// Adding a pet already held by userID renews the hold
+func (m *memoryClient) AddCartItem(_ context.Context, userID, petID string) (*genRouter.CartItem, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var item *genRouter.CartItem
+	err = m.update(func(d *data) error {
+		p := d.activePet(petRowID)
+		if p == nil {
+			return &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+		}
+		if p.UserID == userRowID {
+			return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+		}
+		if p.Status != string(genRouter.Available) {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+
+		now := time.Now().UTC()
+		if len(d.heldBy(userRowID, now, petRowID)) >= constants.MaxCartItems {
+			return &dbErr.HintError{Key: cartItemsCollection, Err: dbErr.ErrLimitExceeded}
+		}
+		// Holds are unique per pet, so an active hold of another user cannot be taken over.
+		// Expired holds are taken over even if not yet swept
+		if held, ok := d.CartItems[petRowID]; ok && held.UserID != userRowID && held.HeldUntil.After(now) {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+
+		held := &cartItem{
+			ID:        d.nextID(),
+			UserID:    userRowID,
+			PetID:     petRowID,
+			HeldUntil: now.Add(constants.CartHoldTTL),
+			CreatedOn: now,
+		}
+		d.CartItems[petRowID] = held
+		item = &genRouter.CartItem{PetId: petID, HeldUntil: held.HeldUntil}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// FindCartItems returns pets actively held by userID in the order they were added
+func (m *memoryClient) FindCartItems(_ context.Context, userID string) ([]genRouter.CartItem, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var res []genRouter.CartItem
+	err = m.view(func(d *data) error {
+		items := d.heldBy(userRowID, time.Now().UTC(), 0)
+		res = make([]genRouter.CartItem, len(items))
+		for i := range items {
+			res[i] = genRouter.CartItem{PetId: formatID(items[i].PetID), HeldUntil: items[i].HeldUntil}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteCartItem releases hold of userID on a pet
+func (m *memoryClient) DeleteCartItem(_ context.Context, userID, petID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return m.update(func(d *data) error {
+		item, ok := d.CartItems[petRowID]
+		if !ok || item.UserID != userRowID || !item.HeldUntil.After(time.Now().UTC()) {
+			return dbErr.ErrNotFound
+		}
+		delete(d.CartItems, petRowID)
+		return nil
+	})
+}
+
+// CheckoutCart places orders for pets actively held by userID, same as PlaceOrders.
+// Cart is emptied once orders are placed. Returns [dbErr.ErrNotFound] when cart is empty
+func (m *memoryClient) CheckoutCart(_ context.Context, userID string) ([]genRouter.Order, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var orders []genRouter.Order
+	err = m.update(func(d *data) error {
+		items := d.heldBy(userRowID, time.Now().UTC(), 0)
+		if len(items) == 0 {
+			return dbErr.ErrNotFound
+		}
+		petIDs := make([]int64, len(items))
+		for i := range items {
+			petIDs[i] = items[i].PetID
+		}
+
+		var errS error
+		orders, errS = d.placeOrders(userRowID, petIDs)
+		if errS != nil {
+			return errS
+		}
+
+		// Expired holds left behind in cart go along
+		for petID, item := range d.CartItems {
+			if item.UserID == userRowID {
+				delete(d.CartItems, petID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ReleaseExpiredHolds deletes holds which have expired & returns the count of holds released
+func (m *memoryClient) ReleaseExpiredHolds(_ context.Context) (int64, error) {
+	var released int64
+	err := m.update(func(d *data) error {
+		now := time.Now().UTC()
+		for petID, item := range d.CartItems {
+			if !item.HeldUntil.After(now) {
+				delete(d.CartItems, petID)
+				released++
+			}
+		}
+		return nil
+	})
+	return released, err
+}
+
+// heldBy returns holds of userID active at now in the order they were added, excluding hold on exceptPetID
+func (d *data) heldBy(userID int64, now time.Time, exceptPetID int64) []*cartItem {
+	items := make([]*cartItem, 0)
+	for _, item := range d.CartItems {
+		if item.UserID == userID && item.PetID != exceptPetID && item.HeldUntil.After(now) {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b *cartItem) int {
+		return cmp.Or(a.CreatedOn.Compare(b.CreatedOn), cmp.Compare(a.ID, b.ID))
+	})
+	return items
+}
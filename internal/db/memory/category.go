@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// maxCategoryEdits & category names sharing the first letter mirror
+// Atlas Search fuzzy options maxEdits & prefixLength of Mongo backend
+const maxCategoryEdits = 2
+
+func (m *memoryClient) FindAnimalCategory(_ context.Context,
+	name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	var res *genRouter.AnimalCategoryJSONResponse
+	err := m.view(func(d *data) error {
+		// Try exact match
+		if category := d.findAnimalCategory(name); category != nil {
+			res = category.toAPI()
+			return nil
+		}
+
+		// Try fuzzy search, closest name wins
+		var (
+			closest  *animalCategory
+			minEdits = maxCategoryEdits + 1
+		)
+		query := strings.ToLower(name)
+		for _, category := range d.AnimalCategories {
+			candidate := strings.ToLower(category.Name)
+			if !sameFirstRune(candidate, query) {
+				continue
+			}
+			edits := levenshtein(candidate, query)
+			if edits < minEdits || (edits == minEdits && closest != nil && category.Name < closest.Name) {
+				closest, minEdits = category, edits
+			}
+		}
+		if closest == nil {
+			return dbErr.ErrNotFound
+		}
+		res = closest.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *memoryClient) AddAnimalCategory(_ context.Context, name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	var res *genRouter.AnimalCategoryJSONResponse
+	err := m.update(func(d *data) error {
+		if d.findAnimalCategory(name) != nil {
+			return dbErr.ErrConflict
+		}
+		category := &animalCategory{
+			ID:        d.nextID(),
+			Name:      name,
+			CreatedOn: time.Now().UTC(),
+		}
+		d.AnimalCategories[category.ID] = category
+		res = category.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *memoryClient) UpdateAnimalCategory(_ context.Context, id,
+	name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	rowID, err := parseID(id)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	err = m.update(func(d *data) error {
+		category, ok := d.AnimalCategories[rowID]
+		if !ok {
+			return dbErr.ErrNotFound
+		}
+		if other := d.findAnimalCategory(name); other != nil && other.ID != rowID {
+			return dbErr.ErrConflict
+		}
+		now := time.Now().UTC()
+		category.Name = name
+		category.UpdatedOn = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &genRouter.AnimalCategoryJSONResponse{
+		Id:   id,
+		Name: name,
+	}, nil
+}
+
+// findAnimalCategory returns category named name, nil when there is none
+func (d *data) findAnimalCategory(name string) *animalCategory {
+	for _, category := range d.AnimalCategories {
+		if category.Name == name {
+			return category
+		}
+	}
+	return nil
+}
+
+func (c *animalCategory) toAPI() *genRouter.AnimalCategoryJSONResponse {
+	return &genRouter.AnimalCategoryJSONResponse{
+		Id:   formatID(c.ID),
+		Name: c.Name,
+	}
+}
+
+func sameFirstRune(a, b string) bool {
+	ra, _ := utf8.DecodeRuneInString(a)
+	rb, _ := utf8.DecodeRuneInString(b)
+	return a != "" && b != "" && ra == rb
+}
+
+// levenshtein returns count of single rune insertions, deletions & substitutions turning a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,316 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+const (
+	// ENV vars
+	snapshotFileEnvVar string = "MEMORY_SNAPSHOT_FILE" // Path of JSON snapshot, data is not persisted when unset
+
+	snapshotInterval = time.Minute
+
+	// Keys of [dbErr.HintError], same as field names used by Mongo backend
+	iDField                  string = "_id"
+	userIDField              string = "user_id"
+	petIDField               string = "pet_id"
+	orderIDField             string = "order_id"
+	statusField              string = "status"
+	priceField               string = "price"
+	phoneNumberField         string = "phone_number"
+	usernameField            string = "username"
+	tokenHashField           string = "token_hash"
+	sellerIDField            string = "seller_id"
+	animalCategoryCollection string = "animal_categories"
+	petsCollection           string = "pets"
+	imagesCollection         string = "images"
+	ordersCollection         string = "orders"
+	cartItemsCollection      string = "cart_items"
+)
+
+// memoryClient keeps every collection in process memory. A single lock serialises writes,
+// hence each operation is atomic & isolated, same as a transaction of other backends
+type memoryClient struct {
+	mu   sync.RWMutex
+	data *data
+
+	snapshotFile string
+	dirty        atomic.Bool
+	stop         chan struct{}
+	stopped      sync.WaitGroup
+	closeOnce    sync.Once
+}
+
+//revive:disable:unexported-return
+func NewInstance(ctx context.Context) *memoryClient {
+	m := &memoryClient{
+		data:         newData(),
+		snapshotFile: os.Getenv(snapshotFileEnvVar),
+		stop:         make(chan struct{}),
+	}
+	if m.snapshotFile == "" {
+		return m
+	}
+
+	if err := m.load(); err != nil {
+		panic(fmt.Sprintf("Failed to load memory snapshot %v", err))
+	}
+	m.stopped.Go(func() {
+		logger := log.Ctx(ctx)
+		ticker := time.NewTicker(snapshotInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				if err := m.save(); err != nil {
+					logger.Error().Err(err).Msg("Failed to save memory snapshot")
+				}
+			}
+		}
+	})
+	return m
+}
+
+// Close saves a final snapshot when persistence is enabled
+func (m *memoryClient) Close(_ context.Context) error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		m.stopped.Wait()
+		if m.snapshotFile != "" {
+			err = m.save()
+		}
+	})
+	return err
+}
+
+// view runs fn holding a read lock. fn must not modify data
+func (m *memoryClient) view(fn func(d *data) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(m.data)
+}
+
+// update runs fn holding the write lock. fn must validate everything before modifying data,
+// since changes made before an error are not rolled back
+func (m *memoryClient) update(fn func(d *data) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := fn(m.data); err != nil {
+		return err
+	}
+	m.dirty.Store(true)
+	return nil
+}
+
+// load restores data from snapshot file, a missing file is an empty store
+func (m *memoryClient) load() error {
+	raw, err := os.ReadFile(m.snapshotFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(raw, m.data)
+}
+
+// save writes data to snapshot file when changed since last save. File is replaced atomically,
+// so that a crash midway never leaves a truncated snapshot behind
+func (m *memoryClient) save() error {
+	if !m.dirty.Swap(false) {
+		return nil
+	}
+
+	m.mu.RLock()
+	raw, err := json.Marshal(m.data)
+	m.mu.RUnlock()
+	if err == nil {
+		err = writeFileAtomic(m.snapshotFile, raw)
+	}
+	if err != nil {
+		m.dirty.Store(true)
+		return err
+	}
+	return nil
+}
+
+func writeFileAtomic(name string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// parseID converts IDs handed out by this backend back to map keys
+func parseID(id string) (int64, error) {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || rowID <= 0 {
+		return 0, errors.New("invalid id")
+	}
+	return rowID, nil
+}
+
+func formatID(rowID int64) string {
+	return strconv.FormatInt(rowID, 10)
+}
+
+// data holds every collection, keyed by ID unless noted otherwise. It is also the snapshot format
+type data struct {
+	LastID           int64                     `json:"last_id"`
+	AnimalCategories map[int64]*animalCategory `json:"animal_categories"`
+	Users            map[int64]*user           `json:"users"`
+	Pets             map[int64]*pet            `json:"pets"`
+	Images           map[int64]*image          `json:"images"`
+	Orders           map[int64]*order          `json:"orders"`
+	CartItems        map[int64]*cartItem       `json:"cart_items"`      // Keyed by pet ID
+	PaymentIntents   map[int64]*paymentIntent  `json:"payment_intents"` // Keyed by order ID
+	RefreshTokens    map[string]*refreshToken  `json:"refresh_tokens"`  // Keyed by token hash
+}
+
+func newData() *data {
+	return &data{
+		AnimalCategories: map[int64]*animalCategory{},
+		Users:            map[int64]*user{},
+		Pets:             map[int64]*pet{},
+		Images:           map[int64]*image{},
+		Orders:           map[int64]*order{},
+		CartItems:        map[int64]*cartItem{},
+		PaymentIntents:   map[int64]*paymentIntent{},
+		RefreshTokens:    map[string]*refreshToken{},
+	}
+}
+
+// nextID returns a new ID, IDs are shared by all collections & increase monotonically
+func (d *data) nextID() int64 {
+	d.LastID++
+	return d.LastID
+}
+
+type animalCategory struct {
+	ID        int64      `json:"_id"`
+	Name      string     `json:"name"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on"`
+}
+
+type pet struct {
+	ID         int64      `json:"_id"`
+	Name       string     `json:"name"`
+	CategoryID int64      `json:"category_id"`
+	UserID     int64      `json:"user_id"`
+	Price      string     `json:"price"` // Canonical decimal string
+	Status     string     `json:"status"`
+	Tags       []string   `json:"tags"`
+	CreatedOn  time.Time  `json:"created_on"`
+	UpdatedOn  *time.Time `json:"updated_on"`
+	DeletedOn  *time.Time `json:"deleted_on"`
+}
+
+type image struct {
+	ID         int64                          `json:"_id"`
+	PetID      int64                          `json:"pet_id"`
+	UserID     int64                          `json:"user_id"`
+	Image      []byte                         `json:"image"`
+	Renditions map[genRouter.ImageSize][]byte `json:"renditions,omitempty"` // Missing in older snapshots
+	DeletedOn  *time.Time                     `json:"deleted_on"`
+}
+
+type user struct {
+	ID          int64      `json:"_id"`
+	Username    string     `json:"username"`
+	FullName    string     `json:"full_name"`
+	Password    string     `json:"password"`
+	Address     string     `json:"address"`
+	PhoneNumber string     `json:"phone_number"`
+	Role        string     `json:"role"`
+	CreatedOn   time.Time  `json:"created_on"`
+	UpdatedOn   *time.Time `json:"updated_on"`
+	DeletedOn   *time.Time `json:"deleted_on"`
+}
+
+type order struct {
+	ID             int64         `json:"_id"`
+	UserID         int64         `json:"user_id"`
+	PetID          int64         `json:"pet_id"`
+	SellerID       int64         `json:"seller_id"`
+	Price          string        `json:"price"`
+	ShippedDate    *time.Time    `json:"shipped_date"`
+	DeliveredDate  *time.Time    `json:"delivered_date"`
+	TrackingNumber *string       `json:"tracking_number"`
+	Carrier        *string       `json:"carrier"`
+	Status         string        `json:"status"`
+	StatusHistory  []statusEntry `json:"status_history"`
+	CreatedOn      time.Time     `json:"created_on"`
+	UpdatedOn      *time.Time    `json:"updated_on"`
+}
+
+// statusEntry records an order reaching a status. Entries are only ever appended
+type statusEntry struct {
+	Status    string    `json:"status"`
+	ChangedOn time.Time `json:"changed_on"`
+}
+
+// paymentIntent tracks payment of an order with a payment provider. An order has atmost one intent
+type paymentIntent struct {
+	OrderID   int64      `json:"order_id"`
+	Provider  string     `json:"provider"`
+	IntentID  string     `json:"intent_id"`
+	Status    string     `json:"status"`
+	Amount    int64      `json:"amount"`
+	Currency  string     `json:"currency"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on"`
+}
+
+// cartItem is a time limited hold of a user on a pet. A pet is held by atmost one user
+type cartItem struct {
+	ID        int64     `json:"_id"`
+	UserID    int64     `json:"user_id"`
+	PetID     int64     `json:"pet_id"`
+	HeldUntil time.Time `json:"held_until"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+type refreshToken struct {
+	UserID    int64      `json:"user_id"`
+	FamilyID  int64      `json:"family_id"`
+	TokenHash string     `json:"token_hash"`
+	ExpiresOn time.Time  `json:"expires_on"`
+	CreatedOn time.Time  `json:"created_on"`
+	RotatedOn *time.Time `json:"rotated_on"`
+	RevokedOn *time.Time `json:"revoked_on"`
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(snapshotFileEnvVar, filepath.Join(t.TempDir(), "snapshot.json"))
+
+	m := NewInstance(ctx)
+	category, err := m.AddAnimalCategory(ctx, "Dog")
+	if err != nil {
+		t.Fatalf("AddAnimalCategory() error = %v", err)
+	}
+	if err = m.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	restored := NewInstance(ctx)
+	defer restored.Close(ctx)
+	got, err := restored.FindAnimalCategory(ctx, "Dog")
+	if err != nil {
+		t.Fatalf("FindAnimalCategory() error = %v", err)
+	}
+	if *got != *category {
+		t.Errorf("FindAnimalCategory() = %v, want %v", got, category)
+	}
+	// IDs are not reused after restore
+	other, _ := restored.AddAnimalCategory(ctx, "Cat")
+	if other.Id == category.Id {
+		t.Errorf("AddAnimalCategory() reused ID %v", other.Id)
+	}
+}
+
+func TestUniqueConstraints(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	m := NewInstance(ctx)
+
+	newUser := func(username, phoneNumber string) *genRouter.CreateUserJSONRequestBody {
+		return &genRouter.CreateUserJSONRequestBody{
+			Username:    username,
+			PhoneNumber: phoneNumber,
+			FullName:    "Full Name",
+			Address:     "Address",
+			Password:    "hashed",
+		}
+	}
+	if _, err := m.AddUser(ctx, newUser("alice", "+100")); err != nil {
+		t.Fatalf("AddUser() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    *genRouter.CreateUserJSONRequestBody
+		wantKey string
+	}{
+		{
+			name:    "duplicate username",
+			user:    newUser("alice", "+200"),
+			wantKey: usernameField,
+		},
+		{
+			name:    "duplicate phone number",
+			user:    newUser("bob", "+100"),
+			wantKey: phoneNumberField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.AddUser(ctx, tt.user)
+			var hintErr *dbErr.HintError
+			if !errors.As(err, &hintErr) || hintErr.Key != tt.wantKey || !errors.Is(hintErr.Err, dbErr.ErrConflict) {
+				t.Errorf("AddUser() error = %v, want conflict on %s", err, tt.wantKey)
+			}
+		})
+	}
+
+	// Deleted users release their username & phone number
+	userID, _, _, err := m.GetUserCredentials(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUserCredentials() error = %v", err)
+	}
+	if err = m.DeleteUser(ctx, userID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err = m.AddUser(ctx, newUser("alice", "+100")); err != nil {
+		t.Errorf("AddUser() after delete error = %v", err)
+	}
+}
+
+func TestFindAnimalCategory(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	m := NewInstance(ctx)
+	for _, name := range []string{"Dog", "Cat", "Parrot"} {
+		if _, err := m.AddAnimalCategory(ctx, name); err != nil {
+			t.Fatalf("AddAnimalCategory() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "exact",
+			query: "Cat",
+			want:  "Cat",
+		},
+		{
+			name:  "misspelt",
+			query: "parot",
+			want:  "Parrot",
+		},
+		{
+			name:    "different first letter",
+			query:   "Bog",
+			wantErr: dbErr.ErrNotFound,
+		},
+		{
+			name:    "too many edits",
+			query:   "Dinosaur",
+			wantErr: dbErr.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := m.FindAnimalCategory(ctx, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindAnimalCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("FindAnimalCategory() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
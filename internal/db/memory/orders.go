@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+)
+
+// PlaceOrders places an order for each of petIDs on behalf of userID & marks those pets sold.
+// Either all orders are placed or none, pets must exist, be available & not be owned by userID
+func (m *memoryClient) PlaceOrders(_ context.Context, userID string, petIDs []string) ([]genRouter.Order, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowIDs := make([]int64, len(petIDs))
+	for i := range petIDs {
+		petRowIDs[i], err = parseID(petIDs[i])
+		if err != nil {
+			return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+		}
+	}
+
+	var orders []genRouter.Order
+	err = m.update(func(d *data) error {
+		var errS error
+		orders, errS = d.placeOrders(userRowID, petRowIDs)
+		return errS
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// placeOrders is PlaceOrders holding the write lock. Pets held in cart of other users cannot be ordered,
+// while holds of userID on ordered pets are released
+func (d *data) placeOrders(userID int64, petIDs []int64) ([]genRouter.Order, error) {
+	now := time.Now().UTC()
+	pets := make([]*pet, len(petIDs))
+	for i, petID := range petIDs {
+		pets[i] = d.activePet(petID)
+		if pets[i] == nil || slices.Contains(petIDs[:i], petID) {
+			return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+		}
+	}
+	for _, p := range pets {
+		if p.UserID == userID {
+			return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+		}
+	}
+	for _, p := range pets {
+		if item, ok := d.CartItems[p.ID]; ok && item.UserID != userID && item.HeldUntil.After(now) {
+			return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+		if p.Status != string(genRouter.Available) {
+			return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+	}
+
+	// Price is fixed when ordered, pets may be repriced afterwards
+	orders := make([]genRouter.Order, len(pets))
+	for i, p := range pets {
+		p.Status = string(genRouter.Sold)
+		p.UpdatedOn = &now
+		delete(d.CartItems, p.ID)
+
+		o := &order{
+			ID:            d.nextID(),
+			UserID:        userID,
+			PetID:         p.ID,
+			SellerID:      p.UserID,
+			Price:         p.Price,
+			Status:        string(genRouter.Placed),
+			StatusHistory: []statusEntry{{Status: string(genRouter.Placed), ChangedOn: now}},
+			CreatedOn:     now,
+		}
+		d.Orders[o.ID] = o
+		orders[i] = o.toAPI()
+	}
+	return orders, nil
+}
+
+// FindOrders returns a page of orders of userID matching params in increasing order of id, starting after afterID.
+// Returns orders along with total count of matching orders & ID to resume from, empty when on last page
+func (m *memoryClient) FindOrders(_ context.Context, userID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, 0, "", &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	return m.findOrders(func(o *order) bool { return o.UserID == userRowID }, params, afterID, limit)
+}
+
+// FindSellerOrders is same as FindOrders but for orders placed against pets of sellerID
+func (m *memoryClient) FindSellerOrders(_ context.Context, sellerID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	sellerRowID, err := parseID(sellerID)
+	if err != nil {
+		return nil, 0, "", &dbErr.HintError{Key: sellerIDField, Err: dbErr.ErrInvalidValue}
+	}
+	return m.findOrders(func(o *order) bool { return o.SellerID == sellerRowID }, params, afterID, limit)
+}
+
+// findOrders pages through orders matching belongs & params
+func (m *memoryClient) findOrders(belongs func(o *order) bool, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	var rowAfterID int64
+	if afterID != "" {
+		var err error
+		rowAfterID, err = parseID(afterID)
+		if err != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
+		}
+	}
+
+	var (
+		orders []genRouter.Order
+		count  int
+		nextID string
+	)
+	err := m.view(func(d *data) error {
+		matched := make([]*order, 0)
+		for _, o := range d.Orders {
+			if !belongs(o) {
+				continue
+			}
+			if params.Status != nil && !slices.Contains(*params.Status, genRouter.OrderStatus(o.Status)) {
+				continue
+			}
+			if params.AfterDate != nil && !o.CreatedOn.After(*params.AfterDate) {
+				continue
+			}
+			matched = append(matched, o)
+		}
+		count = len(matched)
+
+		// Keyset pagination over id
+		slices.SortFunc(matched, func(a, b *order) int { return cmp.Compare(a.ID, b.ID) })
+		start, _ := slices.BinarySearchFunc(matched, rowAfterID+1, func(o *order, id int64) int {
+			return cmp.Compare(o.ID, id)
+		})
+		page := matched[start:]
+		if len(page) > limit {
+			page = page[:limit]
+			nextID = formatID(page[limit-1].ID)
+		}
+		orders = make([]genRouter.Order, len(page))
+		for i := range page {
+			orders[i] = page[i].toAPI()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return orders, count, nextID, nil
+}
+
+func (m *memoryClient) GetOrder(_ context.Context, orderID string) (*genRouter.Order, error) {
+	rowID, err := parseID(orderID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	var res genRouter.Order
+	err = m.view(func(d *data) error {
+		o, ok := d.Orders[rowID]
+		if !ok {
+			return dbErr.ErrNotFound
+		}
+		res = o.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeleteOrder cancels an order of userID & makes its pet available again. Orders are kept
+// for bookkeeping, hence only status is changed as per [orderstate]
+func (m *memoryClient) DeleteOrder(_ context.Context, userID, orderID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return m.update(func(d *data) error {
+		o, ok := d.Orders[orderRowID]
+		if !ok || o.UserID != userRowID {
+			return dbErr.ErrNotFound
+		}
+		change, errS := o.transition(genRouter.Cancelled)
+		if errS != nil {
+			return errS
+		}
+		if p, found := d.Pets[o.PetID]; found && p.Status == string(genRouter.Sold) {
+			p.Status = string(genRouter.Available)
+			p.UpdatedOn = &change.ChangedOn
+		}
+		return nil
+	})
+}
+
+// UpdateOrderStatus moves an order placed against a pet of sellerID forward as per [orderstate].
+// Tracking details are recorded along with the status when given
+func (m *memoryClient) UpdateOrderStatus(_ context.Context, sellerID, orderID string,
+	statusReq *genRouter.UpdateOrderStatusJSONRequestBody) (*genRouter.Order, error) {
+	sellerRowID, err := parseID(sellerID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: sellerIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var res genRouter.Order
+	err = m.update(func(d *data) error {
+		o, ok := d.Orders[orderRowID]
+		if !ok || o.SellerID != sellerRowID {
+			return dbErr.ErrNotFound
+		}
+		if _, errS := o.transition(genRouter.OrderStatus(statusReq.Status)); errS != nil {
+			return errS
+		}
+		if statusReq.TrackingNumber != nil {
+			trackingNumber := *statusReq.TrackingNumber
+			o.TrackingNumber = &trackingNumber
+		}
+		if statusReq.Carrier != nil {
+			carrier := *statusReq.Carrier
+			o.Carrier = &carrier
+		}
+		res = o.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// transition moves order to status & records it in status history
+func (o *order) transition(status genRouter.OrderStatus) (*orderstate.Change, error) {
+	change, err := orderstate.Transition(genRouter.OrderStatus(o.Status), status, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	o.Status = string(change.Status)
+	o.UpdatedOn = &change.ChangedOn
+	if change.ShippedDate != nil {
+		o.ShippedDate = change.ShippedDate
+	}
+	if change.DeliveredDate != nil {
+		o.DeliveredDate = change.DeliveredDate
+	}
+	o.StatusHistory = append(o.StatusHistory, statusEntry{
+		Status:    string(change.Status),
+		ChangedOn: change.ChangedOn,
+	})
+	return change, nil
+}
+
+// toAPI copies order, so that callers never share memory with the store
+func (o *order) toAPI() genRouter.Order {
+	res := genRouter.Order{
+		Id:     formatID(o.ID),
+		PetId:  formatID(o.PetID),
+		Price:  o.Price,
+		Status: genRouter.OrderStatus(o.Status),
+	}
+	if o.ShippedDate != nil {
+		shippedDate := *o.ShippedDate
+		res.ShippedDate = &shippedDate
+	}
+	if o.DeliveredDate != nil {
+		deliveredDate := *o.DeliveredDate
+		res.DeliveredDate = &deliveredDate
+	}
+	if o.TrackingNumber != nil {
+		trackingNumber := *o.TrackingNumber
+		res.TrackingNumber = &trackingNumber
+	}
+	if o.Carrier != nil {
+		carrier := *o.Carrier
+		res.Carrier = &carrier
+	}
+	return res
+}
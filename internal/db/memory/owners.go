@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+)
+
+func (m *memoryClient) GetPetOwner(_ context.Context, petID string) (string, error) {
+	return m.findOwner(petID, func(d *data, id int64) (int64, bool) {
+		p := d.activePet(id)
+		if p == nil {
+			return 0, false
+		}
+		return p.UserID, true
+	})
+}
+
+func (m *memoryClient) GetImageOwner(_ context.Context, imageID string) (string, error) {
+	return m.findOwner(imageID, func(d *data, id int64) (int64, bool) {
+		i, ok := d.Images[id]
+		if !ok || i.DeletedOn != nil {
+			return 0, false
+		}
+		return i.UserID, true
+	})
+}
+
+func (m *memoryClient) GetOrderOwner(_ context.Context, orderID string) (string, error) {
+	return m.findOwner(orderID, func(d *data, id int64) (int64, bool) {
+		o, ok := d.Orders[id]
+		if !ok {
+			return 0, false
+		}
+		return o.UserID, true
+	})
+}
+
+// GetOrderSeller returns userID owning the pet an order was placed for
+func (m *memoryClient) GetOrderSeller(_ context.Context, orderID string) (string, error) {
+	return m.findOwner(orderID, func(d *data, id int64) (int64, bool) {
+		o, ok := d.Orders[id]
+		if !ok {
+			return 0, false
+		}
+		return o.SellerID, true
+	})
+}
+
+// findOwner resolves owner of the record identified by id with owner
+func (m *memoryClient) findOwner(id string, owner func(d *data, id int64) (int64, bool)) (string, error) {
+	rowID, err := parseID(id)
+	if err != nil {
+		return "", &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var ownerID int64
+	err = m.view(func(d *data) error {
+		var ok bool
+		if ownerID, ok = owner(d, rowID); !ok {
+			return dbErr.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatID(ownerID), nil
+}
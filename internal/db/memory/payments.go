@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+// SavePaymentIntent records latest state of the payment intent of an order, replacing an earlier one
+func (m *memoryClient) SavePaymentIntent(_ context.Context, orderID string, intent *payments.Intent) error {
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return m.update(func(d *data) error {
+		// Intents are unique per provider, same as the unique index of Mongo backend
+		for otherOrderID, other := range d.PaymentIntents {
+			if otherOrderID != orderRowID && other.Provider == intent.Provider && other.IntentID == intent.ID {
+				return dbErr.ErrConflict
+			}
+		}
+
+		now := time.Now().UTC()
+		saved, ok := d.PaymentIntents[orderRowID]
+		if !ok {
+			saved = &paymentIntent{OrderID: orderRowID, CreatedOn: now}
+			d.PaymentIntents[orderRowID] = saved
+		} else {
+			saved.UpdatedOn = &now
+		}
+		saved.Provider = intent.Provider
+		saved.IntentID = intent.ID
+		saved.Status = string(intent.Status)
+		saved.Amount = intent.Amount
+		saved.Currency = intent.Currency
+		return nil
+	})
+}
+
+// GetPaymentIntent returns payment intent of an order, [dbErr.ErrNotFound] when order has none
+func (m *memoryClient) GetPaymentIntent(_ context.Context, orderID string) (*payments.Intent, error) {
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var res *payments.Intent
+	err = m.view(func(d *data) error {
+		saved, ok := d.PaymentIntents[orderRowID]
+		if !ok {
+			return dbErr.ErrNotFound
+		}
+		res = &payments.Intent{
+			ID:       saved.IntentID,
+			Provider: saved.Provider,
+			Status:   payments.Status(saved.Status),
+			Amount:   saved.Amount,
+			Currency: saved.Currency,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
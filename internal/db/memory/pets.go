@@ -0,0 +1,403 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"io"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/rendition"
+)
+
+func (m *memoryClient) AddPet(_ context.Context, userID string,
+	petReq *genRouter.Pet, photos []rendition.Set) error {
+	// Validate userID string
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	// Validate price string
+	price, err := parsePrice(petReq.Price)
+	if err != nil {
+		return err
+	}
+
+	return m.update(func(d *data) error {
+		category := d.findAnimalCategory(petReq.Category)
+		if category == nil {
+			return &dbErr.HintError{Key: animalCategoryCollection, Err: dbErr.ErrNotFound}
+		}
+		if d.activeUser(userRowID) == nil {
+			return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrNotFound}
+		}
+		if d.findPet(petReq.Name, category.ID) != nil {
+			return dbErr.ErrConflict
+		}
+
+		var tags []string
+		if petReq.Tags != nil {
+			tags = slices.Clone(*petReq.Tags)
+		}
+		p := &pet{
+			ID:         d.nextID(),
+			Name:       petReq.Name,
+			CategoryID: category.ID,
+			UserID:     userRowID,
+			Price:      price,
+			Status:     string(genRouter.Available),
+			Tags:       tags,
+			CreatedOn:  time.Now().UTC(),
+		}
+		d.Pets[p.ID] = p
+		d.insertPetImages(userRowID, p.ID, photos)
+		return nil
+	})
+}
+
+// ReplacePet replaces details & photos of a pet owned by userID. Previous photos are soft-deleted.
+// Sold pets cannot be replaced
+func (m *memoryClient) ReplacePet(_ context.Context, userID, petID string,
+	petReq *genRouter.Pet, photos []rendition.Set) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	price, err := parsePrice(petReq.Price)
+	if err != nil {
+		return err
+	}
+	status := genRouter.Available
+	if petReq.Status != nil {
+		status = *petReq.Status
+	}
+	var tags []string
+	if petReq.Tags != nil {
+		tags = slices.Clone(*petReq.Tags)
+	}
+
+	return m.update(func(d *data) error {
+		category := d.findAnimalCategory(petReq.Category)
+		if category == nil {
+			return &dbErr.HintError{Key: animalCategoryCollection, Err: dbErr.ErrNotFound}
+		}
+		p := d.activePet(petRowID)
+		if p == nil || p.UserID != userRowID {
+			return dbErr.ErrNotFound
+		}
+		if p.Status == string(genRouter.Sold) {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+		if other := d.findPet(petReq.Name, category.ID); other != nil && other.ID != petRowID {
+			return dbErr.ErrConflict
+		}
+
+		now := time.Now().UTC()
+		p.Name = petReq.Name
+		p.CategoryID = category.ID
+		p.Price = price
+		p.Status = string(status)
+		p.Tags = tags
+		p.UpdatedOn = &now
+		d.deletePetImages(petRowID, now)
+		d.insertPetImages(userRowID, petRowID, photos)
+		return nil
+	})
+}
+
+// DeletePet soft-deletes a pet owned by userID along with its images.
+// Pets with orders that are neither delivered nor cancelled cannot be deleted
+func (m *memoryClient) DeletePet(_ context.Context, userID, petID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return m.update(func(d *data) error {
+		for _, o := range d.Orders {
+			if o.PetID == petRowID && o.Status != string(genRouter.Delivered) &&
+				o.Status != string(genRouter.Cancelled) {
+				return &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
+			}
+		}
+		p := d.activePet(petRowID)
+		if p == nil || p.UserID != userRowID {
+			return dbErr.ErrNotFound
+		}
+
+		now := time.Now().UTC()
+		p.DeletedOn = &now
+		d.deletePetImages(petRowID, now)
+		return nil
+	})
+}
+
+// AddPetImages appends photos to a pet owned by userID & returns IDs of the new images.
+// Count of active images of a pet never exceeds [constants.MaxPetImages]
+func (m *memoryClient) AddPetImages(_ context.Context, userID, petID string,
+	photos []rendition.Set) ([]string, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var imageIDs []string
+	err = m.update(func(d *data) error {
+		p := d.activePet(petRowID)
+		if p == nil || p.UserID != userRowID {
+			return dbErr.ErrNotFound
+		}
+		if len(d.petImageIDs(petRowID))+len(photos) > constants.MaxPetImages {
+			return &dbErr.HintError{Key: imagesCollection, Err: dbErr.ErrLimitExceeded}
+		}
+		imageIDs = d.insertPetImages(userRowID, petRowID, photos)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imageIDs, nil
+}
+
+// GetPetImage returns rendition of an image in size. Images stored before renditions were added
+// are returned in original size
+func (m *memoryClient) GetPetImage(_ context.Context, imageID string,
+	size genRouter.ImageSize) (io.Reader, int64, error) {
+	rowID, err := parseID(imageID)
+	if err != nil {
+		return nil, 0, dbErr.ErrInvalidValue
+	}
+
+	var img []byte
+	err = m.view(func(d *data) error {
+		i, ok := d.Images[rowID]
+		if !ok || i.DeletedOn != nil {
+			return dbErr.ErrNotFound
+		}
+		// Stored bytes are never modified, hence safe to read without the lock
+		img = i.Image
+		if r, ok := i.Renditions[size]; ok {
+			img = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(img), int64(len(img)), nil
+}
+
+func (m *memoryClient) DeletePetImage(_ context.Context, userID, imageID string) error {
+	imageRowID, err := parseID(imageID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return m.update(func(d *data) error {
+		i, ok := d.Images[imageRowID]
+		if !ok || i.UserID != userRowID || i.DeletedOn != nil {
+			return dbErr.ErrNotFound
+		}
+		now := time.Now().UTC()
+		i.DeletedOn = &now
+		return nil
+	})
+}
+
+// FindPets returns a page of pets matching params in increasing order of id, starting after afterID.
+// Returns pets along with total count of matching pets & ID to resume from, empty when on last page
+func (m *memoryClient) FindPets(_ context.Context, params *genRouter.FindPetsParams,
+	afterID string, limit int) ([]genRouter.PetWithMetadata, int, string, error) {
+	var rowAfterID int64
+	if afterID != "" {
+		var err error
+		rowAfterID, err = parseID(afterID)
+		if err != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
+		}
+	}
+
+	var (
+		pets   []genRouter.PetWithMetadata
+		count  int
+		nextID string
+	)
+	err := m.view(func(d *data) error {
+		matched := make([]*pet, 0)
+		for _, p := range d.Pets {
+			if p.DeletedOn != nil {
+				continue
+			}
+			if params.Name != nil && p.Name != *params.Name {
+				continue
+			}
+			if params.Status != nil && !slices.Contains(*params.Status, genRouter.PetStatus(p.Status)) {
+				continue
+			}
+			if params.Tags != nil && !slices.ContainsFunc(p.Tags, func(tag string) bool {
+				return slices.Contains(*params.Tags, tag)
+			}) {
+				continue
+			}
+			matched = append(matched, p)
+		}
+		count = len(matched)
+
+		// Keyset pagination over id
+		slices.SortFunc(matched, func(a, b *pet) int { return cmp.Compare(a.ID, b.ID) })
+		start, _ := slices.BinarySearchFunc(matched, rowAfterID+1, func(p *pet, id int64) int {
+			return cmp.Compare(p.ID, id)
+		})
+		page := matched[start:]
+		if len(page) > limit {
+			page = page[:limit]
+			nextID = formatID(page[limit-1].ID)
+		}
+		pets = make([]genRouter.PetWithMetadata, len(page))
+		for i := range page {
+			pets[i] = d.petToAPI(page[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return pets, count, nextID, nil
+}
+
+func (m *memoryClient) GetPet(_ context.Context, petID string) (*genRouter.PetWithMetadata, error) {
+	rowID, err := parseID(petID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	var res genRouter.PetWithMetadata
+	err = m.view(func(d *data) error {
+		p := d.activePet(rowID)
+		if p == nil {
+			return dbErr.ErrNotFound
+		}
+		res = d.petToAPI(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// activePet returns pet identified by id unless deleted, nil otherwise
+func (d *data) activePet(id int64) *pet {
+	p, ok := d.Pets[id]
+	if !ok || p.DeletedOn != nil {
+		return nil
+	}
+	return p
+}
+
+// findPet returns active pet named name in a category, nil when there is none.
+// Name is unique within a category among active pets
+func (d *data) findPet(name string, categoryID int64) *pet {
+	for _, p := range d.Pets {
+		if p.DeletedOn == nil && p.Name == name && p.CategoryID == categoryID {
+			return p
+		}
+	}
+	return nil
+}
+
+// insertPetImages stores photos of a pet & returns their IDs in the same order
+func (d *data) insertPetImages(userID, petID int64, photos []rendition.Set) []string {
+	imageIDs := make([]string, len(photos))
+	for i := range photos {
+		img := &image{
+			ID:         d.nextID(),
+			PetID:      petID,
+			UserID:     userID,
+			Image:      slices.Clone(photos[i][genRouter.Original]),
+			Renditions: map[genRouter.ImageSize][]byte{},
+		}
+		for size := range rendition.Sizes {
+			img.Renditions[size] = slices.Clone(photos[i][size])
+		}
+		d.Images[img.ID] = img
+		imageIDs[i] = formatID(img.ID)
+	}
+	return imageIDs
+}
+
+// deletePetImages soft-deletes active images of a pet
+func (d *data) deletePetImages(petID int64, now time.Time) {
+	for _, i := range d.Images {
+		if i.PetID == petID && i.DeletedOn == nil {
+			i.DeletedOn = &now
+		}
+	}
+}
+
+// petImageIDs returns IDs of active images of a pet in the order they were added
+func (d *data) petImageIDs(petID int64) []int64 {
+	ids := make([]int64, 0)
+	for _, i := range d.Images {
+		if i.PetID == petID && i.DeletedOn == nil {
+			ids = append(ids, i.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// petToAPI joins a pet with its category name & IDs of active images
+func (d *data) petToAPI(p *pet) genRouter.PetWithMetadata {
+	status := genRouter.PetStatus(p.Status)
+	imageIDs := d.petImageIDs(p.ID)
+	res := genRouter.PetWithMetadata{
+		Id:       formatID(p.ID),
+		Name:     p.Name,
+		Price:    p.Price,
+		Status:   &status,
+		PhotoIds: make([]string, len(imageIDs)),
+	}
+	if category, ok := d.AnimalCategories[p.CategoryID]; ok {
+		res.Category = category.Name
+	}
+	if len(p.Tags) > 0 {
+		tags := slices.Clone(p.Tags)
+		res.Tags = &tags
+	}
+	for i := range imageIDs {
+		res.PhotoIds[i] = formatID(imageIDs[i])
+	}
+	return res
+}
+
+// parsePrice validates price is a decimal & returns it in the form Mongo backend returns Decimal128
+func parsePrice(price string) (string, error) {
+	res, err := bson.ParseDecimal128(price)
+	if err != nil {
+		return "", &dbErr.HintError{Key: priceField, Err: dbErr.ErrInvalidValue}
+	}
+	return res.String(), nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (m *memoryClient) AddRefreshToken(_ context.Context, userID, tokenHash string,
+	expiresOn time.Time) error {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return dbErr.ErrInvalidValue
+	}
+
+	return m.update(func(d *data) error {
+		now := time.Now().UTC()
+		// Expired tokens are purged, same as TTL index of Mongo backend
+		for hash, t := range d.RefreshTokens {
+			if !t.ExpiresOn.After(now) {
+				delete(d.RefreshTokens, hash)
+			}
+		}
+		if _, ok := d.RefreshTokens[tokenHash]; ok {
+			return dbErr.ErrConflict
+		}
+		d.RefreshTokens[tokenHash] = &refreshToken{
+			UserID:    rowID,
+			FamilyID:  d.nextID(),
+			TokenHash: tokenHash,
+			ExpiresOn: expiresOn.UTC(),
+			CreatedOn: now,
+		}
+		return nil
+	})
+}
+
+// RotateRefreshToken exchanges an active refresh token for a new one in the same family.
+// Presenting an already rotated token is treated as token theft & the whole family is revoked.
+// Returns userID & current role of the token owner
+func (m *memoryClient) RotateRefreshToken(_ context.Context, tokenHash, newTokenHash string,
+	expiresOn time.Time) (string, genRouter.UserRole, error) {
+	var (
+		reused bool
+		u      user
+	)
+	err := m.update(func(d *data) error {
+		token, ok := d.RefreshTokens[tokenHash]
+		if !ok {
+			return dbErr.ErrNotFound
+		}
+
+		now := time.Now().UTC()
+		switch {
+		case token.RevokedOn != nil:
+			return dbErr.ErrNotFound
+		case token.RotatedOn != nil:
+			// Revocation has to be kept, hence no error is returned from update
+			reused = true
+			d.revokeRefreshTokens(func(t *refreshToken) bool { return t.FamilyID == token.FamilyID })
+			return nil
+		case !token.ExpiresOn.After(now):
+			return dbErr.ErrNotFound
+		}
+
+		owner := d.activeUser(token.UserID)
+		if owner == nil {
+			return dbErr.ErrNotFound
+		}
+		if _, ok = d.RefreshTokens[newTokenHash]; ok {
+			return dbErr.ErrConflict
+		}
+
+		token.RotatedOn = &now
+		d.RefreshTokens[newTokenHash] = &refreshToken{
+			UserID:    token.UserID,
+			FamilyID:  token.FamilyID,
+			TokenHash: newTokenHash,
+			ExpiresOn: expiresOn.UTC(),
+			CreatedOn: now,
+		}
+		u = *owner
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if reused {
+		return "", "", &dbErr.HintError{Key: tokenHashField, Err: dbErr.ErrConflict}
+	}
+	return formatID(u.ID), genRouter.UserRole(u.Role), nil
+}
+
+func (m *memoryClient) RevokeRefreshTokenFamily(_ context.Context, tokenHash string) error {
+	return m.update(func(d *data) error {
+		token, ok := d.RefreshTokens[tokenHash]
+		if !ok {
+			return dbErr.ErrNotFound
+		}
+		d.revokeRefreshTokens(func(t *refreshToken) bool { return t.FamilyID == token.FamilyID })
+		return nil
+	})
+}
+
+// revokeRefreshTokens revokes every active token matching fn
+func (d *data) revokeRefreshTokens(fn func(t *refreshToken) bool) {
+	now := time.Now().UTC()
+	for _, t := range d.RefreshTokens {
+		if t.RevokedOn == nil && fn(t) {
+			t.RevokedOn = &now
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (m *memoryClient) AddUser(_ context.Context,
+	userReq *genRouter.CreateUserJSONRequestBody) (*genRouter.UserJSONResponse, error) {
+	role := genRouter.Customer
+	if userReq.Role != nil {
+		role = *userReq.Role
+	}
+	err := m.update(func(d *data) error {
+		// Usernames & phone numbers are unique among active users
+		if d.findUser(func(u *user) bool { return u.Username == userReq.Username }) != nil {
+			return &dbErr.HintError{Key: usernameField, Err: dbErr.ErrConflict}
+		}
+		if d.findUser(func(u *user) bool { return u.PhoneNumber == userReq.PhoneNumber }) != nil {
+			return &dbErr.HintError{Key: phoneNumberField, Err: dbErr.ErrConflict}
+		}
+		id := d.nextID()
+		d.Users[id] = &user{
+			ID:          id,
+			Username:    userReq.Username,
+			Password:    userReq.Password,
+			Address:     userReq.Address,
+			FullName:    userReq.FullName,
+			PhoneNumber: userReq.PhoneNumber,
+			Role:        string(role),
+			CreatedOn:   time.Now().UTC(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &genRouter.UserJSONResponse{
+		Username:    userReq.Username,
+		FullName:    userReq.FullName,
+		PhoneNumber: userReq.PhoneNumber,
+		Address:     userReq.Address,
+		Role:        &role,
+	}, nil
+}
+
+func (m *memoryClient) GetUser(_ context.Context,
+	userID string) (*genRouter.UserJSONResponse, error) {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	var res *genRouter.UserJSONResponse
+	err = m.view(func(d *data) error {
+		u := d.activeUser(rowID)
+		if u == nil {
+			return dbErr.ErrNotFound
+		}
+		res = u.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *memoryClient) DeleteUser(_ context.Context, userID string) error {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return dbErr.ErrInvalidValue
+	}
+
+	return m.update(func(d *data) error {
+		for _, p := range d.Pets {
+			if p.UserID == rowID && p.Status == string(genRouter.Available) && p.DeletedOn == nil {
+				return &dbErr.HintError{Key: petsCollection, Err: dbErr.ErrForeignKeyViolation}
+			}
+		}
+		for _, o := range d.Orders {
+			if o.UserID == rowID && o.Status != string(genRouter.Delivered) &&
+				o.Status != string(genRouter.Cancelled) {
+				return &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
+			}
+		}
+
+		u := d.activeUser(rowID)
+		if u == nil {
+			return dbErr.ErrNotFound
+		}
+		now := time.Now().UTC()
+		u.DeletedOn = &now
+		// Deleted users should not be able to continue any session
+		d.revokeRefreshTokens(func(t *refreshToken) bool { return t.UserID == rowID })
+		return nil
+	})
+}
+
+func (m *memoryClient) PatchUser(_ context.Context, userID string,
+	userReq *genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody) (*genRouter.UserJSONResponse, error) {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	var res *genRouter.UserJSONResponse
+	err = m.update(func(d *data) error {
+		u := d.activeUser(rowID)
+		if u == nil {
+			return dbErr.ErrNotFound
+		}
+		if userReq.PhoneNumber != nil {
+			other := d.findUser(func(o *user) bool { return o.PhoneNumber == *userReq.PhoneNumber })
+			if other != nil && other.ID != rowID {
+				return dbErr.ErrConflict
+			}
+		}
+
+		if userReq.FullName != nil {
+			u.FullName = *userReq.FullName
+		}
+		if userReq.Password != nil {
+			u.Password = *userReq.Password
+			d.revokeRefreshTokens(func(t *refreshToken) bool { return t.UserID == rowID })
+		}
+		if userReq.PhoneNumber != nil {
+			u.PhoneNumber = *userReq.PhoneNumber
+		}
+		if userReq.Address != nil {
+			u.Address = *userReq.Address
+		}
+		now := time.Now().UTC()
+		u.UpdatedOn = &now
+		res = u.toAPI()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetUserCredentials returns userID, hashed password & role of an active user
+func (m *memoryClient) GetUserCredentials(_ context.Context,
+	username string) (string, string, genRouter.UserRole, error) {
+	var u user
+	err := m.view(func(d *data) error {
+		found := d.findUser(func(o *user) bool { return o.Username == username })
+		if found == nil {
+			return dbErr.ErrNotFound
+		}
+		u = *found
+		return nil
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+	return formatID(u.ID), u.Password, genRouter.UserRole(u.Role), nil
+}
+
+// activeUser returns user identified by id unless deleted, nil otherwise
+func (d *data) activeUser(id int64) *user {
+	u, ok := d.Users[id]
+	if !ok || u.DeletedOn != nil {
+		return nil
+	}
+	return u
+}
+
+// findUser returns an active user matching fn, nil when there is none
+func (d *data) findUser(fn func(u *user) bool) *user {
+	for _, u := range d.Users {
+		if u.DeletedOn == nil && fn(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (u *user) toAPI() *genRouter.UserJSONResponse {
+	role := genRouter.UserRole(u.Role)
+	return &genRouter.UserJSONResponse{
+		Username:    u.Username,
+		FullName:    u.FullName,
+		PhoneNumber: u.PhoneNumber,
+		Address:     u.Address,
+		Role:        &role,
+	}
+}
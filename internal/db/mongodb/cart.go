@@ -0,0 +1,255 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/lock"
+)
+
+// AddCartItem holds an available pet for userID until [constants.CartHoldTTL] from now.
+// Adding a pet already held by userID renews the hold
+func (m *mongoClient) AddCartItem(ctx context.Context, userID, petID string) (*genRouter.CartItem, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res, err := m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				res := m.mongoDbHandler.Collection(petsCollection).FindOne(
+					sessCtx,
+					bson.M{iDField: petbsonID, deletedOnField: bson.Null{}},
+					options.FindOne().SetProjection(bson.M{statusField: 1, userIDField: 1}),
+				)
+				errY := res.Err()
+				if errY != nil {
+					if errors.Is(errY, mongo.ErrNoDocuments) {
+						return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+					}
+					return nil, errY
+				}
+				var petInstance pet
+				if errY = res.Decode(&petInstance); errY != nil {
+					return nil, errY
+				}
+				if petInstance.UserID == userbsonID {
+					return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+				}
+				if petInstance.Status != string(genRouter.Available) {
+					return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+				}
+
+				now := time.Now().UTC()
+				count, errY := m.mongoDbHandler.Collection(cartItemsCollection).CountDocuments(
+					sessCtx,
+					bson.M{
+						userIDField:    userbsonID,
+						petIDField:     bson.M{neOperator: petbsonID},
+						heldUntilField: bson.M{gtOperator: now},
+					},
+				)
+				if errY != nil {
+					return nil, errY
+				}
+				if count >= constants.MaxCartItems {
+					return nil, &dbErr.HintError{Key: cartItemsCollection, Err: dbErr.ErrLimitExceeded}
+				}
+
+				// Holds are unique per pet, so taking over an active hold of another user
+				// fails with duplicate key. Expired holds are taken over even if not yet swept
+				item := cartItem{
+					UserID:    userbsonID,
+					PetID:     petbsonID,
+					HeldUntil: now.Add(constants.CartHoldTTL),
+					CreatedOn: now,
+				}
+				_, errY = m.mongoDbHandler.Collection(cartItemsCollection).ReplaceOne(
+					sessCtx,
+					bson.M{
+						petIDField: petbsonID,
+						orOperator: []bson.M{
+							{userIDField: userbsonID},
+							{heldUntilField: bson.M{lteOperator: now}},
+						},
+					},
+					item,
+					options.Replace().SetUpsert(true),
+				)
+				if errY != nil {
+					if mongo.IsDuplicateKeyError(errY) {
+						return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+					}
+					return nil, errY
+				}
+				return &genRouter.CartItem{PetId: petID, HeldUntil: item.HeldUntil}, nil
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	item, _ := res.(*genRouter.CartItem)
+	return item, nil
+}
+
+// FindCartItems returns pets actively held by userID in the order they were added
+func (m *mongoClient) FindCartItems(ctx context.Context, userID string) ([]genRouter.CartItem, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	cursor, err := m.mongoDbHandler.Collection(cartItemsCollection).Find(
+		ctx,
+		bson.M{userIDField: userbsonID, heldUntilField: bson.M{gtOperator: time.Now().UTC()}},
+		options.Find().SetSort(bson.M{createdOnField: 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var items []cartItem
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	res := make([]genRouter.CartItem, len(items))
+	for i := range items {
+		res[i] = genRouter.CartItem{PetId: items[i].PetID.Hex(), HeldUntil: items[i].HeldUntil}
+	}
+	return res, nil
+}
+
+// DeleteCartItem releases hold of userID on a pet
+func (m *mongoClient) DeleteCartItem(ctx context.Context, userID, petID string) error {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res, err := m.mongoDbHandler.Collection(cartItemsCollection).DeleteOne(
+		ctx,
+		bson.M{userIDField: userbsonID, petIDField: petbsonID, heldUntilField: bson.M{gtOperator: time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return dbErr.ErrNotFound
+	}
+	return nil
+}
+
+// CheckoutCart places orders for pets actively held by userID, same as PlaceOrders.
+// Cart is emptied once orders are placed. Returns [dbErr.ErrNotFound] when cart is empty
+func (m *mongoClient) CheckoutCart(ctx context.Context, userID string) ([]genRouter.Order, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res, err := m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				cursor, errY := m.mongoDbHandler.Collection(cartItemsCollection).Find(
+					sessCtx,
+					bson.M{userIDField: userbsonID, heldUntilField: bson.M{gtOperator: time.Now().UTC()}},
+					options.Find().SetSort(bson.M{createdOnField: 1}).SetProjection(bson.M{petIDField: 1}),
+				)
+				if errY != nil {
+					return nil, errY
+				}
+				var items []cartItem
+				if errY = cursor.All(sessCtx, &items); errY != nil {
+					return nil, errY
+				}
+				if len(items) == 0 {
+					return nil, dbErr.ErrNotFound
+				}
+
+				petbsonIDs := make([]bson.ObjectID, len(items))
+				for i := range items {
+					petbsonIDs[i] = items[i].PetID
+				}
+				orders, errY := m.placeOrders(sessCtx, userbsonID, petbsonIDs)
+				if errY != nil {
+					return nil, errY
+				}
+
+				// Expired holds left behind in cart go along
+				_, errY = m.mongoDbHandler.Collection(cartItemsCollection).DeleteMany(
+					sessCtx,
+					bson.M{userIDField: userbsonID},
+				)
+				if errY != nil {
+					return nil, errY
+				}
+				return orders, nil
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderDocs, _ := res.([]order)
+	orders := make([]genRouter.Order, len(orderDocs))
+	for i := range orderDocs {
+		orders[i] = orderDocs[i].toAPI()
+	}
+	return orders, nil
+}
+
+// ReleaseExpiredHolds deletes holds which have expired & returns the count of holds released
+func (m *mongoClient) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	res, err := m.mongoDbHandler.Collection(cartItemsCollection).DeleteMany(
+		ctx,
+		bson.M{heldUntilField: bson.M{lteOperator: time.Now().UTC()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -13,7 +13,10 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 
+	"github.com/vrv501/simple-api/internal/blobstore"
 	"github.com/vrv501/simple-api/internal/constants"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/lock"
 )
 
 const (
@@ -25,32 +28,80 @@ const (
 	defaultMongoPassword string = "mongo"
 	defaultMongoURI      string = "localhost:27017"
 
-	dbName string = "shop"
+	DBName string = "shop"
 
-	leasesCollection string = "leases"
-	lockedUntilField string = "locked_until"
-	retriesForLease         = 50
-	leaseWaitTime           = 500 * time.Millisecond
+	leasesCollection   string = "leases"
+	leaseRenewInterval        = lock.DefaultTTL / 3
+
+	unknownCommitResultLabel string = "UnknownTransactionCommitResult"
 
 	iDField        string = "_id"
 	nameField      string = "name"
+	createdOnField string = "created_on"
 	updatedOnField string = "updated_on"
 	deletedOnField string = "deleted_on"
 
-	setOperator   string = "$set"
-	notInOperator string = "$nin"
-	limitOperator string = "$limit"
+	setOperator         string = "$set"
+	pushOperator        string = "$push"
+	setOnInsertOperator string = "$setOnInsert"
+	notInOperator       string = "$nin"
+	neOperator          string = "$ne"
+	lteOperator         string = "$lte"
+	orOperator          string = "$or"
+	inOperator          string = "$in"
+	gtOperator          string = "$gt"
+	limitOperator       string = "$limit"
+	matchStage          string = "$match"
+	sortStage           string = "$sort"
+	lookupStage         string = "$lookup"
+	projectStage        string = "$project"
+	facetStage          string = "$facet"
+	countStage          string = "$count"
 )
 
 type mongoClient struct {
 	client         *mongo.Client
 	mongoDbHandler *mongo.Database
+	locker         lock.Locker // Leases of a crashed holder can be taken over after [lock.DefaultTTL]
+	blobs          blobstore.Store
 }
 
 // Note: Mongo By default stores date in UTC timezone only
 //
 //revive:disable:unexported-return
 func NewInstance(ctx context.Context) *mongoClient {
+	c := ClientOptionsFromEnv("pet-store-api-server", defaultAPIUser)
+	c.SetTimeout(5 * time.Minute) // Query timeout
+	c.SetReadConcern(readconcern.Majority())
+	c.SetReadPreference(readpref.PrimaryPreferred())
+	c.SetWriteConcern(writeconcern.Majority())
+
+	client, err := mongo.Connect(c)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create mongodb client %v", err))
+	}
+
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to ping db %v", err))
+	}
+
+	mongoDbHandler := client.Database(DBName)
+	blobs, err := blobstore.NewStoreFromEnv(mongoDbHandler)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create blob store %v", err))
+	}
+	return &mongoClient{
+		client:         client,
+		mongoDbHandler: mongoDbHandler,
+		locker:         lock.NewMongo(mongoDbHandler.Collection(leasesCollection), lock.ObjectIDKeys, lock.Options{}),
+		blobs:          blobs,
+	}
+}
+
+// ClientOptionsFromEnv returns options connecting to [DBName] with MONGO_APPLY_URI, or MONGO_URI along with
+// DB_USERNAME & DB_PASSWORD. defaultUsername is used when DB_USERNAME is unset
+func ClientOptionsFromEnv(appName, defaultUsername string) *options.ClientOptions {
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
 	serverAPI.SetStrict(false) // Atlas Search requires apiStrict: false
 
@@ -69,7 +120,7 @@ func NewInstance(ctx context.Context) *mongoClient {
 
 		username := os.Getenv(constants.DBUsername)
 		if username == "" {
-			username = defaultAPIUser
+			username = defaultUsername
 		}
 		pswd := os.Getenv(constants.DBPassword)
 		if pswd == "" {
@@ -77,32 +128,12 @@ func NewInstance(ctx context.Context) *mongoClient {
 		}
 		c.SetAuth(options.Credential{
 			AuthMechanism: "SCRAM-SHA-256",
-			AuthSource:    dbName,
+			AuthSource:    DBName,
 			Username:      username,
 			Password:      pswd,
 		})
 	}
-	c.SetServerAPIOptions(serverAPI)
-	c.SetAppName("pet-store-api-server")
-	c.SetTimeout(5 * time.Minute) // Query timeout
-	c.SetReadConcern(readconcern.Majority())
-	c.SetReadPreference(readpref.PrimaryPreferred())
-	c.SetWriteConcern(writeconcern.Majority())
-
-	client, err := mongo.Connect(c.SetServerAPIOptions(serverAPI))
-	if err != nil {
-		panic(fmt.Sprintf("Failed to create mongodb client %v", err))
-	}
-
-	err = client.Ping(ctx, nil)
-	if err != nil {
-		panic(fmt.Sprintf("failed to ping db %v", err))
-	}
-
-	return &mongoClient{
-		client:         client,
-		mongoDbHandler: client.Database(dbName),
-	}
+	return c.SetServerAPIOptions(serverAPI).SetAppName(appName)
 }
 
 func (m *mongoClient) Close(ctx context.Context) error {
@@ -130,29 +161,45 @@ type pet struct {
 	Tags       []string        `bson:"tags"`        // "bsonType": ["array", "null"]
 	CreatedOn  time.Time       `bson:"created_on"`  // "bsonType": "date"
 	UpdatedOn  *time.Time      `bson:"updated_on"`  // "bsonType": ["date", "null"]
+	DeletedOn  *time.Time      `bson:"deleted_on"`  // "bsonType": ["date", "null"]
 }
 
 const (
 	petsCollection string = "pets"
 
-	statusField string = "status"
-	priceField  string = "price"
-	userIDField string = "user_id"
+	statusField     string = "status"
+	priceField      string = "price"
+	userIDField     string = "user_id"
+	tagsField       string = "tags"
+	categoryIDField string = "category_id"
 )
 
+// petWithMetadata is a pet joined with its category & active images
+type petWithMetadata struct {
+	pet      `bson:",inline"`
+	Category []animalCategory `bson:"category"`
+	Photos   []image          `bson:"photos"`
+}
+
 type image struct {
 	ID        bson.ObjectID `bson:"_id,omitempty"`
 	PetID     bson.ObjectID `bson:"pet_id"`     // "bsonType": "objectId"
 	UserID    bson.ObjectID `bson:"user_id"`    // "bsonType": "objectId"
-	Image     []byte        `bson:"image"`      // "bsonType": "binData"
+	BlobKey   string        `bson:"blob_key"`   // "bsonType": "string"
+	Size      int64         `bson:"size"`       // "bsonType": "long"
 	DeletedOn *time.Time    `bson:"deleted_on"` // "bsonType": ["date", "null"]
+
+	// Blob keys of scaled down renditions, "bsonType": "object". A rendition is missing when it would be the
+	// original itself, as well as on images added before renditions
+	Renditions map[genRouter.ImageSize]string `bson:"renditions,omitempty"`
 }
 
 const (
 	imagesCollection string = "images"
 
-	petIDField string = "pet_id"
-	imageField string = "image"
+	petIDField      string = "pet_id"
+	blobKeyField    string = "blob_key"
+	renditionsField string = "renditions"
 )
 
 type user struct {
@@ -162,6 +209,7 @@ type user struct {
 	Password    string        `bson:"password"`     // "bsonType": "string"
 	Address     string        `bson:"address"`      // "bsonType": "string"
 	PhoneNumber string        `bson:"phone_number"` // "bsonType": "string"
+	Role        string        `bson:"role"`         // "enum": ["customer", "seller", "admin"]
 	CreatedOn   time.Time     `bson:"created_on"`   // "bsonType": "date"
 	UpdatedOn   *time.Time    `bson:"updated_on"`   // "bsonType": ["date", "null"]
 	DeletedOn   *time.Time    `bson:"deleted_on"`   // "bsonType": ["date", "null"]
@@ -175,19 +223,96 @@ const (
 	fullNameField    string = "full_name"
 	phoneNumberField string = "phone_number"
 	addressField     string = "address"
+	roleField        string = "role"
 )
 
-type order struct { //nolint:unused //TODO: remove me
-	ID            bson.ObjectID `bson:"_id,omitempty"`
-	UserID        bson.ObjectID `bson:"user_id"`        // "bsonType": "objectId"
-	PetID         bson.ObjectID `bson:"pet_id"`         // "bsonType": "objectId"
-	ShippedDate   *time.Time    `bson:"shipped_date"`   // "bsonType": ["date", "null"]
-	DeliveredDate *time.Time    `bson:"delivered_date"` // "bsonType": ["date", "null"]
-	Status        string        `bson:"status"`         // "bsonType": "string"
-	CreatedOn     time.Time     `bson:"created_on"`     // "bsonType": "date"
-	UpdatedOn     *time.Time    `bson:"updated_on"`     // "bsonType": ["date", "null"]
+type order struct {
+	ID             bson.ObjectID   `bson:"_id,omitempty"`
+	UserID         bson.ObjectID   `bson:"user_id"`         // "bsonType": "objectId"
+	PetID          bson.ObjectID   `bson:"pet_id"`          // "bsonType": "objectId"
+	SellerID       bson.ObjectID   `bson:"seller_id"`       // "bsonType": "objectId"
+	Price          bson.Decimal128 `bson:"price"`           // "bsonType": "decimal"
+	ShippedDate    *time.Time      `bson:"shipped_date"`    // "bsonType": ["date", "null"]
+	DeliveredDate  *time.Time      `bson:"delivered_date"`  // "bsonType": ["date", "null"]
+	TrackingNumber *string         `bson:"tracking_number"` // "bsonType": ["string", "null"]
+	Carrier        *string         `bson:"carrier"`         // "bsonType": ["string", "null"]
+	Status         string          `bson:"status"`          // "bsonType": "string"
+	StatusHistory  []statusEntry   `bson:"status_history"`  // "bsonType": "array"
+	CreatedOn      time.Time       `bson:"created_on"`      // "bsonType": "date"
+	UpdatedOn      *time.Time      `bson:"updated_on"`      // "bsonType": ["date", "null"]
+}
+
+// statusEntry records an order reaching a status. Entries are only ever appended
+type statusEntry struct {
+	Status    string    `bson:"status"`     // "bsonType": "string"
+	ChangedOn time.Time `bson:"changed_on"` // "bsonType": "date"
 }
 
 const (
 	ordersCollection string = "orders"
+
+	sellerIDField       string = "seller_id"
+	shippedDateField    string = "shipped_date"
+	deliveredDateField  string = "delivered_date"
+	trackingNumberField string = "tracking_number"
+	carrierField        string = "carrier"
+	statusHistoryField  string = "status_history"
+)
+
+// paymentIntent tracks payment of an order with a payment provider. An order has atmost one intent
+type paymentIntent struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	OrderID   bson.ObjectID `bson:"order_id"`   // "bsonType": "objectId"
+	Provider  string        `bson:"provider"`   // "bsonType": "string"
+	IntentID  string        `bson:"intent_id"`  // "bsonType": "string"
+	Status    string        `bson:"status"`     // "bsonType": "string"
+	Amount    int64         `bson:"amount"`     // "bsonType": "long"
+	Currency  string        `bson:"currency"`   // "bsonType": "string"
+	CreatedOn time.Time     `bson:"created_on"` // "bsonType": "date"
+	UpdatedOn *time.Time    `bson:"updated_on"` // "bsonType": ["date", "null"]
+}
+
+const (
+	paymentIntentsCollection string = "payment_intents"
+
+	orderIDField  string = "order_id"
+	providerField string = "provider"
+	intentIDField string = "intent_id"
+	amountField   string = "amount"
+	currencyField string = "currency"
+)
+
+// cartItem is a time limited hold of a user on a pet. A pet is held by atmost one user
+type cartItem struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`    // "bsonType": "objectId"
+	PetID     bson.ObjectID `bson:"pet_id"`     // "bsonType": "objectId"
+	HeldUntil time.Time     `bson:"held_until"` // "bsonType": "date"
+	CreatedOn time.Time     `bson:"created_on"` // "bsonType": "date"
+}
+
+const (
+	cartItemsCollection string = "cart_items"
+
+	heldUntilField string = "held_until"
+)
+
+type refreshToken struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	UserID    bson.ObjectID `bson:"user_id"`    // "bsonType": "objectId"
+	FamilyID  bson.ObjectID `bson:"family_id"`  // "bsonType": "objectId"
+	TokenHash string        `bson:"token_hash"` // "bsonType": "string"
+	ExpiresOn time.Time     `bson:"expires_on"` // "bsonType": "date"
+	CreatedOn time.Time     `bson:"created_on"` // "bsonType": "date"
+	RotatedOn *time.Time    `bson:"rotated_on"` // "bsonType": ["date", "null"]
+	RevokedOn *time.Time    `bson:"revoked_on"` // "bsonType": ["date", "null"]
+}
+
+const (
+	refreshTokensCollection string = "refresh_tokens"
+
+	familyIDField  string = "family_id"
+	tokenHashField string = "token_hash"
+	rotatedOnField string = "rotated_on"
+	revokedOnField string = "revoked_on"
 )
@@ -0,0 +1,427 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/lock"
+	"github.com/vrv501/simple-api/internal/orderstate"
+)
+
+// PlaceOrders places an order for each of petIDs on behalf of userID & marks those pets sold.
+// Either all orders are placed or none, pets must exist, be available & not be owned by userID
+func (m *mongoClient) PlaceOrders(ctx context.Context, userID string, petIDs []string) ([]genRouter.Order, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonIDs := make([]bson.ObjectID, len(petIDs))
+	for i := range petIDs {
+		petbsonIDs[i], err = bson.ObjectIDFromHex(petIDs[i])
+		if err != nil {
+			return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+		}
+	}
+
+	res, err := m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				return m.placeOrders(sessCtx, userbsonID, petbsonIDs)
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderDocs, _ := res.([]order)
+	orders := make([]genRouter.Order, len(orderDocs))
+	for i := range orderDocs {
+		orders[i] = orderDocs[i].toAPI()
+	}
+	return orders, nil
+}
+
+// placeOrders is PlaceOrders within a transaction. Pets held in cart of other users cannot be ordered,
+// while holds of userID on ordered pets are released
+func (m *mongoClient) placeOrders(sessCtx context.Context, userbsonID bson.ObjectID,
+	petbsonIDs []bson.ObjectID) ([]order, error) {
+	petsFilter := bson.M{iDField: bson.M{inOperator: petbsonIDs}, deletedOnField: bson.Null{}}
+	cursor, err := m.mongoDbHandler.Collection(petsCollection).Find(
+		sessCtx,
+		petsFilter,
+		options.Find().SetProjection(bson.M{statusField: 1, userIDField: 1, priceField: 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var pets []pet
+	if err = cursor.All(sessCtx, &pets); err != nil {
+		return nil, err
+	}
+	if len(pets) != len(petbsonIDs) {
+		return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+	}
+	petsByID := make(map[bson.ObjectID]*pet, len(pets))
+	for i := range pets {
+		if pets[i].UserID == userbsonID {
+			return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+		}
+		petsByID[pets[i].ID] = &pets[i]
+	}
+
+	now := time.Now().UTC()
+	err = m.mongoDbHandler.Collection(cartItemsCollection).FindOne(
+		sessCtx,
+		bson.M{
+			petIDField:     bson.M{inOperator: petbsonIDs},
+			userIDField:    bson.M{neOperator: userbsonID},
+			heldUntilField: bson.M{gtOperator: now},
+		},
+		options.FindOne().SetProjection(bson.M{iDField: 1}),
+	).Err()
+	if err == nil {
+		return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	// Buyers are locked individually, hence two of them can race for the same pet.
+	// Only pets still available are flipped & the loser's transaction either sees
+	// fewer pets modified or aborts with a write conflict & retries to see the same
+	petsFilter[statusField] = string(genRouter.Available)
+	updateRes, err := m.mongoDbHandler.Collection(petsCollection).UpdateMany(
+		sessCtx,
+		petsFilter,
+		bson.M{setOperator: bson.M{statusField: string(genRouter.Sold), updatedOnField: now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if updateRes.ModifiedCount != int64(len(petbsonIDs)) {
+		return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+	}
+
+	// Price is fixed when ordered, pets may be repriced afterwards
+	orders := make([]order, len(petbsonIDs))
+	for i := range petbsonIDs {
+		orders[i] = order{
+			ID:       bson.NewObjectID(),
+			UserID:   userbsonID,
+			PetID:    petbsonIDs[i],
+			SellerID: petsByID[petbsonIDs[i]].UserID,
+			Price:    petsByID[petbsonIDs[i]].Price,
+			Status:   string(genRouter.Placed),
+			StatusHistory: []statusEntry{
+				{Status: string(genRouter.Placed), ChangedOn: now},
+			},
+			CreatedOn: now,
+		}
+	}
+	_, err = m.mongoDbHandler.Collection(ordersCollection).InsertMany(sessCtx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = m.mongoDbHandler.Collection(cartItemsCollection).DeleteMany(
+		sessCtx,
+		bson.M{petIDField: bson.M{inOperator: petbsonIDs}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindOrders returns a page of orders of userID matching params in increasing order of _id, starting after afterID.
+// Returns orders along with total count of matching orders & ID to resume from, empty when on last page
+func (m *mongoClient) FindOrders(ctx context.Context, userID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	return m.findOrders(ctx, userIDField, userID, params, afterID, limit)
+}
+
+// FindSellerOrders is same as FindOrders but for orders placed against pets of sellerID
+func (m *mongoClient) FindSellerOrders(ctx context.Context, sellerID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	return m.findOrders(ctx, sellerIDField, sellerID, params, afterID, limit)
+}
+
+// findOrders pages through orders whose userField is userID
+func (m *mongoClient) findOrders(ctx context.Context, userField, userID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, 0, "", &dbErr.HintError{Key: userField, Err: dbErr.ErrInvalidValue}
+	}
+	filter := bson.M{userField: userbsonID}
+	if params.Status != nil {
+		filter[statusField] = bson.M{inOperator: *params.Status}
+	}
+	if params.AfterDate != nil {
+		filter[createdOnField] = bson.M{gtOperator: params.AfterDate.UTC()}
+	}
+
+	// Keyset pagination over _id
+	pageStages := make([]bson.M, 0, 3)
+	if afterID != "" {
+		bsonAfterID, errS := bson.ObjectIDFromHex(afterID)
+		if errS != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
+		}
+		pageStages = append(pageStages, bson.M{matchStage: bson.M{iDField: bson.M{gtOperator: bsonAfterID}}})
+	}
+	pageStages = append(pageStages,
+		bson.M{sortStage: bson.M{iDField: 1}},
+		// One extra order tells whether another page exists
+		bson.M{limitOperator: limit + 1},
+	)
+
+	pipeline := []bson.M{
+		{matchStage: filter},
+		{
+			facetStage: bson.M{
+				"total":  []bson.M{{countStage: "count"}},
+				"orders": pageStages,
+			},
+		},
+	}
+	cursor, err := m.mongoDbHandler.Collection(ordersCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var res []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Orders []order `bson:"orders"`
+	}
+	if err = cursor.All(ctx, &res); err != nil {
+		return nil, 0, "", err
+	}
+	if len(res) == 0 || len(res[0].Total) == 0 {
+		return []genRouter.Order{}, 0, "", nil
+	}
+
+	orderDocs, nextID := res[0].Orders, ""
+	if len(orderDocs) > limit {
+		orderDocs = orderDocs[:limit]
+		nextID = orderDocs[limit-1].ID.Hex()
+	}
+	orders := make([]genRouter.Order, len(orderDocs))
+	for i := range orderDocs {
+		orders[i] = orderDocs[i].toAPI()
+	}
+	return orders, res[0].Total[0].Count, nextID, nil
+}
+
+func (m *mongoClient) GetOrder(ctx context.Context, orderID string) (*genRouter.Order, error) {
+	bsonOrderID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	res := m.mongoDbHandler.Collection(ordersCollection).FindOne(ctx, bson.M{iDField: bsonOrderID})
+	err = res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	var orderDoc order
+	if err = res.Decode(&orderDoc); err != nil {
+		return nil, err
+	}
+	o := orderDoc.toAPI()
+	return &o, nil
+}
+
+// DeleteOrder cancels an order of userID & makes its pet available again. Orders are kept
+// for bookkeeping, hence only status is changed as per [orderstate]
+func (m *mongoClient) DeleteOrder(ctx context.Context, userID, orderID string) error {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderbsonID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				orderDoc, errY := m.transitionOrder(sessCtx,
+					bson.M{iDField: orderbsonID, userIDField: userbsonID}, genRouter.Cancelled, nil)
+				if errY != nil {
+					return nil, errY
+				}
+				_, errY = m.mongoDbHandler.Collection(petsCollection).UpdateOne(
+					sessCtx,
+					bson.M{iDField: orderDoc.PetID, statusField: string(genRouter.Sold)},
+					bson.M{setOperator: bson.M{
+						statusField:    string(genRouter.Available),
+						updatedOnField: time.Now().UTC(),
+					}},
+				)
+				return nil, errY
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	return err
+}
+
+// UpdateOrderStatus moves an order placed against a pet of sellerID forward as per [orderstate].
+// Tracking details are recorded along with the status when given
+func (m *mongoClient) UpdateOrderStatus(ctx context.Context, sellerID, orderID string,
+	statusReq *genRouter.UpdateOrderStatusJSONRequestBody) (*genRouter.Order, error) {
+	sellerbsonID, err := bson.ObjectIDFromHex(sellerID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: sellerIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderbsonID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	tracking := bson.M{}
+	if statusReq.TrackingNumber != nil {
+		tracking[trackingNumberField] = *statusReq.TrackingNumber
+	}
+	if statusReq.Carrier != nil {
+		tracking[carrierField] = *statusReq.Carrier
+	}
+
+	res, err := m.performAdvisoryLockDBOperation(ctx, sellerbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				return m.transitionOrder(sessCtx,
+					bson.M{iDField: orderbsonID, sellerIDField: sellerbsonID},
+					genRouter.OrderStatus(statusReq.Status), tracking)
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	orderDoc, _ := res.(*order)
+	o := orderDoc.toAPI()
+	return &o, nil
+}
+
+// transitionOrder moves the order matching filter to status, sets fields in extra
+// & records it in status_history. Must be called within a transaction. Returns the updated order
+func (m *mongoClient) transitionOrder(sessCtx context.Context, filter bson.M,
+	status genRouter.OrderStatus, extra bson.M) (*order, error) {
+	res := m.mongoDbHandler.Collection(ordersCollection).FindOne(
+		sessCtx,
+		filter,
+		options.FindOne().SetProjection(bson.M{statusField: 1}),
+	)
+	err := res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	var orderDoc order
+	if err = res.Decode(&orderDoc); err != nil {
+		return nil, err
+	}
+
+	change, err := orderstate.Transition(genRouter.OrderStatus(orderDoc.Status), status, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{
+		statusField:    string(change.Status),
+		updatedOnField: change.ChangedOn,
+	}
+	for field, value := range extra {
+		set[field] = value
+	}
+	if change.ShippedDate != nil {
+		set[shippedDateField] = *change.ShippedDate
+	}
+	if change.DeliveredDate != nil {
+		set[deliveredDateField] = *change.DeliveredDate
+	}
+	// A concurrent transition of the same order aborts the transaction with a write conflict,
+	// which is retried & hence re-validated against the newer status
+	res = m.mongoDbHandler.Collection(ordersCollection).FindOneAndUpdate(
+		sessCtx,
+		bson.M{iDField: orderDoc.ID},
+		bson.M{
+			setOperator: set,
+			pushOperator: bson.M{statusHistoryField: statusEntry{
+				Status:    string(change.Status),
+				ChangedOn: change.ChangedOn,
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+	if err = res.Decode(&orderDoc); err != nil {
+		return nil, err
+	}
+	return &orderDoc, nil
+}
+
+func (o *order) toAPI() genRouter.Order {
+	return genRouter.Order{
+		Id:             o.ID.Hex(),
+		PetId:          o.PetID.Hex(),
+		Price:          o.Price.String(),
+		Status:         genRouter.OrderStatus(o.Status),
+		ShippedDate:    o.ShippedDate,
+		DeliveredDate:  o.DeliveredDate,
+		TrackingNumber: o.TrackingNumber,
+		Carrier:        o.Carrier,
+	}
+}
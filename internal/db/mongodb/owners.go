@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+)
+
+func (m *mongoClient) GetPetOwner(ctx context.Context, petID string) (string, error) {
+	return m.findOwner(ctx, petsCollection, petID, bson.M{deletedOnField: bson.Null{}})
+}
+
+func (m *mongoClient) GetImageOwner(ctx context.Context, imageID string) (string, error) {
+	return m.findOwner(ctx, imagesCollection, imageID, bson.M{deletedOnField: bson.Null{}})
+}
+
+func (m *mongoClient) GetOrderOwner(ctx context.Context, orderID string) (string, error) {
+	return m.findOwner(ctx, ordersCollection, orderID, bson.M{})
+}
+
+// GetOrderSeller returns userID owning the pet an order was placed for
+func (m *mongoClient) GetOrderSeller(ctx context.Context, orderID string) (string, error) {
+	bsonID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return "", &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res := m.mongoDbHandler.Collection(ordersCollection).FindOne(
+		ctx,
+		bson.M{iDField: bsonID},
+		options.FindOne().SetProjection(bson.M{sellerIDField: 1}),
+	)
+	err = res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", dbErr.ErrNotFound
+		}
+		return "", err
+	}
+	var orderDoc order
+	err = res.Decode(&orderDoc)
+	if err != nil {
+		return "", err
+	}
+	return orderDoc.SellerID.Hex(), nil
+}
+
+// findOwner returns user_id of the document identified by id in collection.
+// filter narrows down documents considered to be existing
+func (m *mongoClient) findOwner(ctx context.Context, collection, id string, filter bson.M) (string, error) {
+	bsonID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return "", &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	filter[iDField] = bsonID
+	res := m.mongoDbHandler.Collection(collection).FindOne(
+		ctx,
+		filter,
+		options.FindOne().SetProjection(bson.M{userIDField: 1}),
+	)
+	err = res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", dbErr.ErrNotFound
+		}
+		return "", err
+	}
+	var owner struct {
+		UserID bson.ObjectID `bson:"user_id"`
+	}
+	err = res.Decode(&owner)
+	if err != nil {
+		return "", err
+	}
+	return owner.UserID.Hex(), nil
+}
@@ -0,0 +1,68 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+// SavePaymentIntent records latest state of the payment intent of an order, replacing an earlier one
+func (m *mongoClient) SavePaymentIntent(ctx context.Context, orderID string, intent *payments.Intent) error {
+	orderbsonID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	now := time.Now().UTC()
+	_, err = m.mongoDbHandler.Collection(paymentIntentsCollection).UpdateOne(
+		ctx,
+		bson.M{orderIDField: orderbsonID},
+		bson.M{
+			setOperator: bson.M{
+				providerField:  intent.Provider,
+				intentIDField:  intent.ID,
+				statusField:    string(intent.Status),
+				amountField:    intent.Amount,
+				currencyField:  intent.Currency,
+				updatedOnField: now,
+			},
+			setOnInsertOperator: bson.M{createdOnField: now},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// GetPaymentIntent returns payment intent of an order, [dbErr.ErrNotFound] when order has none
+func (m *mongoClient) GetPaymentIntent(ctx context.Context, orderID string) (*payments.Intent, error) {
+	orderbsonID, err := bson.ObjectIDFromHex(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var res paymentIntent
+	err = m.mongoDbHandler.Collection(paymentIntentsCollection).FindOne(
+		ctx,
+		bson.M{orderIDField: orderbsonID},
+	).Decode(&res)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &payments.Intent{
+		ID:       res.IntentID,
+		Provider: res.Provider,
+		Status:   payments.Status(res.Status),
+		Amount:   res.Amount,
+		Currency: res.Currency,
+	}, nil
+}
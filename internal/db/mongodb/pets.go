@@ -7,50 +7,44 @@ import (
 	"io"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 
+	"github.com/vrv501/simple-api/internal/blobstore"
+	"github.com/vrv501/simple-api/internal/constants"
 	dbErr "github.com/vrv501/simple-api/internal/db/errors"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/lock"
+	"github.com/vrv501/simple-api/internal/rendition"
 )
 
 func (m *mongoClient) AddPet(ctx context.Context, userID string,
-	petReq *genRouter.AddPetMultipartBody) error {
+	petReq *genRouter.Pet, photos []rendition.Set) error {
 	// Validate userID string
 	userbsonID, err := bson.ObjectIDFromHex(userID)
 	if err != nil {
 		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
 	}
 	// Validate price string
-	price, err := bson.ParseDecimal128(petReq.Pet.Price)
+	price, err := bson.ParseDecimal128(petReq.Price)
 	if err != nil {
 		return &dbErr.HintError{Key: priceField, Err: dbErr.ErrInvalidValue}
 	}
 
-	// Validate animalCategory exists
-	res := m.mongoDbHandler.Collection(animalCategoryCollection).
-		FindOne(
-			ctx,
-			bson.M{nameField: petReq.Pet.Category},
-			options.FindOne().SetProjection(bson.M{iDField: 1}),
-		)
-	err = res.Err()
+	categoryID, err := m.findAnimalCategoryID(ctx, petReq.Category)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return &dbErr.HintError{Key: animalCategoryCollection, Err: dbErr.ErrNotFound}
-		}
 		return err
 	}
-	var animalCategoryDetail animalCategory
-	err = res.Decode(&animalCategoryDetail)
+	petID := bson.NewObjectID()
+	imageList, err := m.putPetImages(ctx, userbsonID, petID, photos)
 	if err != nil {
 		return err
 	}
 
-	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context) (any, error) {
-		petID := bson.NewObjectID()
+	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
 		session, errS := m.client.StartSession()
 		if errS != nil {
 			return nil, errS
@@ -74,18 +68,21 @@ func (m *mongoClient) AddPet(ctx context.Context, userID string,
 		_, errS = session.WithTransaction(
 			aCtx,
 			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
 				// Insert new pet record
 				petInstance := pet{
 					ID:         petID,
-					Name:       petReq.Pet.Name,
-					CategoryID: animalCategoryDetail.ID,
+					Name:       petReq.Name,
+					CategoryID: categoryID,
 					UserID:     userbsonID,
 					Price:      price,
 					Status:     string(genRouter.Available),
 					CreatedOn:  time.Now().UTC(),
 				}
-				if petReq.Pet.Tags != nil {
-					petInstance.Tags = *petReq.Pet.Tags
+				if petReq.Tags != nil {
+					petInstance.Tags = *petReq.Tags
 				}
 				_, errY := m.mongoDbHandler.Collection(petsCollection).
 					InsertOne(sessCtx, petInstance)
@@ -96,19 +93,123 @@ func (m *mongoClient) AddPet(ctx context.Context, userID string,
 					return nil, errY
 				}
 
-				if len(petReq.Photos) == 0 {
+				if len(imageList) == 0 {
 					return nil, nil
 				}
-				// Upload photos
-				imageList := make([]image, len(petReq.Photos))
-				for i := range petReq.Photos {
-					imgBytes, _ := petReq.Photos[i].Bytes()
-					imageList[i].UserID = userbsonID
-					imageList[i].PetID = petID
-					imageList[i].Image = imgBytes
+				_, errY = m.mongoDbHandler.Collection(imagesCollection).InsertMany(sessCtx,
+					imageList, options.InsertMany().SetOrdered(false))
+				return nil, errY
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+		return nil, errS
+	})
+	if err != nil {
+		m.deleteBlobs(ctx, imageList, err)
+	}
+	return err
+}
+
+// ReplacePet replaces details & photos of a pet owned by userID. Previous photos are soft-deleted.
+// Sold pets cannot be replaced
+func (m *mongoClient) ReplacePet(ctx context.Context, userID, petID string,
+	petReq *genRouter.Pet, photos []rendition.Set) error {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	price, err := bson.ParseDecimal128(petReq.Price)
+	if err != nil {
+		return &dbErr.HintError{Key: priceField, Err: dbErr.ErrInvalidValue}
+	}
+	categoryID, err := m.findAnimalCategoryID(ctx, petReq.Category)
+	if err != nil {
+		return err
+	}
+	status := genRouter.Available
+	if petReq.Status != nil {
+		status = *petReq.Status
+	}
+	var tags []string
+	if petReq.Tags != nil {
+		tags = *petReq.Tags
+	}
+
+	imageList, err := m.putPetImages(ctx, userbsonID, petbsonID, photos)
+	if err != nil {
+		return err
+	}
+
+	// Lock is taken on the owner, same as AddPet, so that pets of a user change one at a time
+	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		_, errS = session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				petFilter := bson.M{iDField: petbsonID, userIDField: userbsonID, deletedOnField: bson.Null{}}
+				res := m.mongoDbHandler.Collection(petsCollection).FindOne(
+					sessCtx,
+					petFilter,
+					options.FindOne().SetProjection(bson.M{statusField: 1}),
+				)
+				errY := res.Err()
+				if errY != nil {
+					if errors.Is(errY, mongo.ErrNoDocuments) {
+						return nil, dbErr.ErrNotFound
+					}
+					return nil, errY
+				}
+				var petInstance pet
+				if errY = res.Decode(&petInstance); errY != nil {
+					return nil, errY
 				}
-				_, errY = m.mongoDbHandler.Collection(imagesCollection).
-					InsertMany(sessCtx, imageList, options.InsertMany().SetOrdered(false))
+				if petInstance.Status == string(genRouter.Sold) {
+					return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+				}
+
+				now := time.Now().UTC()
+				_, errY = m.mongoDbHandler.Collection(petsCollection).UpdateOne(
+					sessCtx,
+					petFilter,
+					bson.M{setOperator: bson.M{
+						nameField:       petReq.Name,
+						categoryIDField: categoryID,
+						priceField:      price,
+						statusField:     string(status),
+						tagsField:       tags,
+						updatedOnField:  now,
+					}},
+				)
+				if errY != nil {
+					if mongo.IsDuplicateKeyError(errY) {
+						return nil, dbErr.ErrConflict
+					}
+					return nil, errY
+				}
+
+				_, errY = m.mongoDbHandler.Collection(imagesCollection).UpdateMany(
+					sessCtx,
+					bson.M{petIDField: petbsonID, deletedOnField: bson.Null{}},
+					bson.M{setOperator: bson.M{deletedOnField: now}},
+				)
+				if errY != nil || len(imageList) == 0 {
+					return nil, errY
+				}
+				_, errY = m.mongoDbHandler.Collection(imagesCollection).InsertMany(sessCtx,
+					imageList, options.InsertMany().SetOrdered(false))
 				return nil, errY
 			},
 			// Transactions apparently require read preference to be primary
@@ -116,10 +217,254 @@ func (m *mongoClient) AddPet(ctx context.Context, userID string,
 		)
 		return nil, errS
 	})
+	if err != nil {
+		m.deleteBlobs(ctx, imageList, err)
+	}
 	return err
 }
 
-func (m *mongoClient) GetPetImage(ctx context.Context, imageID string) (io.Reader, int64, error) {
+// DeletePet soft-deletes a pet owned by userID along with its images.
+// Pets with orders that are neither delivered nor cancelled cannot be deleted
+func (m *mongoClient) DeletePet(ctx context.Context, userID, petID string) error {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				errY := m.mongoDbHandler.Collection(ordersCollection).FindOne(
+					sessCtx,
+					bson.M{
+						petIDField: petbsonID,
+						statusField: bson.M{
+							notInOperator: []string{
+								string(genRouter.Delivered),
+								string(genRouter.Cancelled),
+							},
+						},
+					},
+					options.FindOne().SetProjection(bson.M{iDField: 1}),
+				).Err()
+				if errY != nil && !errors.Is(errY, mongo.ErrNoDocuments) {
+					return nil, errY
+				}
+				if errY == nil {
+					return nil, &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
+				}
+
+				now := time.Now().UTC()
+				res, errY := m.mongoDbHandler.Collection(petsCollection).UpdateOne(
+					sessCtx,
+					bson.M{iDField: petbsonID, userIDField: userbsonID, deletedOnField: bson.Null{}},
+					bson.M{setOperator: bson.M{deletedOnField: now}},
+				)
+				if errY != nil {
+					return nil, errY
+				}
+				if res.MatchedCount == 0 {
+					return nil, dbErr.ErrNotFound
+				}
+
+				_, errY = m.mongoDbHandler.Collection(imagesCollection).UpdateMany(
+					sessCtx,
+					bson.M{petIDField: petbsonID, deletedOnField: bson.Null{}},
+					bson.M{setOperator: bson.M{deletedOnField: now}},
+				)
+				return nil, errY
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	return err
+}
+
+// AddPetImages appends photos to a pet owned by userID & returns IDs of the new images.
+// Count of active images of a pet never exceeds [constants.MaxPetImages]
+func (m *mongoClient) AddPetImages(ctx context.Context, userID, petID string,
+	photos []rendition.Set) ([]string, error) {
+	userbsonID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petbsonID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	imageList, err := m.putPetImages(ctx, userbsonID, petbsonID, photos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every change to images of a pet is made holding lock on its owner,
+	// hence concurrent uploads cannot together go beyond the limit
+	_, err = m.performAdvisoryLockDBOperation(ctx, userbsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
+		}
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				errY := m.mongoDbHandler.Collection(petsCollection).FindOne(
+					sessCtx,
+					bson.M{iDField: petbsonID, userIDField: userbsonID, deletedOnField: bson.Null{}},
+					options.FindOne().SetProjection(bson.M{iDField: 1}),
+				).Err()
+				if errY != nil {
+					if errors.Is(errY, mongo.ErrNoDocuments) {
+						return nil, dbErr.ErrNotFound
+					}
+					return nil, errY
+				}
+
+				count, errY := m.mongoDbHandler.Collection(imagesCollection).CountDocuments(
+					sessCtx,
+					bson.M{petIDField: petbsonID, deletedOnField: bson.Null{}},
+				)
+				if errY != nil {
+					return nil, errY
+				}
+				if int(count)+len(photos) > constants.MaxPetImages {
+					return nil, &dbErr.HintError{Key: imagesCollection, Err: dbErr.ErrLimitExceeded}
+				}
+
+				_, errY = m.mongoDbHandler.Collection(imagesCollection).InsertMany(sessCtx, imageList)
+				return nil, errY
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
+	})
+	if err != nil {
+		m.deleteBlobs(ctx, imageList, err)
+		return nil, err
+	}
+	imageIDs := make([]string, len(imageList))
+	for i := range imageList {
+		imageIDs[i] = imageList[i].ID.Hex()
+	}
+	return imageIDs, nil
+}
+
+// findAnimalCategoryID validates animal category exists & returns its ID
+func (m *mongoClient) findAnimalCategoryID(ctx context.Context, name string) (bson.ObjectID, error) {
+	res := m.mongoDbHandler.Collection(animalCategoryCollection).
+		FindOne(
+			ctx,
+			bson.M{nameField: name},
+			options.FindOne().SetProjection(bson.M{iDField: 1}),
+		)
+	err := res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return bson.ObjectID{}, &dbErr.HintError{Key: animalCategoryCollection, Err: dbErr.ErrNotFound}
+		}
+		return bson.ObjectID{}, err
+	}
+	var animalCategoryDetail animalCategory
+	err = res.Decode(&animalCategoryDetail)
+	if err != nil {
+		return bson.ObjectID{}, err
+	}
+	return animalCategoryDetail.ID, nil
+}
+
+// putPetImages stores photos along with their renditions in blob store & returns image documents referring
+// to them. Blobs are stored before documents are inserted, since a transaction should not wait on blob store
+func (m *mongoClient) putPetImages(ctx context.Context, userID, petID bson.ObjectID,
+	photos []rendition.Set) ([]image, error) {
+	imageList := make([]image, len(photos))
+	for i := range photos {
+		original := photos[i][genRouter.Original]
+		imageID := bson.NewObjectID()
+		imageList[i] = image{
+			ID:         imageID,
+			PetID:      petID,
+			UserID:     userID,
+			BlobKey:    imageID.Hex(),
+			Size:       int64(len(original)),
+			Renditions: map[genRouter.ImageSize]string{},
+		}
+		if err := m.putRenditions(ctx, &imageList[i], photos[i]); err != nil {
+			m.deleteBlobs(ctx, imageList[:i+1], err)
+			return nil, err
+		}
+	}
+	return imageList, nil
+}
+
+// putRenditions stores original & scaled down renditions of img, recording keys of renditions stored so far
+func (m *mongoClient) putRenditions(ctx context.Context, img *image, photo rendition.Set) error {
+	original := photo[genRouter.Original]
+	if err := m.blobs.Put(ctx, img.BlobKey, bytes.NewReader(original)); err != nil {
+		return err
+	}
+	for size := range rendition.Sizes {
+		if bytes.Equal(photo[size], original) {
+			continue
+		}
+		key := img.BlobKey + "_" + string(size)
+		if err := m.blobs.Put(ctx, key, bytes.NewReader(photo[size])); err != nil {
+			return err
+		}
+		img.Renditions[size] = key
+	}
+	return nil
+}
+
+// blobKeys returns keys of original as well as renditions of img
+func (img *image) blobKeys() []string {
+	keys := []string{img.BlobKey}
+	for _, key := range img.Renditions {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// deleteBlobs removes blobs of images whose documents were not inserted due to cause. Failures are only
+// logged, since nothing refers to those blobs
+func (m *mongoClient) deleteBlobs(ctx context.Context, imageList []image, cause error) {
+	var serverErr mongo.ServerError
+	if errors.As(cause, &serverErr) && serverErr.HasErrorLabel(unknownCommitResultLabel) {
+		// Documents may have been inserted after all
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for i := range imageList {
+		for _, key := range imageList[i].blobKeys() {
+			if err := m.blobs.Delete(ctx, key); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msgf("Failed to delete orphaned blob %s", key)
+			}
+		}
+	}
+}
+
+// GetPetImage streams rendition of an image in size from blob store, original when the image has no such rendition
+func (m *mongoClient) GetPetImage(ctx context.Context, imageID string,
+	size genRouter.ImageSize) (io.Reader, int64, error) {
 	bsonImageID, err := bson.ObjectIDFromHex(imageID)
 	if err != nil {
 		return nil, 0, dbErr.ErrInvalidValue
@@ -127,7 +472,7 @@ func (m *mongoClient) GetPetImage(ctx context.Context, imageID string) (io.Reade
 
 	res := m.mongoDbHandler.Collection(imagesCollection).FindOne(ctx,
 		bson.M{iDField: bsonImageID, deletedOnField: bson.Null{}},
-		options.FindOne().SetProjection(bson.M{imageField: 1}))
+		options.FindOne().SetProjection(bson.M{blobKeyField: 1, renditionsField: 1}))
 	err = res.Err()
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -141,7 +486,18 @@ func (m *mongoClient) GetPetImage(ctx context.Context, imageID string) (io.Reade
 		return nil, 0, err
 	}
 
-	return bytes.NewReader(img.Image), int64(len(img.Image)), nil
+	key := img.BlobKey
+	if renditionKey, ok := img.Renditions[size]; ok {
+		key = renditionKey
+	}
+	blob, length, err := m.blobs.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, 0, dbErr.ErrNotFound
+		}
+		return nil, 0, err
+	}
+	return blob, length, nil
 }
 
 func (m *mongoClient) DeletePetImage(ctx context.Context, userID, imageID string) error {
@@ -154,15 +510,163 @@ func (m *mongoClient) DeletePetImage(ctx context.Context, userID, imageID string
 		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
 	}
 
-	_, err = m.mongoDbHandler.Collection(imagesCollection).UpdateOne(
+	res, err := m.mongoDbHandler.Collection(imagesCollection).UpdateOne(
 		ctx,
 		bson.M{iDField: bsonImageID, userIDField: bsonUserID, deletedOnField: bson.Null{}},
 		bson.M{setOperator: bson.M{deletedOnField: time.Now().UTC()}},
 	)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return dbErr.ErrNotFound
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return dbErr.ErrNotFound
+	}
+	return nil
+}
+
+// FindPets returns a page of pets matching params in increasing order of _id, starting after afterID.
+// Returns pets along with total count of matching pets & ID to resume from, empty when on last page
+func (m *mongoClient) FindPets(ctx context.Context, params *genRouter.FindPetsParams,
+	afterID string, limit int) ([]genRouter.PetWithMetadata, int, string, error) {
+	filter := bson.M{deletedOnField: bson.Null{}}
+	if params.Name != nil {
+		filter[nameField] = *params.Name
+	}
+	if params.Status != nil {
+		filter[statusField] = bson.M{inOperator: *params.Status}
+	}
+	if params.Tags != nil {
+		filter[tagsField] = bson.M{inOperator: *params.Tags}
+	}
+
+	// Keyset pagination over _id
+	pageStages := make([]bson.M, 0, 5)
+	if afterID != "" {
+		bsonAfterID, err := bson.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
 		}
+		pageStages = append(pageStages, bson.M{matchStage: bson.M{iDField: bson.M{gtOperator: bsonAfterID}}})
 	}
-	return err
+	pageStages = append(pageStages,
+		bson.M{sortStage: bson.M{iDField: 1}},
+		// One extra pet tells whether another page exists
+		bson.M{limitOperator: limit + 1},
+	)
+	pageStages = append(pageStages, petMetadataStages()...)
+
+	pipeline := []bson.M{
+		{matchStage: filter},
+		{
+			facetStage: bson.M{
+				"total": []bson.M{{countStage: "count"}},
+				"pets":  pageStages,
+			},
+		},
+	}
+	cursor, err := m.mongoDbHandler.Collection(petsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var res []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Pets []petWithMetadata `bson:"pets"`
+	}
+	if err = cursor.All(ctx, &res); err != nil {
+		return nil, 0, "", err
+	}
+	if len(res) == 0 || len(res[0].Total) == 0 {
+		return []genRouter.PetWithMetadata{}, 0, "", nil
+	}
+
+	petDocs, nextID := res[0].Pets, ""
+	if len(petDocs) > limit {
+		petDocs = petDocs[:limit]
+		nextID = petDocs[limit-1].ID.Hex()
+	}
+	pets := make([]genRouter.PetWithMetadata, len(petDocs))
+	for i := range petDocs {
+		pets[i] = petDocs[i].toAPI()
+	}
+	return pets, res[0].Total[0].Count, nextID, nil
+}
+
+func (m *mongoClient) GetPet(ctx context.Context, petID string) (*genRouter.PetWithMetadata, error) {
+	bsonPetID, err := bson.ObjectIDFromHex(petID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	pipeline := append([]bson.M{
+		{matchStage: bson.M{iDField: bsonPetID, deletedOnField: bson.Null{}}},
+	}, petMetadataStages()...)
+	cursor, err := m.mongoDbHandler.Collection(petsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err = cursor.Err(); err != nil {
+			return nil, err
+		}
+		return nil, dbErr.ErrNotFound
+	}
+	var petDoc petWithMetadata
+	if err = cursor.Decode(&petDoc); err != nil {
+		return nil, err
+	}
+	res := petDoc.toAPI()
+	return &res, nil
+}
+
+// petMetadataStages joins category name & IDs of active images to pets
+func petMetadataStages() []bson.M {
+	return []bson.M{
+		{
+			lookupStage: bson.M{
+				"from":         animalCategoryCollection,
+				"localField":   categoryIDField,
+				"foreignField": iDField,
+				"as":           "category",
+			},
+		},
+		{
+			lookupStage: bson.M{
+				"from":         imagesCollection,
+				"localField":   iDField,
+				"foreignField": petIDField,
+				"pipeline": []bson.M{
+					{matchStage: bson.M{deletedOnField: bson.Null{}}},
+					{projectStage: bson.M{iDField: 1}},
+				},
+				"as": "photos",
+			},
+		},
+	}
+}
+
+func (p *petWithMetadata) toAPI() genRouter.PetWithMetadata {
+	status := genRouter.PetStatus(p.Status)
+	res := genRouter.PetWithMetadata{
+		Id:       p.ID.Hex(),
+		Name:     p.Name,
+		Price:    p.Price.String(),
+		Status:   &status,
+		PhotoIds: make([]string, len(p.Photos)),
+	}
+	if len(p.Category) > 0 {
+		res.Category = p.Category[0].Name
+	}
+	if len(p.Tags) > 0 {
+		res.Tags = &p.Tags
+	}
+	for i := range p.Photos {
+		res.PhotoIds[i] = p.Photos[i].ID.Hex()
+	}
+	return res
 }
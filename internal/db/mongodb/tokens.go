@@ -0,0 +1,161 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (m *mongoClient) AddRefreshToken(ctx context.Context, userID, tokenHash string,
+	expiresOn time.Time) error {
+	bsonUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return dbErr.ErrInvalidValue
+	}
+
+	_, err = m.mongoDbHandler.Collection(refreshTokensCollection).InsertOne(ctx, refreshToken{
+		UserID:    bsonUserID,
+		FamilyID:  bson.NewObjectID(),
+		TokenHash: tokenHash,
+		ExpiresOn: expiresOn.UTC(),
+		CreatedOn: time.Now().UTC(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return dbErr.ErrConflict
+	}
+	return err
+}
+
+// RotateRefreshToken exchanges an active refresh token for a new one in the same family.
+// Presenting an already rotated token is treated as token theft & the whole family is revoked.
+// Returns userID & current role of the token owner
+func (m *mongoClient) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string,
+	expiresOn time.Time) (string, genRouter.UserRole, error) {
+	session, err := m.client.StartSession()
+	if err != nil {
+		return "", "", err
+	}
+	defer session.EndSession(ctx)
+
+	var reused bool
+	res, err := session.WithTransaction(
+		ctx,
+		func(sessCtx context.Context) (any, error) {
+			reused = false
+			findRes := m.mongoDbHandler.Collection(refreshTokensCollection).
+				FindOne(sessCtx, bson.M{tokenHashField: tokenHash})
+			errY := findRes.Err()
+			if errY != nil {
+				if errors.Is(errY, mongo.ErrNoDocuments) {
+					return nil, dbErr.ErrNotFound
+				}
+				return nil, errY
+			}
+			var token refreshToken
+			if errY = findRes.Decode(&token); errY != nil {
+				return nil, errY
+			}
+
+			now := time.Now().UTC()
+			switch {
+			case token.RevokedOn != nil:
+				return nil, dbErr.ErrNotFound
+			case token.RotatedOn != nil:
+				// Revocation has to be committed, hence no error is returned from transaction
+				reused = true
+				return nil, m.revokeRefreshTokens(sessCtx, bson.M{familyIDField: token.FamilyID})
+			case !token.ExpiresOn.After(now):
+				return nil, dbErr.ErrNotFound
+			}
+
+			userRes := m.mongoDbHandler.Collection(usersCollection).FindOne(
+				sessCtx,
+				bson.M{iDField: token.UserID, deletedOnField: bson.Null{}},
+				options.FindOne().SetProjection(bson.M{iDField: 1, roleField: 1}),
+			)
+			errY = userRes.Err()
+			if errY != nil {
+				if errors.Is(errY, mongo.ErrNoDocuments) {
+					return nil, dbErr.ErrNotFound
+				}
+				return nil, errY
+			}
+			var userInstance user
+			if errY = userRes.Decode(&userInstance); errY != nil {
+				return nil, errY
+			}
+
+			_, errY = m.mongoDbHandler.Collection(refreshTokensCollection).UpdateOne(
+				sessCtx,
+				bson.M{iDField: token.ID},
+				bson.M{setOperator: bson.M{rotatedOnField: now}},
+			)
+			if errY != nil {
+				return nil, errY
+			}
+			_, errY = m.mongoDbHandler.Collection(refreshTokensCollection).InsertOne(sessCtx, refreshToken{
+				UserID:    token.UserID,
+				FamilyID:  token.FamilyID,
+				TokenHash: newTokenHash,
+				ExpiresOn: expiresOn.UTC(),
+				CreatedOn: now,
+			})
+			if errY != nil {
+				if mongo.IsDuplicateKeyError(errY) {
+					return nil, dbErr.ErrConflict
+				}
+				return nil, errY
+			}
+			return &userInstance, nil
+		},
+		// Transactions apparently require read preference to be primary
+		options.Transaction().SetReadPreference(readpref.Primary()),
+	)
+	if err != nil {
+		return "", "", err
+	}
+	if reused {
+		return "", "", &dbErr.HintError{Key: tokenHashField, Err: dbErr.ErrConflict}
+	}
+	userInstance, _ := res.(*user)
+	return userInstance.ID.Hex(), *userRole(userInstance.Role), nil
+}
+
+func (m *mongoClient) RevokeRefreshTokenFamily(ctx context.Context, tokenHash string) error {
+	res := m.mongoDbHandler.Collection(refreshTokensCollection).FindOne(
+		ctx,
+		bson.M{tokenHashField: tokenHash},
+		options.FindOne().SetProjection(bson.M{familyIDField: 1}),
+	)
+	err := res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dbErr.ErrNotFound
+		}
+		return err
+	}
+	var token refreshToken
+	if err = res.Decode(&token); err != nil {
+		return err
+	}
+	return m.revokeRefreshTokens(ctx, bson.M{familyIDField: token.FamilyID})
+}
+
+// revokeRefreshTokens revokes every active token matching the filter
+func (m *mongoClient) revokeRefreshTokens(ctx context.Context, filter bson.M) error {
+	filter[revokedOnField] = bson.Null{}
+	_, err := m.mongoDbHandler.Collection(refreshTokensCollection).UpdateMany(
+		ctx,
+		filter,
+		bson.M{setOperator: bson.M{revokedOnField: time.Now().UTC()}},
+	)
+	return err
+}
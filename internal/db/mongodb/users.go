@@ -8,19 +8,26 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 
 	dbErr "github.com/vrv501/simple-api/internal/db/errors"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/lock"
 )
 
 func (m *mongoClient) AddUser(ctx context.Context,
 	userReq *genRouter.CreateUserJSONRequestBody) (*genRouter.UserJSONResponse, error) {
+	role := genRouter.Customer
+	if userReq.Role != nil {
+		role = *userReq.Role
+	}
 	_, err := m.mongoDbHandler.Collection(usersCollection).InsertOne(ctx, user{
 		Username:    userReq.Username,
 		Password:    userReq.Password,
 		Address:     userReq.Address,
 		FullName:    userReq.FullName,
 		PhoneNumber: userReq.PhoneNumber,
+		Role:        string(role),
 		CreatedOn:   time.Now().UTC(),
 	})
 	if err != nil {
@@ -40,6 +47,7 @@ func (m *mongoClient) AddUser(ctx context.Context,
 		FullName:    userReq.FullName,
 		PhoneNumber: userReq.PhoneNumber,
 		Address:     userReq.Address,
+		Role:        &role,
 	}, nil
 }
 
@@ -75,6 +83,7 @@ func (m *mongoClient) GetUser(ctx context.Context,
 		FullName:    userInstance.FullName,
 		PhoneNumber: userInstance.PhoneNumber,
 		Address:     userInstance.Address,
+		Role:        userRole(userInstance.Role),
 	}, nil
 }
 
@@ -84,10 +93,10 @@ func (m *mongoClient) DeleteUser(aInctx context.Context, userID string) error {
 		return dbErr.ErrInvalidValue
 	}
 
-	_, err = m.performAdvisoryLockDBOperation(aInctx, bsonID, func(aCtx context.Context) (any, error) {
+	_, err = m.performAdvisoryLockDBOperation(aInctx, bsonID, func(aCtx context.Context, l *lock.Lease) (any, error) {
 		errS := m.mongoDbHandler.Collection(petsCollection).FindOne(
 			aCtx,
-			bson.M{userIDField: bsonID, statusField: genRouter.Available},
+			bson.M{userIDField: bsonID, statusField: genRouter.Available, deletedOnField: bson.Null{}},
 			options.FindOne().SetProjection(bson.M{iDField: 1}),
 		).Err()
 		if errS != nil && !errors.Is(errS, mongo.ErrNoDocuments) {
@@ -117,16 +126,33 @@ func (m *mongoClient) DeleteUser(aInctx context.Context, userID string) error {
 			return nil, &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
 		}
 
-		res, errS := m.mongoDbHandler.Collection(usersCollection).
-			UpdateOne(aCtx, bson.M{iDField: bsonID, deletedOnField: bson.Null{}},
-				bson.M{setOperator: bson.M{deletedOnField: time.Now().UTC()}})
-		if errS == nil {
-			if res.MatchedCount == 0 {
-				return nil, dbErr.ErrNotFound
-			}
-			return nil, nil
+		session, errS := m.client.StartSession()
+		if errS != nil {
+			return nil, errS
 		}
-		return nil, errS
+		defer session.EndSession(aCtx)
+
+		return session.WithTransaction(
+			aCtx,
+			func(sessCtx context.Context) (any, error) {
+				if errY := m.locker.Renew(sessCtx, l); errY != nil {
+					return nil, errY
+				}
+				res, errY := m.mongoDbHandler.Collection(usersCollection).
+					UpdateOne(sessCtx, bson.M{iDField: bsonID, deletedOnField: bson.Null{}},
+						bson.M{setOperator: bson.M{deletedOnField: time.Now().UTC()}})
+				if errY != nil {
+					return nil, errY
+				}
+				if res.MatchedCount == 0 {
+					return nil, dbErr.ErrNotFound
+				}
+				// Deleted users should not be able to continue any session
+				return nil, m.revokeRefreshTokens(sessCtx, bson.M{userIDField: bsonID})
+			},
+			// Transactions apparently require read preference to be primary
+			options.Transaction().SetReadPreference(readpref.Primary()),
+		)
 	})
 	return err
 }
@@ -152,14 +178,38 @@ func (m *mongoClient) PatchUser(ctx context.Context, userID string,
 	}
 	updateDoc[updatedOnField] = time.Now().UTC()
 
-	res := m.mongoDbHandler.Collection(usersCollection).
-		FindOneAndUpdate(
-			ctx,
-			bson.M{iDField: bsonID, deletedOnField: bson.Null{}},
-			bson.M{setOperator: updateDoc},
-			options.FindOneAndUpdate().SetReturnDocument(options.After),
-		)
-	err = res.Err()
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	// Password change & revocation of sessions are made together, so that a password is never changed
+	// while sessions signed in with the old one remain valid
+	res, err := session.WithTransaction(
+		ctx,
+		func(sessCtx context.Context) (any, error) {
+			var userInstance user
+			errY := m.mongoDbHandler.Collection(usersCollection).
+				FindOneAndUpdate(
+					sessCtx,
+					bson.M{iDField: bsonID, deletedOnField: bson.Null{}},
+					bson.M{setOperator: updateDoc},
+					options.FindOneAndUpdate().SetReturnDocument(options.After),
+				).Decode(&userInstance)
+			if errY != nil {
+				return nil, errY
+			}
+			if userReq.Password != nil {
+				if errY = m.revokeRefreshTokens(sessCtx, bson.M{userIDField: bsonID}); errY != nil {
+					return nil, errY
+				}
+			}
+			return &userInstance, nil
+		},
+		// Transactions apparently require read preference to be primary
+		options.Transaction().SetReadPreference(readpref.Primary()),
+	)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, dbErr.ErrNotFound
@@ -169,16 +219,46 @@ func (m *mongoClient) PatchUser(ctx context.Context, userID string,
 		}
 		return nil, err
 	}
-	var userInstance user
-	err = res.Decode(&userInstance)
-	if err != nil {
-		return nil, err
-	}
+	userInstance, _ := res.(*user)
 
 	return &genRouter.UserJSONResponse{
 		Address:     userInstance.Address,
 		FullName:    userInstance.FullName,
 		PhoneNumber: userInstance.PhoneNumber,
 		Username:    userInstance.Username,
+		Role:        userRole(userInstance.Role),
 	}, nil
 }
+
+// GetUserCredentials returns userID, hashed password & role of an active user
+func (m *mongoClient) GetUserCredentials(ctx context.Context,
+	username string) (string, string, genRouter.UserRole, error) {
+	res := m.mongoDbHandler.Collection(usersCollection).FindOne(
+		ctx,
+		bson.M{usernameField: username, deletedOnField: bson.Null{}},
+		options.FindOne().SetProjection(bson.M{iDField: 1, passwordField: 1, roleField: 1}),
+	)
+	err := res.Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", "", "", dbErr.ErrNotFound
+		}
+		return "", "", "", err
+	}
+	var userInstance user
+	err = res.Decode(&userInstance)
+	if err != nil {
+		return "", "", "", err
+	}
+	return userInstance.ID.Hex(), userInstance.Password, *userRole(userInstance.Role), nil
+}
+
+// userRole maps stored role to api role.
+// Users created before roles were introduced are customers
+func userRole(role string) *genRouter.UserRole {
+	apiRole := genRouter.UserRole(role)
+	if apiRole == "" {
+		apiRole = genRouter.Customer
+	}
+	return &apiRole
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// AddCartItem holds an available pet for userID until [constants.CartHoldTTL] from now.
+// Adding a pet already held by userID renews the hold
+func (p *pgClient) AddCartItem(ctx context.Context, userID, petID string) (*genRouter.CartItem, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var item *genRouter.CartItem
+	err = p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var (
+			ownerID int64
+			status  genRouter.PetStatus
+		)
+		errS := tx.QueryRow(aCtx,
+			`SELECT user_id, status FROM pets WHERE id = $1 AND deleted_on IS NULL`,
+			petRowID,
+		).Scan(&ownerID, &status)
+		if errS != nil {
+			if errors.Is(errS, pgx.ErrNoRows) {
+				return &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+			}
+			return errS
+		}
+		if ownerID == userRowID {
+			return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+		}
+		if status != genRouter.Available {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+
+		now := time.Now().UTC()
+		var count int
+		errS = tx.QueryRow(aCtx,
+			`SELECT count(*) FROM cart_items WHERE user_id = $1 AND pet_id <> $2 AND held_until > $3`,
+			userRowID, petRowID, now,
+		).Scan(&count)
+		if errS != nil {
+			return errS
+		}
+		if count >= constants.MaxCartItems {
+			return &dbErr.HintError{Key: cartItemsCollection, Err: dbErr.ErrLimitExceeded}
+		}
+
+		// Holds are unique per pet, so an active hold of another user is left untouched.
+		// Expired holds are taken over even if not yet swept
+		heldUntil := now.Add(constants.CartHoldTTL)
+		res, errS := tx.Exec(aCtx,
+			`INSERT INTO cart_items (user_id, pet_id, held_until, created_on) VALUES ($1, $2, $3, $4)
+			ON CONFLICT ON CONSTRAINT cart_items_pet_id_unique DO UPDATE
+			SET user_id = EXCLUDED.user_id, held_until = EXCLUDED.held_until, created_on = EXCLUDED.created_on
+			WHERE cart_items.user_id = EXCLUDED.user_id OR cart_items.held_until <= $4`,
+			userRowID, petRowID, heldUntil, now,
+		)
+		if errS != nil {
+			return errS
+		}
+		if res.RowsAffected() == 0 {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+		item = &genRouter.CartItem{PetId: petID, HeldUntil: heldUntil}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// FindCartItems returns pets actively held by userID in the order they were added
+func (p *pgClient) FindCartItems(ctx context.Context, userID string) ([]genRouter.CartItem, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	rows, err := p.pool.Query(ctx,
+		`SELECT pet_id, held_until FROM cart_items WHERE user_id = $1 AND held_until > $2
+		ORDER BY created_on, id`,
+		userRowID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (genRouter.CartItem, error) {
+		var (
+			res   genRouter.CartItem
+			petID int64
+		)
+		errS := row.Scan(&petID, &res.HeldUntil)
+		res.PetId = formatID(petID)
+		return res, errS
+	})
+}
+
+// DeleteCartItem releases hold of userID on a pet
+func (p *pgClient) DeleteCartItem(ctx context.Context, userID, petID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res, err := p.pool.Exec(ctx,
+		`DELETE FROM cart_items WHERE user_id = $1 AND pet_id = $2 AND held_until > $3`,
+		userRowID, petRowID, time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return dbErr.ErrNotFound
+	}
+	return nil
+}
+
+// CheckoutCart places orders for pets actively held by userID, same as PlaceOrders.
+// Cart is emptied once orders are placed. Returns [dbErr.ErrNotFound] when cart is empty
+func (p *pgClient) CheckoutCart(ctx context.Context, userID string) ([]genRouter.Order, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var orders []genRouter.Order
+	err = p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		rows, errS := tx.Query(aCtx,
+			`SELECT pet_id FROM cart_items WHERE user_id = $1 AND held_until > $2 ORDER BY created_on, id`,
+			userRowID, time.Now().UTC(),
+		)
+		if errS != nil {
+			return errS
+		}
+		petIDs, errS := pgx.CollectRows(rows, pgx.RowTo[int64])
+		if errS != nil {
+			return errS
+		}
+		if len(petIDs) == 0 {
+			return dbErr.ErrNotFound
+		}
+
+		orders, errS = placeOrders(aCtx, tx, userRowID, petIDs)
+		if errS != nil {
+			return errS
+		}
+
+		// Expired holds left behind in cart go along
+		_, errS = tx.Exec(aCtx, `DELETE FROM cart_items WHERE user_id = $1`, userRowID)
+		return errS
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ReleaseExpiredHolds deletes holds which have expired & returns the count of holds released
+func (p *pgClient) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	res, err := p.pool.Exec(ctx, `DELETE FROM cart_items WHERE held_until <= $1`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
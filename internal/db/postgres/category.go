@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (p *pgClient) FindAnimalCategory(ctx context.Context,
+	name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	var (
+		id           int64
+		categoryName string
+	)
+	// Try exact match
+	err := p.pool.QueryRow(ctx,
+		`SELECT id, name FROM animal_categories WHERE name = $1`,
+		name,
+	).Scan(&id, &categoryName)
+	if err == nil {
+		return &genRouter.AnimalCategoryJSONResponse{
+			Id:   formatID(id),
+			Name: categoryName,
+		}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// Try fuzzy search, same as Atlas Search index with maxEdits 2 & prefixLength 1.
+	// Closest name wins
+	err = p.pool.QueryRow(ctx,
+		`SELECT id, name FROM animal_categories
+		WHERE left(lower(name), 1) = left(lower($1), 1) AND levenshtein(lower(name), lower($1)) <= 2
+		ORDER BY levenshtein(lower(name), lower($1)), name
+		LIMIT 1`,
+		name,
+	).Scan(&id, &categoryName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &genRouter.AnimalCategoryJSONResponse{
+		Id:   formatID(id),
+		Name: categoryName,
+	}, nil
+}
+
+func (p *pgClient) AddAnimalCategory(ctx context.Context, name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	var id int64
+	err := p.pool.QueryRow(ctx,
+		`INSERT INTO animal_categories (name, created_on) VALUES ($1, $2) RETURNING id`,
+		name, time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, dbErr.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &genRouter.AnimalCategoryJSONResponse{
+		Id:   formatID(id),
+		Name: name,
+	}, nil
+}
+
+func (p *pgClient) UpdateAnimalCategory(ctx context.Context, id,
+	name string) (*genRouter.AnimalCategoryJSONResponse, error) {
+	rowID, err := parseID(id)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	res, err := p.pool.Exec(ctx,
+		`UPDATE animal_categories SET name = $1, updated_on = $2 WHERE id = $3`,
+		name, time.Now().UTC(), rowID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, dbErr.ErrConflict
+		}
+		return nil, err
+	}
+	if res.RowsAffected() == 0 {
+		return nil, dbErr.ErrNotFound
+	}
+
+	return &genRouter.AnimalCategoryJSONResponse{
+		Id:   id,
+		Name: name,
+	}, nil
+}
+
+// findAnimalCategoryID validates animal category exists & returns its ID
+func findAnimalCategoryID(ctx context.Context, q querier, name string) (int64, error) {
+	var id int64
+	err := q.QueryRow(ctx, `SELECT id FROM animal_categories WHERE name = $1`, name).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, &dbErr.HintError{Key: animalCategoryCollection, Err: dbErr.ErrNotFound}
+		}
+		return 0, err
+	}
+	return id, nil
+}
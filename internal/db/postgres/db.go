@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vrv501/simple-api/internal/constants"
+)
+
+const (
+	// ENV vars
+	postgresHostEnvVar string = "POSTGRES_HOST" // Should be hostname:port
+	postgresURIEnvVar  string = "POSTGRES_URI"  // Should be postgres://{{ rest of the URI }}
+
+	defaultAPIUser          string = "apiUser"
+	defaultPostgresPassword string = "postgres"
+	defaultPostgresHost     string = "localhost:5432"
+
+	dbName string = "shop"
+
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	uniqueViolationCode     string = "23505"
+	foreignKeyViolationCode string = "23503"
+
+	// Keys of [dbErr.HintError], same as field names used by Mongo backend
+	iDField                  string = "_id"
+	userIDField              string = "user_id"
+	petIDField               string = "pet_id"
+	orderIDField             string = "order_id"
+	statusField              string = "status"
+	priceField               string = "price"
+	phoneNumberField         string = "phone_number"
+	usernameField            string = "username"
+	tokenHashField           string = "token_hash"
+	sellerIDField            string = "seller_id"
+	animalCategoryCollection string = "animal_categories"
+	petsCollection           string = "pets"
+	imagesCollection         string = "images"
+	ordersCollection         string = "orders"
+	cartItemsCollection      string = "cart_items"
+)
+
+type pgClient struct {
+	pool *pgxpool.Pool
+}
+
+//revive:disable:unexported-return
+func NewInstance(ctx context.Context) *pgClient {
+	connString := os.Getenv(postgresURIEnvVar)
+	if connString == "" {
+		host := os.Getenv(postgresHostEnvVar)
+		if host == "" {
+			host = defaultPostgresHost
+		}
+		username := os.Getenv(constants.DBUsername)
+		if username == "" {
+			username = defaultAPIUser
+		}
+		pswd := os.Getenv(constants.DBPassword)
+		if pswd == "" {
+			pswd = defaultPostgresPassword
+		}
+		connString = (&url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(username, pswd),
+			Host:   host,
+			Path:   dbName,
+		}).String()
+	}
+
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid postgres configuration %v", err))
+	}
+	cfg.ConnConfig.RuntimeParams["application_name"] = "pet-store-api-server"
+	// Query timeout, same as mongo
+	cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(int((5 * time.Minute).Milliseconds()))
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create postgres client %v", err))
+	}
+
+	err = pool.Ping(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("failed to ping db %v", err))
+	}
+
+	return &pgClient{pool: pool}
+}
+
+func (p *pgClient) Close(_ context.Context) error {
+	p.pool.Close()
+	return nil
+}
+
+// performAdvisoryLockDBOperation runs fn in a transaction holding an advisory lock on uniqueID.
+// Lock is released along with the transaction, hence a crashed holder never leaves it behind
+func (p *pgClient) performAdvisoryLockDBOperation(ctx context.Context, uniqueID int64,
+	fn func(aCtx context.Context, tx pgx.Tx) error) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DefaultTimeout)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", uniqueID); err != nil {
+			return err
+		}
+		return fn(ctx, tx)
+	})
+}
+
+// querier is satisfied by both the pool & transactions
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// parseID converts IDs handed out by this backend back to row IDs
+func parseID(id string) (int64, error) {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || rowID <= 0 {
+		return 0, errors.New("invalid id")
+	}
+	return rowID, nil
+}
+
+func formatID(rowID int64) string {
+	return strconv.FormatInt(rowID, 10)
+}
+
+// violatedConstraint returns name of the constraint violated by err when its code is code
+func violatedConstraint(err error, code string) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == code {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+func isUniqueViolation(err error) bool {
+	_, ok := violatedConstraint(err, uniqueViolationCode)
+	return ok
+}
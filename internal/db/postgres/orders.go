@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/orderstate"
+)
+
+// orderColumns selects an order in the order scanned by scanOrder
+const orderColumns = `id, pet_id, price::text, status, shipped_date, delivered_date, tracking_number, carrier`
+
+// PlaceOrders places an order for each of petIDs on behalf of userID & marks those pets sold.
+// Either all orders are placed or none, pets must exist, be available & not be owned by userID
+func (p *pgClient) PlaceOrders(ctx context.Context, userID string, petIDs []string) ([]genRouter.Order, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowIDs := make([]int64, len(petIDs))
+	for i := range petIDs {
+		petRowIDs[i], err = parseID(petIDs[i])
+		if err != nil {
+			return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrInvalidValue}
+		}
+	}
+
+	var orders []genRouter.Order
+	err = p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var errS error
+		orders, errS = placeOrders(aCtx, tx, userRowID, petRowIDs)
+		return errS
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// placeOrders is PlaceOrders within a transaction. Pets held in cart of other users cannot be ordered,
+// while holds of userID on ordered pets are released
+func placeOrders(ctx context.Context, tx pgx.Tx, userID int64, petIDs []int64) ([]genRouter.Order, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT id, user_id, price::text FROM pets WHERE id = ANY($1) AND deleted_on IS NULL`,
+		petIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	type petRow struct {
+		ID     int64
+		UserID int64
+		Price  string
+	}
+	pets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[petRow])
+	if err != nil {
+		return nil, err
+	}
+	if len(pets) != len(petIDs) {
+		return nil, &dbErr.HintError{Key: petIDField, Err: dbErr.ErrNotFound}
+	}
+	petsByID := make(map[int64]*petRow, len(pets))
+	for i := range pets {
+		if pets[i].UserID == userID {
+			return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrOwnPet}
+		}
+		petsByID[pets[i].ID] = &pets[i]
+	}
+
+	now := time.Now().UTC()
+	var held bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM cart_items WHERE pet_id = ANY($1) AND user_id <> $2 AND held_until > $3)`,
+		petIDs, userID, now,
+	).Scan(&held)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+	}
+
+	// Buyers are locked individually, hence two of them can race for the same pet.
+	// The loser's update waits on the winner's row lock & then no longer sees the pet available
+	res, err := tx.Exec(ctx,
+		`UPDATE pets SET status = $1, updated_on = $2
+		WHERE id = ANY($3) AND status = $4 AND deleted_on IS NULL`,
+		string(genRouter.Sold), now, petIDs, string(genRouter.Available),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected() != int64(len(petIDs)) {
+		return nil, &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+	}
+
+	// Price is fixed when ordered, pets may be repriced afterwards
+	orders := make([]genRouter.Order, len(petIDs))
+	for i, petID := range petIDs {
+		rows, err = tx.Query(ctx,
+			`INSERT INTO orders (user_id, pet_id, seller_id, price, status, created_on)
+			VALUES ($1, $2, $3, $4::numeric, $5, $6) RETURNING `+orderColumns,
+			userID, petID, petsByID[petID].UserID, petsByID[petID].Price, string(genRouter.Placed), now,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orders[i], err = pgx.CollectExactlyOneRow(rows, scanOrder)
+		if err != nil {
+			return nil, err
+		}
+		err = recordStatus(ctx, tx, orders[i].Id, genRouter.Placed, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM cart_items WHERE pet_id = ANY($1)`, petIDs)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindOrders returns a page of orders of userID matching params in increasing order of id, starting after afterID.
+// Returns orders along with total count of matching orders & ID to resume from, empty when on last page
+func (p *pgClient) FindOrders(ctx context.Context, userID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	return p.findOrders(ctx, userIDField, userID, params, afterID, limit)
+}
+
+// FindSellerOrders is same as FindOrders but for orders placed against pets of sellerID
+func (p *pgClient) FindSellerOrders(ctx context.Context, sellerID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	return p.findOrders(ctx, sellerIDField, sellerID, params, afterID, limit)
+}
+
+// findOrders pages through orders whose userColumn is userID
+func (p *pgClient) findOrders(ctx context.Context, userColumn, userID string, params *genRouter.FindOrdersParams,
+	afterID string, limit int) ([]genRouter.Order, int, string, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, 0, "", &dbErr.HintError{Key: userColumn, Err: dbErr.ErrInvalidValue}
+	}
+	conditions := []string{userColumn + " = $1"}
+	args := []any{userRowID}
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "$?", "$"+strconv.Itoa(len(args))))
+	}
+	if params.Status != nil {
+		statuses := make([]string, len(*params.Status))
+		for i, status := range *params.Status {
+			statuses[i] = string(status)
+		}
+		where("status = ANY($?)", statuses)
+	}
+	if params.AfterDate != nil {
+		where("created_on > $?", params.AfterDate.UTC())
+	}
+
+	var count int
+	err = p.pool.QueryRow(ctx,
+		`SELECT count(*) FROM orders WHERE `+strings.Join(conditions, " AND "),
+		args...,
+	).Scan(&count)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if count == 0 {
+		return []genRouter.Order{}, 0, "", nil
+	}
+
+	// Keyset pagination over id
+	if afterID != "" {
+		rowAfterID, errS := parseID(afterID)
+		if errS != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
+		}
+		where("id > $?", rowAfterID)
+	}
+	// One extra order tells whether another page exists
+	args = append(args, limit+1)
+	rows, err := p.pool.Query(ctx,
+		`SELECT `+orderColumns+` FROM orders WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY id LIMIT $`+strconv.Itoa(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	orders, err := pgx.CollectRows(rows, scanOrder)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	nextID := ""
+	if len(orders) > limit {
+		orders = orders[:limit]
+		nextID = orders[limit-1].Id
+	}
+	return orders, count, nextID, nil
+}
+
+func (p *pgClient) GetOrder(ctx context.Context, orderID string) (*genRouter.Order, error) {
+	rowID, err := parseID(orderID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	rows, err := p.pool.Query(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1`, rowID)
+	if err != nil {
+		return nil, err
+	}
+	o, err := pgx.CollectExactlyOneRow(rows, scanOrder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &o, nil
+}
+
+// DeleteOrder cancels an order of userID & makes its pet available again. Orders are kept
+// for bookkeeping, hence only status is changed as per [orderstate]
+func (p *pgClient) DeleteOrder(ctx context.Context, userID, orderID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		o, errS := transitionOrder(aCtx, tx, userIDField, userRowID, orderRowID, genRouter.Cancelled, nil, nil)
+		if errS != nil {
+			return errS
+		}
+		petID, _ := parseID(o.PetId)
+		_, errS = tx.Exec(aCtx,
+			`UPDATE pets SET status = $1, updated_on = $2 WHERE id = $3 AND status = $4`,
+			string(genRouter.Available), time.Now().UTC(), petID, string(genRouter.Sold),
+		)
+		return errS
+	})
+}
+
+// UpdateOrderStatus moves an order placed against a pet of sellerID forward as per [orderstate].
+// Tracking details are recorded along with the status when given
+func (p *pgClient) UpdateOrderStatus(ctx context.Context, sellerID, orderID string,
+	statusReq *genRouter.UpdateOrderStatusJSONRequestBody) (*genRouter.Order, error) {
+	sellerRowID, err := parseID(sellerID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: sellerIDField, Err: dbErr.ErrInvalidValue}
+	}
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var o *genRouter.Order
+	err = p.performAdvisoryLockDBOperation(ctx, sellerRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var errS error
+		o, errS = transitionOrder(aCtx, tx, sellerIDField, sellerRowID, orderRowID,
+			genRouter.OrderStatus(statusReq.Status), statusReq.TrackingNumber, statusReq.Carrier)
+		return errS
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// transitionOrder moves the order whose userColumn is userID to status, sets tracking details when given
+// & records it in order_status_history. Returns the updated order
+func transitionOrder(ctx context.Context, tx pgx.Tx, userColumn string, userID, orderID int64,
+	status genRouter.OrderStatus, trackingNumber, carrier *string) (*genRouter.Order, error) {
+	// Row lock makes a concurrent transition of the same order wait & see the newer status
+	var current genRouter.OrderStatus
+	err := tx.QueryRow(ctx,
+		`SELECT status FROM orders WHERE id = $1 AND `+userColumn+` = $2 FOR UPDATE`,
+		orderID, userID,
+	).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+
+	change, err := orderstate.Transition(current, status, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx,
+		`UPDATE orders SET status = $1, updated_on = $2,
+			shipped_date = COALESCE($3, shipped_date), delivered_date = COALESCE($4, delivered_date),
+			tracking_number = COALESCE($5, tracking_number), carrier = COALESCE($6, carrier)
+		WHERE id = $7 RETURNING `+orderColumns,
+		string(change.Status), change.ChangedOn, change.ShippedDate, change.DeliveredDate,
+		trackingNumber, carrier, orderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	o, err := pgx.CollectExactlyOneRow(rows, scanOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err = recordStatus(ctx, tx, o.Id, change.Status, change.ChangedOn); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// recordStatus appends status reached by an order to its history
+func recordStatus(ctx context.Context, tx pgx.Tx, orderID string, status genRouter.OrderStatus,
+	changedOn time.Time) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO order_status_history (order_id, status, changed_on) VALUES ($1, $2, $3)`,
+		orderID, string(status), changedOn,
+	)
+	return err
+}
+
+func scanOrder(row pgx.CollectableRow) (genRouter.Order, error) {
+	var (
+		res   genRouter.Order
+		id    int64
+		petID int64
+	)
+	err := row.Scan(&id, &petID, &res.Price, &res.Status, &res.ShippedDate, &res.DeliveredDate,
+		&res.TrackingNumber, &res.Carrier)
+	if err != nil {
+		return res, err
+	}
+	res.Id = formatID(id)
+	res.PetId = formatID(petID)
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+)
+
+func (p *pgClient) GetPetOwner(ctx context.Context, petID string) (string, error) {
+	return p.findOwner(ctx, `SELECT user_id FROM pets WHERE id = $1 AND deleted_on IS NULL`, petID)
+}
+
+func (p *pgClient) GetImageOwner(ctx context.Context, imageID string) (string, error) {
+	return p.findOwner(ctx, `SELECT user_id FROM images WHERE id = $1 AND deleted_on IS NULL`, imageID)
+}
+
+func (p *pgClient) GetOrderOwner(ctx context.Context, orderID string) (string, error) {
+	return p.findOwner(ctx, `SELECT user_id FROM orders WHERE id = $1`, orderID)
+}
+
+// GetOrderSeller returns userID owning the pet an order was placed for
+func (p *pgClient) GetOrderSeller(ctx context.Context, orderID string) (string, error) {
+	return p.findOwner(ctx, `SELECT seller_id FROM orders WHERE id = $1`, orderID)
+}
+
+// findOwner runs query selecting the owner of the row identified by id
+func (p *pgClient) findOwner(ctx context.Context, query, id string) (string, error) {
+	rowID, err := parseID(id)
+	if err != nil {
+		return "", &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var ownerID int64
+	err = p.pool.QueryRow(ctx, query, rowID).Scan(&ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", dbErr.ErrNotFound
+		}
+		return "", err
+	}
+	return formatID(ownerID), nil
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	"github.com/vrv501/simple-api/internal/payments"
+)
+
+// SavePaymentIntent records latest state of the payment intent of an order, replacing an earlier one
+func (p *pgClient) SavePaymentIntent(ctx context.Context, orderID string, intent *payments.Intent) error {
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	now := time.Now().UTC()
+	_, err = p.pool.Exec(ctx,
+		`INSERT INTO payment_intents (order_id, provider, intent_id, status, amount, currency, created_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ON CONSTRAINT payment_intents_order_id_unique DO UPDATE
+		SET provider = EXCLUDED.provider, intent_id = EXCLUDED.intent_id, status = EXCLUDED.status,
+			amount = EXCLUDED.amount, currency = EXCLUDED.currency, updated_on = EXCLUDED.created_on`,
+		orderRowID, intent.Provider, intent.ID, string(intent.Status), intent.Amount, intent.Currency, now,
+	)
+	if _, ok := violatedConstraint(err, foreignKeyViolationCode); ok {
+		return &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrNotFound}
+	}
+	return err
+}
+
+// GetPaymentIntent returns payment intent of an order, [dbErr.ErrNotFound] when order has none
+func (p *pgClient) GetPaymentIntent(ctx context.Context, orderID string) (*payments.Intent, error) {
+	orderRowID, err := parseID(orderID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: orderIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	var (
+		res    payments.Intent
+		status string
+	)
+	err = p.pool.QueryRow(ctx,
+		`SELECT intent_id, provider, status, amount, currency FROM payment_intents WHERE order_id = $1`,
+		orderRowID,
+	).Scan(&res.ID, &res.Provider, &status, &res.Amount, &res.Currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	res.Status = payments.Status(status)
+	return &res, nil
+}
@@ -0,0 +1,446 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/vrv501/simple-api/internal/constants"
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+	"github.com/vrv501/simple-api/internal/rendition"
+)
+
+// petColumns selects a pet along with its category name & IDs of active images, in the order scanned by scanPet
+const petColumns = `p.id, p.name, p.price::text, p.status, p.tags, c.name,
+	ARRAY(SELECT i.id::text FROM images i WHERE i.pet_id = p.id AND i.deleted_on IS NULL ORDER BY i.id)`
+
+// renditionColumns are columns of images keeping scaled down renditions, image column keeps the original
+var renditionColumns = map[genRouter.ImageSize]string{
+	genRouter.Small:  "image_small",
+	genRouter.Medium: "image_medium",
+}
+
+func (p *pgClient) AddPet(ctx context.Context, userID string,
+	petReq *genRouter.Pet, photos []rendition.Set) error {
+	// Validate userID string
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	// Validate price string
+	price, err := parsePrice(petReq.Price)
+	if err != nil {
+		return err
+	}
+
+	categoryID, err := findAnimalCategoryID(ctx, p.pool, petReq.Category)
+	if err != nil {
+		return err
+	}
+
+	return p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		// Validate userID exists
+		var exists bool
+		errS := tx.QueryRow(aCtx,
+			`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_on IS NULL)`,
+			userRowID,
+		).Scan(&exists)
+		if errS != nil {
+			return errS
+		}
+		if !exists {
+			return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrNotFound}
+		}
+
+		var tags []string
+		if petReq.Tags != nil {
+			tags = *petReq.Tags
+		}
+		var petID int64
+		errS = tx.QueryRow(aCtx,
+			`INSERT INTO pets (name, category_id, user_id, price, status, tags, created_on)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			petReq.Name, categoryID, userRowID, price, string(genRouter.Available), tags, time.Now().UTC(),
+		).Scan(&petID)
+		if errS != nil {
+			if isUniqueViolation(errS) {
+				return dbErr.ErrConflict
+			}
+			return errS
+		}
+
+		_, errS = insertPetImages(aCtx, tx, userRowID, petID, photos)
+		return errS
+	})
+}
+
+// ReplacePet replaces details & photos of a pet owned by userID. Previous photos are soft-deleted.
+// Sold pets cannot be replaced
+func (p *pgClient) ReplacePet(ctx context.Context, userID, petID string,
+	petReq *genRouter.Pet, photos []rendition.Set) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	price, err := parsePrice(petReq.Price)
+	if err != nil {
+		return err
+	}
+	categoryID, err := findAnimalCategoryID(ctx, p.pool, petReq.Category)
+	if err != nil {
+		return err
+	}
+	status := genRouter.Available
+	if petReq.Status != nil {
+		status = *petReq.Status
+	}
+	var tags []string
+	if petReq.Tags != nil {
+		tags = *petReq.Tags
+	}
+
+	// Lock is taken on the owner, same as AddPet, so that pets of a user change one at a time
+	return p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var currentStatus string
+		errS := tx.QueryRow(aCtx,
+			`SELECT status FROM pets WHERE id = $1 AND user_id = $2 AND deleted_on IS NULL FOR UPDATE`,
+			petRowID, userRowID,
+		).Scan(&currentStatus)
+		if errS != nil {
+			if errors.Is(errS, pgx.ErrNoRows) {
+				return dbErr.ErrNotFound
+			}
+			return errS
+		}
+		if currentStatus == string(genRouter.Sold) {
+			return &dbErr.HintError{Key: statusField, Err: dbErr.ErrConflict}
+		}
+
+		now := time.Now().UTC()
+		_, errS = tx.Exec(aCtx,
+			`UPDATE pets SET name = $1, category_id = $2, price = $3, status = $4, tags = $5, updated_on = $6
+			WHERE id = $7`,
+			petReq.Name, categoryID, price, string(status), tags, now, petRowID,
+		)
+		if errS != nil {
+			if isUniqueViolation(errS) {
+				return dbErr.ErrConflict
+			}
+			return errS
+		}
+
+		_, errS = tx.Exec(aCtx,
+			`UPDATE images SET deleted_on = $1 WHERE pet_id = $2 AND deleted_on IS NULL`,
+			now, petRowID,
+		)
+		if errS != nil {
+			return errS
+		}
+		_, errS = insertPetImages(aCtx, tx, userRowID, petRowID, photos)
+		return errS
+	})
+}
+
+// DeletePet soft-deletes a pet owned by userID along with its images.
+// Pets with orders that are neither delivered nor cancelled cannot be deleted
+func (p *pgClient) DeletePet(ctx context.Context, userID, petID string) error {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	return p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var exists bool
+		errS := tx.QueryRow(aCtx,
+			`SELECT EXISTS (SELECT 1 FROM orders WHERE pet_id = $1 AND status NOT IN ($2, $3))`,
+			petRowID, string(genRouter.Delivered), string(genRouter.Cancelled),
+		).Scan(&exists)
+		if errS != nil {
+			return errS
+		}
+		if exists {
+			return &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
+		}
+
+		now := time.Now().UTC()
+		res, errS := tx.Exec(aCtx,
+			`UPDATE pets SET deleted_on = $1 WHERE id = $2 AND user_id = $3 AND deleted_on IS NULL`,
+			now, petRowID, userRowID,
+		)
+		if errS != nil {
+			return errS
+		}
+		if res.RowsAffected() == 0 {
+			return dbErr.ErrNotFound
+		}
+
+		_, errS = tx.Exec(aCtx,
+			`UPDATE images SET deleted_on = $1 WHERE pet_id = $2 AND deleted_on IS NULL`,
+			now, petRowID,
+		)
+		return errS
+	})
+}
+
+// AddPetImages appends photos to a pet owned by userID & returns IDs of the new images.
+// Count of active images of a pet never exceeds [constants.MaxPetImages]
+func (p *pgClient) AddPetImages(ctx context.Context, userID, petID string,
+	photos []rendition.Set) ([]string, error) {
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+	petRowID, err := parseID(petID)
+	if err != nil {
+		return nil, &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	// Every change to images of a pet is made holding lock on its owner,
+	// hence concurrent uploads cannot together go beyond the limit
+	var imageIDs []string
+	err = p.performAdvisoryLockDBOperation(ctx, userRowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var exists bool
+		errS := tx.QueryRow(aCtx,
+			`SELECT EXISTS (SELECT 1 FROM pets WHERE id = $1 AND user_id = $2 AND deleted_on IS NULL)`,
+			petRowID, userRowID,
+		).Scan(&exists)
+		if errS != nil {
+			return errS
+		}
+		if !exists {
+			return dbErr.ErrNotFound
+		}
+
+		var count int
+		errS = tx.QueryRow(aCtx,
+			`SELECT count(*) FROM images WHERE pet_id = $1 AND deleted_on IS NULL`,
+			petRowID,
+		).Scan(&count)
+		if errS != nil {
+			return errS
+		}
+		if count+len(photos) > constants.MaxPetImages {
+			return &dbErr.HintError{Key: imagesCollection, Err: dbErr.ErrLimitExceeded}
+		}
+
+		imageIDs, errS = insertPetImages(aCtx, tx, userRowID, petRowID, photos)
+		return errS
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imageIDs, nil
+}
+
+// insertPetImages stores photos of a pet & returns their IDs in the same order.
+// Renditions which are the original itself are stored as NULL
+func insertPetImages(ctx context.Context, tx pgx.Tx, userID, petID int64,
+	photos []rendition.Set) ([]string, error) {
+	imageIDs := make([]string, len(photos))
+	for i := range photos {
+		original := photos[i][genRouter.Original]
+		scaled := func(size genRouter.ImageSize) []byte {
+			if bytes.Equal(photos[i][size], original) {
+				return nil
+			}
+			return photos[i][size]
+		}
+		var imageID int64
+		err := tx.QueryRow(ctx,
+			`INSERT INTO images (pet_id, user_id, image, image_small, image_medium)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			petID, userID, original, scaled(genRouter.Small), scaled(genRouter.Medium),
+		).Scan(&imageID)
+		if err != nil {
+			return nil, err
+		}
+		imageIDs[i] = formatID(imageID)
+	}
+	return imageIDs, nil
+}
+
+// GetPetImage returns rendition of an image in size, original when the image has no such rendition
+func (p *pgClient) GetPetImage(ctx context.Context, imageID string,
+	size genRouter.ImageSize) (io.Reader, int64, error) {
+	rowID, err := parseID(imageID)
+	if err != nil {
+		return nil, 0, dbErr.ErrInvalidValue
+	}
+
+	column := "image"
+	if c, ok := renditionColumns[size]; ok {
+		column = "COALESCE(" + c + ", image)"
+	}
+	var img []byte
+	err = p.pool.QueryRow(ctx,
+		`SELECT `+column+` FROM images WHERE id = $1 AND deleted_on IS NULL`,
+		rowID,
+	).Scan(&img)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, dbErr.ErrNotFound
+		}
+		return nil, 0, err
+	}
+
+	return bytes.NewReader(img), int64(len(img)), nil
+}
+
+func (p *pgClient) DeletePetImage(ctx context.Context, userID, imageID string) error {
+	imageRowID, err := parseID(imageID)
+	if err != nil {
+		return &dbErr.HintError{Key: iDField, Err: dbErr.ErrInvalidValue}
+	}
+	userRowID, err := parseID(userID)
+	if err != nil {
+		return &dbErr.HintError{Key: userIDField, Err: dbErr.ErrInvalidValue}
+	}
+
+	res, err := p.pool.Exec(ctx,
+		`UPDATE images SET deleted_on = $1 WHERE id = $2 AND user_id = $3 AND deleted_on IS NULL`,
+		time.Now().UTC(), imageRowID, userRowID,
+	)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return dbErr.ErrNotFound
+	}
+	return nil
+}
+
+// FindPets returns a page of pets matching params in increasing order of id, starting after afterID.
+// Returns pets along with total count of matching pets & ID to resume from, empty when on last page
+func (p *pgClient) FindPets(ctx context.Context, params *genRouter.FindPetsParams,
+	afterID string, limit int) ([]genRouter.PetWithMetadata, int, string, error) {
+	conditions := []string{"p.deleted_on IS NULL"}
+	args := make([]any, 0, 5)
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "$?", "$"+strconv.Itoa(len(args))))
+	}
+	if params.Name != nil {
+		where("p.name = $?", *params.Name)
+	}
+	if params.Status != nil {
+		statuses := make([]string, len(*params.Status))
+		for i, status := range *params.Status {
+			statuses[i] = string(status)
+		}
+		where("p.status = ANY($?)", statuses)
+	}
+	if params.Tags != nil {
+		where("p.tags && $?", *params.Tags)
+	}
+
+	var count int
+	err := p.pool.QueryRow(ctx,
+		`SELECT count(*) FROM pets p WHERE `+strings.Join(conditions, " AND "),
+		args...,
+	).Scan(&count)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if count == 0 {
+		return []genRouter.PetWithMetadata{}, 0, "", nil
+	}
+
+	// Keyset pagination over id
+	if afterID != "" {
+		rowAfterID, errS := parseID(afterID)
+		if errS != nil {
+			return nil, 0, "", dbErr.ErrInvalidValue
+		}
+		where("p.id > $?", rowAfterID)
+	}
+	// One extra pet tells whether another page exists
+	args = append(args, limit+1)
+	rows, err := p.pool.Query(ctx,
+		`SELECT `+petColumns+` FROM pets p JOIN animal_categories c ON c.id = p.category_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY p.id LIMIT $`+strconv.Itoa(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	pets, err := pgx.CollectRows(rows, scanPet)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	nextID := ""
+	if len(pets) > limit {
+		pets = pets[:limit]
+		nextID = pets[limit-1].Id
+	}
+	return pets, count, nextID, nil
+}
+
+func (p *pgClient) GetPet(ctx context.Context, petID string) (*genRouter.PetWithMetadata, error) {
+	rowID, err := parseID(petID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	rows, err := p.pool.Query(ctx,
+		`SELECT `+petColumns+` FROM pets p JOIN animal_categories c ON c.id = p.category_id
+		WHERE p.id = $1 AND p.deleted_on IS NULL`,
+		rowID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res, err := pgx.CollectExactlyOneRow(rows, scanPet)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+func scanPet(row pgx.CollectableRow) (genRouter.PetWithMetadata, error) {
+	var (
+		res    genRouter.PetWithMetadata
+		id     int64
+		status genRouter.PetStatus
+		tags   []string
+	)
+	err := row.Scan(&id, &res.Name, &res.Price, &status, &tags, &res.Category, &res.PhotoIds)
+	if err != nil {
+		return res, err
+	}
+	res.Id = formatID(id)
+	res.Status = &status
+	if len(tags) > 0 {
+		res.Tags = &tags
+	}
+	return res, nil
+}
+
+// parsePrice validates price is a decimal, same as Decimal128 parsing of Mongo backend
+func parsePrice(price string) (pgtype.Numeric, error) {
+	var res pgtype.Numeric
+	if err := res.Scan(price); err != nil || res.NaN || res.InfinityModifier != pgtype.Finite {
+		return res, &dbErr.HintError{Key: priceField, Err: dbErr.ErrInvalidValue}
+	}
+	return res, nil
+}
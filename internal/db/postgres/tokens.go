@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (p *pgClient) AddRefreshToken(ctx context.Context, userID, tokenHash string,
+	expiresOn time.Time) error {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return dbErr.ErrInvalidValue
+	}
+
+	_, err = p.pool.Exec(ctx,
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_on, created_on)
+		VALUES ($1, gen_random_uuid(), $2, $3, $4)`,
+		rowID, tokenHash, expiresOn.UTC(), time.Now().UTC(),
+	)
+	if isUniqueViolation(err) {
+		return dbErr.ErrConflict
+	}
+	return err
+}
+
+// RotateRefreshToken exchanges an active refresh token for a new one in the same family.
+// Presenting an already rotated token is treated as token theft & the whole family is revoked.
+// Returns userID & current role of the token owner
+func (p *pgClient) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string,
+	expiresOn time.Time) (string, genRouter.UserRole, error) {
+	var (
+		reused bool
+		userID int64
+		role   genRouter.UserRole
+	)
+	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		var (
+			tokenID   int64
+			familyID  string
+			expiresAt time.Time
+			rotatedOn *time.Time
+			revokedOn *time.Time
+		)
+		// Row lock makes concurrent rotations of the same token queue up,
+		// so that the later one sees the token rotated
+		errY := tx.QueryRow(ctx,
+			`SELECT id, user_id, family_id::text, expires_on, rotated_on, revoked_on FROM refresh_tokens
+			WHERE token_hash = $1 FOR UPDATE`,
+			tokenHash,
+		).Scan(&tokenID, &userID, &familyID, &expiresAt, &rotatedOn, &revokedOn)
+		if errY != nil {
+			if errors.Is(errY, pgx.ErrNoRows) {
+				return dbErr.ErrNotFound
+			}
+			return errY
+		}
+
+		now := time.Now().UTC()
+		switch {
+		case revokedOn != nil:
+			return dbErr.ErrNotFound
+		case rotatedOn != nil:
+			// Revocation has to be committed, hence no error is returned from transaction
+			reused = true
+			return revokeRefreshTokens(ctx, tx, "family_id", familyID)
+		case !expiresAt.After(now):
+			return dbErr.ErrNotFound
+		}
+
+		errY = tx.QueryRow(ctx,
+			`SELECT role FROM users WHERE id = $1 AND deleted_on IS NULL`,
+			userID,
+		).Scan(&role)
+		if errY != nil {
+			if errors.Is(errY, pgx.ErrNoRows) {
+				return dbErr.ErrNotFound
+			}
+			return errY
+		}
+
+		_, errY = tx.Exec(ctx, `UPDATE refresh_tokens SET rotated_on = $1 WHERE id = $2`, now, tokenID)
+		if errY != nil {
+			return errY
+		}
+		_, errY = tx.Exec(ctx,
+			`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_on, created_on)
+			VALUES ($1, $2, $3, $4, $5)`,
+			userID, familyID, newTokenHash, expiresOn.UTC(), now,
+		)
+		if isUniqueViolation(errY) {
+			return dbErr.ErrConflict
+		}
+		return errY
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if reused {
+		return "", "", &dbErr.HintError{Key: tokenHashField, Err: dbErr.ErrConflict}
+	}
+	return formatID(userID), role, nil
+}
+
+func (p *pgClient) RevokeRefreshTokenFamily(ctx context.Context, tokenHash string) error {
+	var familyID string
+	err := p.pool.QueryRow(ctx,
+		`SELECT family_id::text FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&familyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return dbErr.ErrNotFound
+		}
+		return err
+	}
+	return revokeRefreshTokens(ctx, p.pool, "family_id", familyID)
+}
+
+// revokeRefreshTokens revokes every active token whose column is value
+func revokeRefreshTokens(ctx context.Context, q querier, column string, value any) error {
+	_, err := q.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_on = $1 WHERE `+column+` = $2 AND revoked_on IS NULL`,
+		time.Now().UTC(), value,
+	)
+	return err
+}
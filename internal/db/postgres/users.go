@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	dbErr "github.com/vrv501/simple-api/internal/db/errors"
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func (p *pgClient) AddUser(ctx context.Context,
+	userReq *genRouter.CreateUserJSONRequestBody) (*genRouter.UserJSONResponse, error) {
+	role := genRouter.Customer
+	if userReq.Role != nil {
+		role = *userReq.Role
+	}
+	_, err := p.pool.Exec(ctx,
+		`INSERT INTO users (username, password, address, full_name, phone_number, role, created_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		userReq.Username, userReq.Password, userReq.Address, userReq.FullName, userReq.PhoneNumber,
+		string(role), time.Now().UTC(),
+	)
+	if err != nil {
+		if constraint, ok := violatedConstraint(err, uniqueViolationCode); ok {
+			if strings.Contains(constraint, phoneNumberField) {
+				return nil, &dbErr.HintError{Key: phoneNumberField, Err: dbErr.ErrConflict}
+			}
+			return nil, &dbErr.HintError{Key: usernameField, Err: dbErr.ErrConflict}
+		}
+		return nil, err
+	}
+
+	return &genRouter.UserJSONResponse{
+		Username:    userReq.Username,
+		FullName:    userReq.FullName,
+		PhoneNumber: userReq.PhoneNumber,
+		Address:     userReq.Address,
+		Role:        &role,
+	}, nil
+}
+
+func (p *pgClient) GetUser(ctx context.Context,
+	userID string) (*genRouter.UserJSONResponse, error) {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+
+	var (
+		res  genRouter.UserJSONResponse
+		role genRouter.UserRole
+	)
+	err = p.pool.QueryRow(ctx,
+		`SELECT username, full_name, phone_number, address, role FROM users
+		WHERE id = $1 AND deleted_on IS NULL`,
+		rowID,
+	).Scan(&res.Username, &res.FullName, &res.PhoneNumber, &res.Address, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		return nil, err
+	}
+	res.Role = &role
+	return &res, nil
+}
+
+func (p *pgClient) DeleteUser(ctx context.Context, userID string) error {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return dbErr.ErrInvalidValue
+	}
+
+	return p.performAdvisoryLockDBOperation(ctx, rowID, func(aCtx context.Context, tx pgx.Tx) error {
+		var exists bool
+		errS := tx.QueryRow(aCtx,
+			`SELECT EXISTS (SELECT 1 FROM pets WHERE user_id = $1 AND status = $2 AND deleted_on IS NULL)`,
+			rowID, string(genRouter.Available),
+		).Scan(&exists)
+		if errS != nil {
+			return errS
+		}
+		if exists {
+			return &dbErr.HintError{Key: petsCollection, Err: dbErr.ErrForeignKeyViolation}
+		}
+
+		errS = tx.QueryRow(aCtx,
+			`SELECT EXISTS (SELECT 1 FROM orders WHERE user_id = $1 AND status NOT IN ($2, $3))`,
+			rowID, string(genRouter.Delivered), string(genRouter.Cancelled),
+		).Scan(&exists)
+		if errS != nil {
+			return errS
+		}
+		if exists {
+			return &dbErr.HintError{Key: ordersCollection, Err: dbErr.ErrForeignKeyViolation}
+		}
+
+		res, errS := tx.Exec(aCtx,
+			`UPDATE users SET deleted_on = $1 WHERE id = $2 AND deleted_on IS NULL`,
+			time.Now().UTC(), rowID,
+		)
+		if errS != nil {
+			return errS
+		}
+		if res.RowsAffected() == 0 {
+			return dbErr.ErrNotFound
+		}
+		// Deleted users should not be able to continue any session
+		return revokeRefreshTokens(aCtx, tx, userIDField, rowID)
+	})
+}
+
+func (p *pgClient) PatchUser(ctx context.Context, userID string,
+	userReq *genRouter.PatchUserApplicationMergePatchPlusJSONRequestBody) (*genRouter.UserJSONResponse, error) {
+	rowID, err := parseID(userID)
+	if err != nil {
+		return nil, dbErr.ErrInvalidValue
+	}
+	columns := make([]string, 0, 5)
+	args := make([]any, 0, 6)
+	set := func(column string, value any) {
+		args = append(args, value)
+		columns = append(columns, column+" = $"+strconv.Itoa(len(args)))
+	}
+	if userReq.FullName != nil {
+		set("full_name", *userReq.FullName)
+	}
+	if userReq.Password != nil {
+		set("password", *userReq.Password)
+	}
+	if userReq.PhoneNumber != nil {
+		set(phoneNumberField, *userReq.PhoneNumber)
+	}
+	if userReq.Address != nil {
+		set("address", *userReq.Address)
+	}
+	set("updated_on", time.Now().UTC())
+	args = append(args, rowID)
+
+	var (
+		res  genRouter.UserJSONResponse
+		role genRouter.UserRole
+	)
+	err = pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		errS := tx.QueryRow(ctx,
+			`UPDATE users SET `+strings.Join(columns, ", ")+`
+			WHERE id = $`+strconv.Itoa(len(args))+` AND deleted_on IS NULL
+			RETURNING username, full_name, phone_number, address, role`,
+			args...,
+		).Scan(&res.Username, &res.FullName, &res.PhoneNumber, &res.Address, &role)
+		if errS != nil {
+			return errS
+		}
+		if userReq.Password != nil {
+			return revokeRefreshTokens(ctx, tx, userIDField, rowID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbErr.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, dbErr.ErrConflict
+		}
+		return nil, err
+	}
+	res.Role = &role
+	return &res, nil
+}
+
+// GetUserCredentials returns userID, hashed password & role of an active user
+func (p *pgClient) GetUserCredentials(ctx context.Context,
+	username string) (string, string, genRouter.UserRole, error) {
+	var (
+		id       int64
+		password string
+		role     genRouter.UserRole
+	)
+	err := p.pool.QueryRow(ctx,
+		`SELECT id, password, role FROM users WHERE username = $1 AND deleted_on IS NULL`,
+		username,
+	).Scan(&id, &password, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", "", dbErr.ErrNotFound
+		}
+		return "", "", "", err
+	}
+	return formatID(id), password, role, nil
+}
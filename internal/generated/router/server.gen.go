@@ -28,12 +28,21 @@ type ServerInterface interface {
 	// Replace existing animal-category data using Id.
 	// (PUT /animal-categories/{animalCategoryId})
 	ReplaceAnimalCategory(w http.ResponseWriter, r *http.Request, animalCategoryId Id)
+	// Login using username & password.
+	// (POST /auth/login)
+	Login(w http.ResponseWriter, r *http.Request)
+	// Revoke refresh token.
+	// (POST /auth/logout)
+	Logout(w http.ResponseWriter, r *http.Request)
+	// Exchange refresh token for new tokens.
+	// (POST /auth/refresh)
+	RefreshToken(w http.ResponseWriter, r *http.Request)
 	// Delete a pet image.
 	// (DELETE /images/{imageId})
 	DeletePetImage(w http.ResponseWriter, r *http.Request, imageId ImageId)
 	// Get a pet image using ID.
 	// (GET /images/{imageId})
-	GetImageByID(w http.ResponseWriter, r *http.Request, imageId ImageId)
+	GetImageByID(w http.ResponseWriter, r *http.Request, imageId ImageId, params GetImageByIDParams)
 	// Find Pets using name, status, tags.
 	// (GET /pets)
 	FindPets(w http.ResponseWriter, r *http.Request, params FindPetsParams)
@@ -52,6 +61,21 @@ type ServerInterface interface {
 	// Upload a new image for a pet.
 	// (POST /pets/{petId}/images)
 	UploadPetImage(w http.ResponseWriter, r *http.Request, petId PetId)
+	// Find orders placed against pets of the user.
+	// (GET /sellers/me/orders)
+	FindSellerOrders(w http.ResponseWriter, r *http.Request, params FindSellerOrdersParams)
+	// List pets held in user cart.
+	// (GET /store/cart)
+	ListCart(w http.ResponseWriter, r *http.Request)
+	// Add pet to user cart.
+	// (POST /store/cart)
+	AddToCart(w http.ResponseWriter, r *http.Request)
+	// Checkout user cart.
+	// (POST /store/cart/checkout)
+	CheckoutCart(w http.ResponseWriter, r *http.Request)
+	// Remove pet from user cart.
+	// (DELETE /store/cart/{petId})
+	RemoveFromCart(w http.ResponseWriter, r *http.Request, petId PetId)
 	// Find user orders using status.
 	// (GET /store/orders)
 	FindOrders(w http.ResponseWriter, r *http.Request, params FindOrdersParams)
@@ -64,6 +88,9 @@ type ServerInterface interface {
 	// Find user order by ID.
 	// (GET /store/orders/{orderId})
 	GetOrderByID(w http.ResponseWriter, r *http.Request, orderId OrderId)
+	// Advance order fulfilment.
+	// (PATCH /store/orders/{orderId}/status)
+	UpdateOrderStatus(w http.ResponseWriter, r *http.Request, orderId OrderId)
 	// Delete user resource.
 	// (DELETE /users)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
@@ -178,6 +205,48 @@ func (siw *ServerInterfaceWrapper) ReplaceAnimalCategory(w http.ResponseWriter,
 	handler.ServeHTTP(w, r)
 }
 
+// Login operation middleware
+func (siw *ServerInterfaceWrapper) Login(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Login(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Logout operation middleware
+func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Logout(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RefreshToken operation middleware
+func (siw *ServerInterfaceWrapper) RefreshToken(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RefreshToken(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // DeletePetImage operation middleware
 func (siw *ServerInterfaceWrapper) DeletePetImage(w http.ResponseWriter, r *http.Request) {
 
@@ -229,8 +298,19 @@ func (siw *ServerInterfaceWrapper) GetImageByID(w http.ResponseWriter, r *http.R
 
 	r = r.WithContext(ctx)
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetImageByIDParams
+
+	// ------------- Optional query parameter "size" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "size", r.URL.Query(), &params.Size)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "size", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetImageByID(w, r, imageId)
+		siw.Handler.GetImageByID(w, r, imageId, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -449,6 +529,154 @@ func (siw *ServerInterfaceWrapper) UploadPetImage(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r)
 }
 
+// FindSellerOrders operation middleware
+func (siw *ServerInterfaceWrapper) FindSellerOrders(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params FindSellerOrdersParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "afterDate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "afterDate", r.URL.Query(), &params.AfterDate)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "afterDate", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.FindSellerOrders(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListCart operation middleware
+func (siw *ServerInterfaceWrapper) ListCart(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListCart(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AddToCart operation middleware
+func (siw *ServerInterfaceWrapper) AddToCart(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AddToCart(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CheckoutCart operation middleware
+func (siw *ServerInterfaceWrapper) CheckoutCart(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CheckoutCart(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RemoveFromCart operation middleware
+func (siw *ServerInterfaceWrapper) RemoveFromCart(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "petId" -------------
+	var petId PetId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "petId", r.PathValue("petId"), &petId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "petId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RemoveFromCart(w, r, petId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // FindOrders operation middleware
 func (siw *ServerInterfaceWrapper) FindOrders(w http.ResponseWriter, r *http.Request) {
 
@@ -588,6 +816,37 @@ func (siw *ServerInterfaceWrapper) GetOrderByID(w http.ResponseWriter, r *http.R
 	handler.ServeHTTP(w, r)
 }
 
+// UpdateOrderStatus operation middleware
+func (siw *ServerInterfaceWrapper) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "orderId" -------------
+	var orderId OrderId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "orderId", r.PathValue("orderId"), &orderId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateOrderStatus(w, r, orderId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // DeleteUser operation middleware
 func (siw *ServerInterfaceWrapper) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
@@ -785,6 +1044,9 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("GET "+options.BaseURL+"/animal-categories", wrapper.FindAnimalCategory)
 	m.HandleFunc("POST "+options.BaseURL+"/animal-categories", wrapper.AddAnimalCategory)
 	m.HandleFunc("PUT "+options.BaseURL+"/animal-categories/{animalCategoryId}", wrapper.ReplaceAnimalCategory)
+	m.HandleFunc("POST "+options.BaseURL+"/auth/login", wrapper.Login)
+	m.HandleFunc("POST "+options.BaseURL+"/auth/logout", wrapper.Logout)
+	m.HandleFunc("POST "+options.BaseURL+"/auth/refresh", wrapper.RefreshToken)
 	m.HandleFunc("DELETE "+options.BaseURL+"/images/{imageId}", wrapper.DeletePetImage)
 	m.HandleFunc("GET "+options.BaseURL+"/images/{imageId}", wrapper.GetImageByID)
 	m.HandleFunc("GET "+options.BaseURL+"/pets", wrapper.FindPets)
@@ -793,10 +1055,16 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("GET "+options.BaseURL+"/pets/{petId}", wrapper.GetPetByID)
 	m.HandleFunc("PUT "+options.BaseURL+"/pets/{petId}", wrapper.ReplacePet)
 	m.HandleFunc("POST "+options.BaseURL+"/pets/{petId}/images", wrapper.UploadPetImage)
+	m.HandleFunc("GET "+options.BaseURL+"/sellers/me/orders", wrapper.FindSellerOrders)
+	m.HandleFunc("GET "+options.BaseURL+"/store/cart", wrapper.ListCart)
+	m.HandleFunc("POST "+options.BaseURL+"/store/cart", wrapper.AddToCart)
+	m.HandleFunc("POST "+options.BaseURL+"/store/cart/checkout", wrapper.CheckoutCart)
+	m.HandleFunc("DELETE "+options.BaseURL+"/store/cart/{petId}", wrapper.RemoveFromCart)
 	m.HandleFunc("GET "+options.BaseURL+"/store/orders", wrapper.FindOrders)
 	m.HandleFunc("POST "+options.BaseURL+"/store/orders", wrapper.PlaceOrders)
 	m.HandleFunc("DELETE "+options.BaseURL+"/store/orders/{orderId}", wrapper.DeleteOrder)
 	m.HandleFunc("GET "+options.BaseURL+"/store/orders/{orderId}", wrapper.GetOrderByID)
+	m.HandleFunc("PATCH "+options.BaseURL+"/store/orders/{orderId}/status", wrapper.UpdateOrderStatus)
 	m.HandleFunc("DELETE "+options.BaseURL+"/users", wrapper.DeleteUser)
 	m.HandleFunc("GET "+options.BaseURL+"/users", wrapper.GetUser)
 	m.HandleFunc("PATCH "+options.BaseURL+"/users", wrapper.PatchUser)
@@ -810,6 +1078,12 @@ type AnimalCategoryJSONResponse struct {
 	Name AnimalCategoryName `json:"name"`
 }
 
+type CartJSONResponse struct {
+	// Count Total number of pets in cart
+	Count int        `json:"count"`
+	Items []CartItem `json:"items"`
+}
+
 type GenericJSONResponse struct {
 	Message string `json:"message"`
 }
@@ -827,6 +1101,18 @@ type OrderArrayJSONResponse struct {
 	Headers OrderArrayResponseHeaders
 }
 
+type TokenJSONResponse struct {
+	// AccessToken JWT access token to be used as bearer token
+	AccessToken string `json:"access_token"`
+
+	// ExpiresIn Lifetime of access token in seconds
+	ExpiresIn int `json:"expires_in"`
+
+	// RefreshToken Opaque token used to obtain new access tokens
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
 type UserJSONResponse UserSchema
 
 type FindAnimalCategoryRequestObject struct {
@@ -923,6 +1209,98 @@ func (response ReplaceAnimalCategorydefaultJSONResponse) VisitReplaceAnimalCateg
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type LoginRequestObject struct {
+	Body *LoginJSONRequestBody
+}
+
+type LoginResponseObject interface {
+	VisitLoginResponse(w http.ResponseWriter) error
+}
+
+type Login200JSONResponse struct{ TokenJSONResponse }
+
+func (response Login200JSONResponse) VisitLoginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type LogindefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response LogindefaultJSONResponse) VisitLoginResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type LogoutRequestObject struct {
+	Body *LogoutJSONRequestBody
+}
+
+type LogoutResponseObject interface {
+	VisitLogoutResponse(w http.ResponseWriter) error
+}
+
+type Logout204Response struct {
+}
+
+func (response Logout204Response) VisitLogoutResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type LogoutdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response LogoutdefaultJSONResponse) VisitLogoutResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type RefreshTokenRequestObject struct {
+	Body *RefreshTokenJSONRequestBody
+}
+
+type RefreshTokenResponseObject interface {
+	VisitRefreshTokenResponse(w http.ResponseWriter) error
+}
+
+type RefreshToken200JSONResponse struct{ TokenJSONResponse }
+
+func (response RefreshToken200JSONResponse) VisitRefreshTokenResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RefreshTokendefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response RefreshTokendefaultJSONResponse) VisitRefreshTokenResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type DeletePetImageRequestObject struct {
 	ImageId ImageId `json:"imageId"`
 }
@@ -955,6 +1333,7 @@ func (response DeletePetImagedefaultJSONResponse) VisitDeletePetImageResponse(w
 
 type GetImageByIDRequestObject struct {
 	ImageId ImageId `json:"imageId"`
+	Params  GetImageByIDParams
 }
 
 type GetImageByIDResponseObject interface {
@@ -1078,112 +1457,269 @@ type DeletePetResponseObject interface {
 type DeletePet204Response struct {
 }
 
-func (response DeletePet204Response) VisitDeletePetResponse(w http.ResponseWriter) error {
-	w.WriteHeader(204)
-	return nil
+func (response DeletePet204Response) VisitDeletePetResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type DeletePetdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response DeletePetdefaultJSONResponse) VisitDeletePetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type GetPetByIDRequestObject struct {
+	PetId PetId `json:"petId"`
+}
+
+type GetPetByIDResponseObject interface {
+	VisitGetPetByIDResponse(w http.ResponseWriter) error
+}
+
+type GetPetByID200JSONResponse PetWithMetadata
+
+func (response GetPetByID200JSONResponse) VisitGetPetByIDResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetPetByIDdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response GetPetByIDdefaultJSONResponse) VisitGetPetByIDResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ReplacePetRequestObject struct {
+	PetId PetId `json:"petId"`
+	Body  *multipart.Reader
+}
+
+type ReplacePetResponseObject interface {
+	VisitReplacePetResponse(w http.ResponseWriter) error
+}
+
+type ReplacePet202Response struct {
+}
+
+func (response ReplacePet202Response) VisitReplacePetResponse(w http.ResponseWriter) error {
+	w.WriteHeader(202)
+	return nil
+}
+
+type ReplacePetdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response ReplacePetdefaultJSONResponse) VisitReplacePetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type UploadPetImageRequestObject struct {
+	PetId PetId `json:"petId"`
+	Body  *multipart.Reader
+}
+
+type UploadPetImageResponseObject interface {
+	VisitUploadPetImageResponse(w http.ResponseWriter) error
+}
+
+type UploadPetImage201JSONResponse struct {
+	PhotoIds []string `json:"photo_ids"`
+}
+
+func (response UploadPetImage201JSONResponse) VisitUploadPetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UploadPetImagedefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response UploadPetImagedefaultJSONResponse) VisitUploadPetImageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type FindSellerOrdersRequestObject struct {
+	Params FindSellerOrdersParams
+}
+
+type FindSellerOrdersResponseObject interface {
+	VisitFindSellerOrdersResponse(w http.ResponseWriter) error
+}
+
+type FindSellerOrders200JSONResponse struct{ OrderArrayJSONResponse }
+
+func (response FindSellerOrders200JSONResponse) VisitFindSellerOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Next-Cursor", fmt.Sprint(response.Headers.XNextCursor))
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type FindSellerOrdersdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response FindSellerOrdersdefaultJSONResponse) VisitFindSellerOrdersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListCartRequestObject struct {
+}
+
+type ListCartResponseObject interface {
+	VisitListCartResponse(w http.ResponseWriter) error
+}
+
+type ListCart200JSONResponse struct{ CartJSONResponse }
+
+func (response ListCart200JSONResponse) VisitListCartResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type DeletePetdefaultJSONResponse struct {
+type ListCartdefaultJSONResponse struct {
 	Body struct {
 		Message string `json:"message"`
 	}
 	StatusCode int
 }
 
-func (response DeletePetdefaultJSONResponse) VisitDeletePetResponse(w http.ResponseWriter) error {
+func (response ListCartdefaultJSONResponse) VisitListCartResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.StatusCode)
 
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
-type GetPetByIDRequestObject struct {
-	PetId PetId `json:"petId"`
+type AddToCartRequestObject struct {
+	Body *AddToCartJSONRequestBody
 }
 
-type GetPetByIDResponseObject interface {
-	VisitGetPetByIDResponse(w http.ResponseWriter) error
+type AddToCartResponseObject interface {
+	VisitAddToCartResponse(w http.ResponseWriter) error
 }
 
-type GetPetByID200JSONResponse PetWithMetadata
+type AddToCart201JSONResponse CartItem
 
-func (response GetPetByID200JSONResponse) VisitGetPetByIDResponse(w http.ResponseWriter) error {
+func (response AddToCart201JSONResponse) VisitAddToCartResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(200)
+	w.WriteHeader(201)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type GetPetByIDdefaultJSONResponse struct {
+type AddToCartdefaultJSONResponse struct {
 	Body struct {
 		Message string `json:"message"`
 	}
 	StatusCode int
 }
 
-func (response GetPetByIDdefaultJSONResponse) VisitGetPetByIDResponse(w http.ResponseWriter) error {
+func (response AddToCartdefaultJSONResponse) VisitAddToCartResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.StatusCode)
 
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
-type ReplacePetRequestObject struct {
-	PetId PetId `json:"petId"`
-	Body  *multipart.Reader
+type CheckoutCartRequestObject struct {
 }
 
-type ReplacePetResponseObject interface {
-	VisitReplacePetResponse(w http.ResponseWriter) error
+type CheckoutCartResponseObject interface {
+	VisitCheckoutCartResponse(w http.ResponseWriter) error
 }
 
-type ReplacePet202Response struct {
-}
+type CheckoutCart201JSONResponse struct{ OrderArrayJSONResponse }
 
-func (response ReplacePet202Response) VisitReplacePetResponse(w http.ResponseWriter) error {
-	w.WriteHeader(202)
-	return nil
+func (response CheckoutCart201JSONResponse) VisitCheckoutCartResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Next-Cursor", fmt.Sprint(response.Headers.XNextCursor))
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response.Body)
 }
 
-type ReplacePetdefaultJSONResponse struct {
+type CheckoutCartdefaultJSONResponse struct {
 	Body struct {
 		Message string `json:"message"`
 	}
 	StatusCode int
 }
 
-func (response ReplacePetdefaultJSONResponse) VisitReplacePetResponse(w http.ResponseWriter) error {
+func (response CheckoutCartdefaultJSONResponse) VisitCheckoutCartResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.StatusCode)
 
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
-type UploadPetImageRequestObject struct {
+type RemoveFromCartRequestObject struct {
 	PetId PetId `json:"petId"`
-	Body  *multipart.Reader
 }
 
-type UploadPetImageResponseObject interface {
-	VisitUploadPetImageResponse(w http.ResponseWriter) error
+type RemoveFromCartResponseObject interface {
+	VisitRemoveFromCartResponse(w http.ResponseWriter) error
 }
 
-type UploadPetImage202Response struct {
+type RemoveFromCart204Response struct {
 }
 
-func (response UploadPetImage202Response) VisitUploadPetImageResponse(w http.ResponseWriter) error {
-	w.WriteHeader(202)
+func (response RemoveFromCart204Response) VisitRemoveFromCartResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
 	return nil
 }
 
-type UploadPetImagedefaultJSONResponse struct {
+type RemoveFromCartdefaultJSONResponse struct {
 	Body struct {
 		Message string `json:"message"`
 	}
 	StatusCode int
 }
 
-func (response UploadPetImagedefaultJSONResponse) VisitUploadPetImageResponse(w http.ResponseWriter) error {
+func (response RemoveFromCartdefaultJSONResponse) VisitRemoveFromCartResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.StatusCode)
 
@@ -1315,6 +1851,38 @@ func (response GetOrderByIDdefaultJSONResponse) VisitGetOrderByIDResponse(w http
 	return json.NewEncoder(w).Encode(response.Body)
 }
 
+type UpdateOrderStatusRequestObject struct {
+	OrderId OrderId `json:"orderId"`
+	Body    *UpdateOrderStatusJSONRequestBody
+}
+
+type UpdateOrderStatusResponseObject interface {
+	VisitUpdateOrderStatusResponse(w http.ResponseWriter) error
+}
+
+type UpdateOrderStatus200JSONResponse Order
+
+func (response UpdateOrderStatus200JSONResponse) VisitUpdateOrderStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type UpdateOrderStatusdefaultJSONResponse struct {
+	Body struct {
+		Message string `json:"message"`
+	}
+	StatusCode int
+}
+
+func (response UpdateOrderStatusdefaultJSONResponse) VisitUpdateOrderStatusResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
 type DeleteUserRequestObject struct {
 }
 
@@ -1447,6 +2015,15 @@ type StrictServerInterface interface {
 	// Replace existing animal-category data using Id.
 	// (PUT /animal-categories/{animalCategoryId})
 	ReplaceAnimalCategory(ctx context.Context, request ReplaceAnimalCategoryRequestObject) (ReplaceAnimalCategoryResponseObject, error)
+	// Login using username & password.
+	// (POST /auth/login)
+	Login(ctx context.Context, request LoginRequestObject) (LoginResponseObject, error)
+	// Revoke refresh token.
+	// (POST /auth/logout)
+	Logout(ctx context.Context, request LogoutRequestObject) (LogoutResponseObject, error)
+	// Exchange refresh token for new tokens.
+	// (POST /auth/refresh)
+	RefreshToken(ctx context.Context, request RefreshTokenRequestObject) (RefreshTokenResponseObject, error)
 	// Delete a pet image.
 	// (DELETE /images/{imageId})
 	DeletePetImage(ctx context.Context, request DeletePetImageRequestObject) (DeletePetImageResponseObject, error)
@@ -1471,6 +2048,21 @@ type StrictServerInterface interface {
 	// Upload a new image for a pet.
 	// (POST /pets/{petId}/images)
 	UploadPetImage(ctx context.Context, request UploadPetImageRequestObject) (UploadPetImageResponseObject, error)
+	// Find orders placed against pets of the user.
+	// (GET /sellers/me/orders)
+	FindSellerOrders(ctx context.Context, request FindSellerOrdersRequestObject) (FindSellerOrdersResponseObject, error)
+	// List pets held in user cart.
+	// (GET /store/cart)
+	ListCart(ctx context.Context, request ListCartRequestObject) (ListCartResponseObject, error)
+	// Add pet to user cart.
+	// (POST /store/cart)
+	AddToCart(ctx context.Context, request AddToCartRequestObject) (AddToCartResponseObject, error)
+	// Checkout user cart.
+	// (POST /store/cart/checkout)
+	CheckoutCart(ctx context.Context, request CheckoutCartRequestObject) (CheckoutCartResponseObject, error)
+	// Remove pet from user cart.
+	// (DELETE /store/cart/{petId})
+	RemoveFromCart(ctx context.Context, request RemoveFromCartRequestObject) (RemoveFromCartResponseObject, error)
 	// Find user orders using status.
 	// (GET /store/orders)
 	FindOrders(ctx context.Context, request FindOrdersRequestObject) (FindOrdersResponseObject, error)
@@ -1483,6 +2075,9 @@ type StrictServerInterface interface {
 	// Find user order by ID.
 	// (GET /store/orders/{orderId})
 	GetOrderByID(ctx context.Context, request GetOrderByIDRequestObject) (GetOrderByIDResponseObject, error)
+	// Advance order fulfilment.
+	// (PATCH /store/orders/{orderId}/status)
+	UpdateOrderStatus(ctx context.Context, request UpdateOrderStatusRequestObject) (UpdateOrderStatusResponseObject, error)
 	// Delete user resource.
 	// (DELETE /users)
 	DeleteUser(ctx context.Context, request DeleteUserRequestObject) (DeleteUserResponseObject, error)
@@ -1616,6 +2211,99 @@ func (sh *strictHandler) ReplaceAnimalCategory(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// Login operation middleware
+func (sh *strictHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request LoginRequestObject
+
+	var body LoginJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Login(ctx, request.(LoginRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Login")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(LoginResponseObject); ok {
+		if err := validResponse.VisitLoginResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Logout operation middleware
+func (sh *strictHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var request LogoutRequestObject
+
+	var body LogoutJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Logout(ctx, request.(LogoutRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Logout")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(LogoutResponseObject); ok {
+		if err := validResponse.VisitLogoutResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RefreshToken operation middleware
+func (sh *strictHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var request RefreshTokenRequestObject
+
+	var body RefreshTokenJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RefreshToken(ctx, request.(RefreshTokenRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RefreshToken")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RefreshTokenResponseObject); ok {
+		if err := validResponse.VisitRefreshTokenResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // DeletePetImage operation middleware
 func (sh *strictHandler) DeletePetImage(w http.ResponseWriter, r *http.Request, imageId ImageId) {
 	var request DeletePetImageRequestObject
@@ -1643,10 +2331,11 @@ func (sh *strictHandler) DeletePetImage(w http.ResponseWriter, r *http.Request,
 }
 
 // GetImageByID operation middleware
-func (sh *strictHandler) GetImageByID(w http.ResponseWriter, r *http.Request, imageId ImageId) {
+func (sh *strictHandler) GetImageByID(w http.ResponseWriter, r *http.Request, imageId ImageId, params GetImageByIDParams) {
 	var request GetImageByIDRequestObject
 
 	request.ImageId = imageId
+	request.Params = params
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.GetImageByID(ctx, request.(GetImageByIDRequestObject))
@@ -1843,6 +2532,137 @@ func (sh *strictHandler) UploadPetImage(w http.ResponseWriter, r *http.Request,
 	}
 }
 
+// FindSellerOrders operation middleware
+func (sh *strictHandler) FindSellerOrders(w http.ResponseWriter, r *http.Request, params FindSellerOrdersParams) {
+	var request FindSellerOrdersRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.FindSellerOrders(ctx, request.(FindSellerOrdersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "FindSellerOrders")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(FindSellerOrdersResponseObject); ok {
+		if err := validResponse.VisitFindSellerOrdersResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListCart operation middleware
+func (sh *strictHandler) ListCart(w http.ResponseWriter, r *http.Request) {
+	var request ListCartRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ListCart(ctx, request.(ListCartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListCart")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ListCartResponseObject); ok {
+		if err := validResponse.VisitListCartResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// AddToCart operation middleware
+func (sh *strictHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
+	var request AddToCartRequestObject
+
+	var body AddToCartJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.AddToCart(ctx, request.(AddToCartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AddToCart")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(AddToCartResponseObject); ok {
+		if err := validResponse.VisitAddToCartResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// CheckoutCart operation middleware
+func (sh *strictHandler) CheckoutCart(w http.ResponseWriter, r *http.Request) {
+	var request CheckoutCartRequestObject
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.CheckoutCart(ctx, request.(CheckoutCartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CheckoutCart")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(CheckoutCartResponseObject); ok {
+		if err := validResponse.VisitCheckoutCartResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RemoveFromCart operation middleware
+func (sh *strictHandler) RemoveFromCart(w http.ResponseWriter, r *http.Request, petId PetId) {
+	var request RemoveFromCartRequestObject
+
+	request.PetId = petId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.RemoveFromCart(ctx, request.(RemoveFromCartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RemoveFromCart")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(RemoveFromCartResponseObject); ok {
+		if err := validResponse.VisitRemoveFromCartResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // FindOrders operation middleware
 func (sh *strictHandler) FindOrders(w http.ResponseWriter, r *http.Request, params FindOrdersParams) {
 	var request FindOrdersRequestObject
@@ -1952,6 +2772,39 @@ func (sh *strictHandler) GetOrderByID(w http.ResponseWriter, r *http.Request, or
 	}
 }
 
+// UpdateOrderStatus operation middleware
+func (sh *strictHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request, orderId OrderId) {
+	var request UpdateOrderStatusRequestObject
+
+	request.OrderId = orderId
+
+	var body UpdateOrderStatusJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateOrderStatus(ctx, request.(UpdateOrderStatusRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateOrderStatus")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(UpdateOrderStatusResponseObject); ok {
+		if err := validResponse.VisitUpdateOrderStatusResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // DeleteUser operation middleware
 func (sh *strictHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	var request DeleteUserRequestObject
@@ -18,55 +18,80 @@ import (
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9RbaXMbN9L+KyjEb5VjD8VDIhPz/ZCVrbJX2ThmmXJlayWtCxo0SSRzGcDIklXc376F",
-	"Y25wOBTpOPtFpZnB0Re6n240H7Afh0kcQSQFnj7ghHASggSun16lXMRc/UdB+JwlksURntr3aBFzlJAl",
-	"i4h6j54ueByihMMti1OBOIgkjgR8jz3M1KxPKfB77OGIhICn2DeLe1j4KwiJ2iUkd79AtJQrPJ2ceDhk",
-	"UfY49LC8T9Q0ITmLlni99vB5SJZwTtVMvUFC5KpYn9mvHubwKWUcKJ5KnkJ5wyccFniKv+sXUuibr6J/",
-	"TvUmv7CQyaYIfk3DG+AoXiAmIRRIxoiDTHm0gdtAL1Pem8KCpIHE09HAU5yzMA3xdDzQfJuH4SBnm0US",
-	"lsA1Se84Bb6R79h+3ZPvGciNWyT6214brM1sEPJlTBloczuNWEiCV0TCMub36o0fRxIiLX6SJAHztaX1",
-	"fxdKBw+l/RIeJ8ClXciQ2U5FdbNf1YyMKMPSpVnmOldBfPM7+NLQXjUGsxbKFkNmJJIrIlEEQLV53AAi",
-	"lAJV/8sVICFjDg4h3vWWcc8KukrkeyMwbLRTk0+YBpIlhMv+IuZhjxKpBQORH1N1YJSQKpMuDFcNua49",
-	"nKxiGYvmWH2k+r8nsMQehrskiCkYspVMNmnDbtumDMVOZd8tg2dmYF1haqd8FbfiqtJee/iDAL6TqZEg",
-	"eLfA08t2IvWya68hCyLE55jTrTxm4xosZh+a7F07GKyakyIqNyI91rjow54+Rrt4AO9Qp5TRzDF1Oarz",
-	"1PdBiEUaoPqpzaShaHsDEXDm7yGFEIQgS81gM3KV6c8GdqHekoVOZ+fofYlcHRFOOSf76M2P08gR6S5i",
-	"SQIU5fFOxxehHQAJkwDKwTmPUh62w5Q9qAC5TdGaAzXPrkQ0M3VZGRLzxXdUuN4D6ZULZXt4BSQj9Z+9",
-	"X+FO9jrgHuXBI7iTCgABehqlQYDYAkUxCmMO+q34vhLv1RByE0B2MOtWsX6MM9ruglolooZk0aow/9yX",
-	"G79AKQeh/801joejY/SWsAjNpYfmiWJhwSCgHvowP8VeGcmNLKTJnseeQhMSuKLl35envX+R3pdB74V3",
-	"ddVD18+f4IZoPOxwAxVyaLwUtV2rmx5XNyW9Ly27vU6DoLmHjKN7JCThf1R3mgzqSLX7TgZhFXsQIuhk",
-	"IunKnxyP5Li60cl4CyS2fqB5sikE7BY40I+USGja9Vn2HZ0RCRcshKcfLl4p81VYgkglYSKhJ1moDswW",
-	"S/Y6R4AE5MeuY8WKJclGDubm64HoF5LItJvLmpuhzqBk2cvXqzHh1fXSdGdWo/OcnjxpwElAfKC4fri1",
-	"Z0RmwyPlpCOVSFwWwxMeq9OvOM3pKZOCPeyTyIcggDLIKIQzK0GYwnI/8+OTk5Pll5MnJ7ws9xyvVEz5",
-	"uHpmftzgEq7SwWA0+b8n3/3t2fT/1cOxr//CfzacJwuLa3GtBGl2xRrdUMoMZDY84cx32OdMvUYsQh/m",
-	"Z0fobSqkygeSWDDJbgF9ZnKFQnKHRoiCr2GJVphRYS7k0YujFy8qVm0GV4U7bHNIV1f0+dOrq6OrK/ow",
-	"9Ebr739yCrLbCZiBzOzfw5Isu0y4UMOcmZZXaCqTo+tEZMKumF9I7g7n/4sEo6lHkEhnQQI9TROVyg0H",
-	"usCRYZzq8LcsQoJ9gSkavvTQW3Jnn0bjwT9eVlR7wyLCVXxRRCAOCQcBkTSVlXiBVNJldi7r38xy8RCS",
-	"u3NDkzWH7KmGsDycRuxTCvazTYoKxVZ8DrklzHjOuttJQFqno4y8nN5mDqg8V8TBBt9i7aOMGgsZBeSG",
-	"E6prRpvN/aRVGONWYRgKfmNy9RYkyVLoIuF7XJ6j89GPjFbZqsrv/EwpOcmsq2IadEKPJ3RyMhbjiUvV",
-	"rYjZBKKcAseB6pCZXyvBrOIITNWrqpXnw954PO4NR8e9k/Hkh5pyJq2+6PlPl4Pei971ww/ecLx2HsYM",
-	"EldlTwpQ2urS7bC1hxdpEHzs4sxz9GdUF8HHKOe6VVIlAa09nArgXbb7kI2ray5foEx7jSQvF0RDsc3M",
-	"f26yBivTqOFD4z/IkocsGo6OW33puOFLVai+6n08cjtUFU7ATzmT95oEo8EbIBz4aapWzJ5eZ37t598u",
-	"Gi7m598u0Es9DMn4D4iy5Ep7T/2+2HolZWJSHxYtYkdOu2ICMYEIEpp7pPz6XHksNAd+CxzdEAEUxcaX",
-	"vUsgUin38dEAiQR8trAZmQrPkkktvvlnslwCV0tp34d65XnYw7fAhdl+eDQ4Gk10lpxARBKGp/j4aHB0",
-	"grVoV1pAfaLxSM8GRWv4S3Ck6K9ZRJEfxAKE7IVE+isVRKrz7xWt6ghpwlXioafVqj5e5QbgslH1JiEo",
-	"R2WWRqV47ap5W4t9XJXYWfm5rtWsRoPBpnXycbWVsM6HbUTbNjUrA2kbTsNQBduO4sYZIrrETUUqh5rE",
-	"wqHKU0pRBJ/rq1WKxk1NntKmIovy+v1mRksVeIecarIefgNZd5VHm6zXnuMw9R9IhdZzutZhJnUo5T1o",
-	"NI7gjgnp0DVSUAGlKqlC57SpHjt/21lz3LTUadzv0uX6a1jFtziBuytkm30YUN9/sLeGa1s2gQ3lEpCA",
-	"SIHXmio3Y2Ygzy2eq+naxXUxpJ9dbTo83klLVoIMyfQAInYzmYnRiEv7MWdEegOyPDVTxVlTUG+siF7e",
-	"n58dUkyDWh21dHdVqaB+rRSste5avmrYU09tkm5oS1l6AnILklAIxq6j3JBnMzsPqfWO0LNnpzIAIiSK",
-	"I40GlCMulKJwVeajnj1zgg61QVeoYe70WuBFN/+Xl2hUwlPTi8lbb0mQgigube2drR9HglFdHF3EHC1Y",
-	"IDXSrFx/ugksyn45iZ3uQqpllVp214CyZKmvlw1h6Oa+G23aNHYQXla22Xok7d1Jh5Gms6LD0f0Kl1iJ",
-	"scD2K6zspHRVWqVw0PUqS2+y40WW8vd/5WusQ+DrdidUcm5GgFsBtfKQW0H0TDubnTGSbV+omfHIBST1",
-	"NHTq+5AcJk638VcTUeb9+w+6gaczxmlBNztHbNNW1B3WfB1A47QfZ0B8r5u6dKWARcsA3PJ4A3IG8lEQ",
-	"ZqNABge7CG64pq3OpXElfJgzrWz05r4GTorz2yn1Umt0S7f2Nc//ISfQTUpb3IHNgXRMdbrSD0kQE4qI",
-	"9jcGbaoYssFHmNGPzoA2amLHnrfDtrLt26C2uTWtk+lkJoNKrYB7ms42pTqMRseXftHgszmTSHV/iR5o",
-	"LbG4mW6mBe+yzqLWxOAvB9crfQDbAftrA9StVMy9PCIL9U6umEB5p0LWw+CiVY8/M10EBbnObodGNf5P",
-	"g/Dt5ljqWTtQeGmztsyMs86xjXhxph2pOgx2pfJ9ZtNu9fDccDe7qvbondtZo3P2nHbs1671wZ5T5+X5",
-	"PtfCzvbZ3UvEB9W70ZbVlP49Aki3vuuOq/9gG+VrMNgFdE1b1a4RLGvT7wZ2TXPiweFucSYU8mIUIskW",
-	"DPiGM2FdeQPdauoehW9bpHA4hGu7Rzt0fx4a0lblW0W2FdtTA8V2U9PX3V3sRbdufh1z4SDilPuVFNKQ",
-	"31rvTQ1FkrBAOFMkN28dQoVtpj9EqVQR6eYrIdJfNdUyU69zyru49xD4Enp6uefbylV/Vh/Dzj872KP3",
-	"Yd3thxffzAy0RrUhuO3AwoKqGbziQEqnc8fcMKN892i5H8+27QJPL6/LEjDMbDwLtanVXo3La+XJhe6S",
-	"cMHzIPZJ0KNwqzAED2wzxrTf1x9WsZDTH48Hgz5JWP92qMPCXa9Uuyx+UPh3/fLb1zPX/w0AAP//3HA9",
-	"TSU5AAA=",
+	"H4sIAAAAAAAC/+w8a3PbOJJ/BcWdq8qDsiS/duKrqzknucx5L5m4/Ki52tjnhYmWhAkJcADQj7h8v32r",
+	"AfBNSZQlT3ar9ktikXg0+t2Nbj4EkUxSKUAYHRw8BClVNAEDyv56lyktFf7FQEeKp4ZLERz452QiFUnp",
+	"lAuKz8mLiZIJSRXccJlpokCnUmh4GYQBx1m/Z6DugzAQNIHgIIjc4mGgoxkkFHdJ6N1HEFMzCw72d8Mg",
+	"4SL/OQ4Dc5/iNG0UF9Pg8TEMjhI6hSOGM+0GKTWzcn3u34aBgt8zroAFB0ZlUN3wBwWT4CD407DEwtC9",
+	"1cMjZjf5yBNu2ij4JUuuQRE5IdxAoomRRIHJlJhz2tguU92bwYRmsQkOtkchnpwnWRIc7I3sud2P8ag4",
+	"NhcGpqAsSJ8VAzX33NK/XfPcx2DmbpGCWXeDRzcbtHkrGQfLboeCJzR+Rw1MpbrHJ5EUBoRFP03TmEeW",
+	"04a/aaTBQ2W/VMkUlPELOTAXQ1Hf7Bec8fhYPdIXt8xlQQJ5/RtExsFeZwa3FskXI24kMTNqiABglj2u",
+	"gVDGgOHfZgZEG6mgA4l3g6kceETXgTxxCAscdRr4SbLY8JQqM5xIlQwYNRYxICLJUGAQSbVJZ+5ULbw+",
+	"hkE6k0bq9lgrUsPfUpgGYQB3aSwZOLARJ/Oo4bddRAw8Tm3fJYOP3cAmwXCnYpVuwtWx/RgGJzBRoGdn",
+	"8iuINVhOuWWuTL7Oirqseoz6Wn3OUeea6pEqPHOuQa10QhrHnyfBwZfF5LDLPoYtqlOtb6ViS6mZj2sR",
+	"M3/RRsDlUhQgUMXR7VhnjDarZzjro+vCTekjznIV3EcpnWZRBFpPspg09VOODYTtHVVmDRREMhMd5vFM",
+	"GhoTURjJFIwmXJAId0PdQZM0hqosFAYuDKxFtfjN/1iEODzAkYEkeCzWokrR+xb6HKj58iuiEHep4e1n",
+	"EKB4tAbqEtCaTi1jLNYH+cA+IHuwyOHxETmpgGt9hkOLl2cntvVA9FIy+2F96WxP0JvIfvEVqWz3IHbl",
+	"kthhMAOag/q/g1/gzgx6eMZo4wXcGXSRgbwQWRwTPiFCkkQqsE/1y5pHiEPodQy5QmtyBYK+rp2i9qyl",
+	"maqD/5dfz4gbQewI77dkGhihmlwDVaDcq6AFn3UJuAJ9xTuW/sgnYHgCyCC1LbggGiIpWI1j3oxGXTzT",
+	"MrP1TT6n9PcM/MIWaiOJvDaUCyLgtrax7jqBfXPlHj+U0ARv7cmDZZa7ht3aajXkhL1M/FwutTxQU0Yr",
+	"m/blBn0hBDgk93JLOAof0FlZxhRoXcfjeHuHfEJqnJqQnKaIxAmHmIXk/PQwCKte07YPhfLfe2GQUmNA",
+	"ISz/9+Vw8Fc6+DYavAkvLgbk8vUPXeTsMKo1cJic6sau9U136pvSwbcFu72jSnHo0gruBWEQ8xvA4S4C",
+	"mPE0AWG2qowfnB+fBuFi93GJogiDwia25H8GMbvKhOFxG8z31MAZT+DF+dm7l4Qacjvj0YzMZMyIFBbk",
+	"FAzxjByEAUYa1CAeqYEBSncXWlIwV/08pbY7f2V9ngrQbVEJgw9ZHLdpa6S4J9pQ9bWBzlETnf0pfMTq",
+	"e1Cq2f6+YbNof2fb7NU32t1b4vb7DMYp/wa1hEAgFcesShw0RfAEBOP4t9Wilho2JtsiOqFxTC6y0Wh7",
+	"nyTAeJYQqoDoiMbACJO3VpmPt39M7/Jhe+Pt9C6nbCzFFLQhmjMI3aqa0FgBZfdudUCbRgWhRBVg2C1A",
+	"3QBDPZ7DTTT/BparBaYxvgR2PmLHAmbNsz/hZQdWnKVvex+leC3xB+0wq7+svAG7QgbtYPj8Pamx/jzO",
+	"Xip2feOB/hIRBqniUQfox/gYcX5++r6QzFuqnfOF5rqhVrbfbL15UzsZRKgcu9gcFVM6F2un7u2GcKYN",
+	"NVk/5+/UDUUzrWj0lYvplXM6l80+88Nd0q47riqUjUN4AVgDGy2m6lJIVWhrgp3GNALWEmtLM+I2rIpN",
+	"MTxVMgKtEWUFPFVQgjCIqIggjoF1itRxJRoveeJW7ezu7k6/7f6wq6oELELvmj7bqSvOH+fYY6dc/u2H",
+	"P/3nq4N/xx87kf0X/n+OUvW5rKawl9H5qmFzv4D7GEw+vIeMbZFPmTboDKdSc8NvgNxyMyMJvSPbxMsS",
+	"sQTTvQWvgtzxIqt0ccFev7i42Lq4YA/jcPvx5U+diOwnSsdgKoJEp30mnOGwzvRoWFIqx2OXROTIrrFf",
+	"Qu8253yVWcE2HXMzqcmLLLVmcPQyqOQZ6sM/YUTCv8EBGb8NySd6539t743+522NtNdcUHVPHBBEQapA",
+	"gzA0t9CYKXU7V+nvZnWdIaF3Rw4mzw75r0bQGwaZ4L9n4F/7TGZJ2JrOoTeUOxXcVDtoMhzPIJNXc9K5",
+	"AqrO1TKeo1s8f1QD+RJHMb1WlElVp3SD3XcXImNvITIcBL9yM/sEhuZ57zJ3+bSUnU0iX3FWP1Ydf0fv",
+	"fWqroHF5bLbPdvbZ/u6e3tvvDDQbR0jn8O75yUeNuxQul67tqcOCdBRja5e/0KQEP+yXXbGyc65ivTTD",
+	"4oxkZf25+fawR9L/Esk3kwJ+KSx5icTX48He3t5gvL0z2N3b/3ODhfYXaszXP30ZDd4MLh/+HI73HrtV",
+	"RnHmjtycdVRr0Axpyoc346FD/LBK4Z9QQ/xH4d0Wop4pPlAwAQUi6oyNCj94tZ0qAUL/vZwTvtpGuePe",
+	"d5cGr/Tx+0sj0XDT2nlG/76SapwfRo//+ubNm8PD8Wi8vYPM8+Pu2lF1nmVpZNTKPMdCR8UPewyDSRbH",
+	"V31clCKwdQpCQE9/typQSBEZQ5+kzwmOewyDTIPqA955Pq5J9mKB6lkbRwgLxLVYoX2Vc2p3zGlw4s9T",
+	"2rgo00YmdtVGwCxjyPkEodoin1NQ1ClSBUjbyLg0oU4h4hMeEUSXRpcupgpwZkIydL3J3+4G+SkHdtDf",
+	"CNwZEJpLsUUoS7iwk0lEhZDWWdQQTwZUaz4VwKqufQVijW67Q0jCRaeJPa9QpGRx+ZVOVcLFeHtnoR+1",
+	"1/Kj0E2/GFxtdTtTqCsgyhQ39xbxjs9d8vcwM7Py14dcLfzl17Mg7Egmv61njC3nWM+pkU+dGZO6nCMX",
+	"E9kh+jOuCdeEop1LYyDo050aqYCcYvpBkWuqoUhTfU5B4A3IztaooKslOpLAcGPRd3pLp1NQuJT1e8ig",
+	"Oi8IgxtQ2m0/3hptbe9bhZ2CoCkPDoKdrdEW6hQsjbAIGlIbiwy8Q+zVwxQ6bkw+cMFIFEsN2gwSaqIZ",
+	"8ld9/j3CKnNuPWJ+WuPyMqyV7HxplalQn3G3s0jFV+8qUvFy+rSyjs4LzMvG1ev2aDRvnWJcYyWXyPGS",
+	"vmxqfitneThLEnS0e6I7yKOhL0GbkOimpFJ3kPKQMXe1UF+tVuXRpuQhaxOyrIe5n3/QSslMB54auB5/",
+	"B1z3xccCXKP+rytaO9BpR9ytLWnDB1o7yBF7tJY666DYCdgwncAd16aDEQjGEF7lH7E27fz8ZYLYUTfV",
+	"hHG9EqrL52CZ7yGeqxNkPebJzGwYy6m7peyW6v+6i2ZUTJ3TQCIFDIThNNb2apcSPZPKDDD7xuq3mT61",
+	"Tom/4XOP20z00e7fouATb3VXL7bZrJe3oFans+hqdaZz995P5jXvzgQHXy6rnGep4DkrP05OwvxMNX5D",
+	"76fORTIz89noBG7kV9BW89UYglC8b3EpRLgBde8fc60zYMRW0hZRveXVThbCzZ+gBWo1b21y7Hbc3svp",
+	"FN2rzGycBg5HbXmZh3M/cAHSpaGmC+lb5Ngl6JyOKW63lJ3AGhRSnnZPJU8NyZsn0neTmUI11tGFehHt",
+	"vv2l5xDQ5xkefG32oyNeDHMu58BA7ZazhWM3BkuVfQKuYYO7TlsOGeYF5I+XfWSgSCMTBzLbgOnrPmSO",
+	"OfvAOZ+dYcTPYKpTcxP5vo2onz2K3t4fvX86msKHRffRKBAODiMJwtsdZGBeKejt6BQX5HNjiYrJrNQn",
+	"14zlc2XsF9bIVOtz1uSSRXRu8QrKWQpmSfCJQa9fB+kS+ouAkOB6W+TVq0MTA9WGSFEkUEqWwFA8N+qv",
+	"XnXGqbhB3+g0ncss9r++zFLc6LUZ1V2MkBsaZ6DLwnxf3xZJobm7Nkc1NuGxAdUscZ/DzcUtcQFiv3x7",
+	"7RaukW9vZT/o1LYQOMDI9X0/2CxrrIC8/JZvqULw1Y89RrrumR6i+0w1x0uLUHNJ6Uu02j1T32JUu8mK",
+	"RX5obf6RC1E3kZJZrIQqys0hcGkOBjXk0rzLMTzJZ/YtKg023u7y+e00chhFkG7GS1h0vgaKcu0/fLBN",
+	"Wr09rAW+1cr+gmsd6+9UPY871ck/nQbxxDbu2eQyF9MYuvHxM5hjME9yoOYiZLSxot2WalqqXFrlu5uR",
+	"aeTR6/uGc1LKb6+EHK7RLwm3Lnv+EymBflhaog58BDY/eD5PY0kZoVbf2ME+49UpE270k+OvuZRYsa9x",
+	"s+2K6zYhzm8/nJOhf2rS7w8tUek6pd28j19z5MuJq/2vyFDkEP/D+1IyozdAqCGJ1IaMR8Qz6vpys4yj",
+	"OyTG3cjqYQLDsklpfizlxrjiP4bKT5oZKJtQ1IROKRfauA64mGvjhhQX0p3B06kF4HPeR7UwiPqHC22a",
+	"tbpLgpsPLqipI5FOjC0355oU1cR5nXEXrHb8e1egW4Lbpz/hDwx3FnNvpUNvQ6a4gdIqI1ZLIioykHfN",
+	"OSkwUsEw8v2hnez/kecLYosG4cLfl1Bltpx3fzuTGlwLyYxq3zvCbN+AkMYLREdum2vzzvWKro5JO3F9",
+	"HC46XAVn+HtJbOL99gpuznzFPveL53GaHeLUk/1iBMoxx8iIZTZj5TpyqvrF15pYyhGOKpWhXfT5ojzF",
+	"7YsEI9fFKuBWE44HkzHrDJLOZIH9jdxP5R+TWL0D6Oipl0rjjfnWZYfx8hZh1JCb9Kk72afNfXV5HUYz",
+	"iL4uvJk6tr6k1xCW4eJ4DrPnF2KQpOa+YKM227zzm3bL7fgP1oA5NKugrUfMfAKJvHGya2+BWlhSEAPV",
+	"sEC83BIflEw8pp43ulZ2O39pFW1GNc7FwkIc9/Gl7EqeLV1cU7bFtP2kf3lI//KQ1vGQFnFbyycKF6pS",
+	"jC78StVmijbf2uEF4z7VvhZ89ryGdr2elN5m+g+ke8vwpWD0Eh/YPRo++E9rNQxEV9rUgryyas8/7NVP",
+	"udvRm0+eljKBcSq3hU8TPidOKDKqrVyphe5J2dIFWNhcvtQRqM/XQDadIK3jt54n7cF7w7LLL8VK1o7+",
+	"NTTNVPg9jCwvgCZZPOFxAiLv+9oin0XsfDqX8yiuegFDClzFlbVjhiZL0UgUK2+RZj+G94B8n7adI3F5",
+	"WwePXxMApxh9rKKJ72TtyiviVlUzuR4TbSKGWb39XFe68aoEapGhghX0qzQxstYGvKz7t9UygEWQOHtw",
+	"Q21Nm2WuD8W2x9UFy8enxdLls/flJhvvu/b4WaNm8J9XFRyyGypyQ1QRzLnawEb7yw2P7VHqYz3O9bMZ",
+	"DwVaZiqqXU868BdWMmUOIkN5rDuv37rP1sNx9J+o20QZTjNjVp6r0MYNdw8fF5D3UUQJqCkM7HKv25xM",
+	"mau4ovFxRT1NaKyhs/mJaxLNpAaByXXsQ8rSUlHnbUquoo+FZAbIlNwmphSgNAKr1qpSUWS3xVeBHxXx",
+	"StJ2q3yfxrgn1D4/uZnu8bnqmzfEopbbLJN286gPYBqJGwXUQDePLr8TzSFf3a9f78xzylPdYebKaWNq",
+	"va3tyyW6C/Z7Np2JhFhGNB4wuAnCIFOx71s7GA7ti5nU5uDHndHId9Va3+NuUKnZKT+W/N/24fev43n8",
+	"+wDn5jNbAVoAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -13,6 +13,13 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// Defines values for ImageSize.
+const (
+	Medium   ImageSize = "medium"
+	Original ImageSize = "original"
+	Small    ImageSize = "small"
+)
+
 // Defines values for OrderStatus.
 const (
 	Cancelled  OrderStatus = "cancelled"
@@ -28,30 +35,66 @@ const (
 	Sold      PetStatus = "sold"
 )
 
+// Defines values for UserRole.
+const (
+	Admin    UserRole = "admin"
+	Customer UserRole = "customer"
+	Seller   UserRole = "seller"
+)
+
+// Defines values for UpdateOrderStatusJSONBodyStatus.
+const (
+	FulfilmentDelivered  UpdateOrderStatusJSONBodyStatus = "delivered"
+	FulfilmentProcessing UpdateOrderStatusJSONBodyStatus = "processing"
+	FulfilmentShipped    UpdateOrderStatusJSONBodyStatus = "shipped"
+)
+
 // Address defines model for Address.
 type Address = string
 
 // AnimalCategoryName defines model for AnimalCategoryName.
 type AnimalCategoryName = string
 
+// Carrier Carrier delivering the shipment.
+type Carrier = string
+
+// CartItem defines model for CartItem.
+type CartItem struct {
+	// HeldUntil DateTime(UTC) at which hold on the pet expires
+	HeldUntil time.Time `json:"held_until"`
+	PetId     Id        `json:"pet_id"`
+}
+
 // FullName defines model for FullName.
 type FullName = string
 
 // Id defines model for Id.
 type Id = string
 
+// ImageSize Rendition of a pet image. small & medium are scaled down to 128px & 512px on the longest side, images already smaller than a rendition are served in original size.
+type ImageSize string
+
 // Order defines model for Order.
 type Order struct {
+	// Carrier Carrier delivering the shipment.
+	Carrier *Carrier `json:"carrier"`
+
 	// DeliveredDate Delivered DateTime(UTC)
 	DeliveredDate *time.Time `json:"delivered_date"`
 	Id            Id         `json:"id"`
 	PetId         Id         `json:"pet_id"`
 
+	// Price Price in USD the pet was ordered at.
+	Price string `json:"price"`
+
 	// ShippedDate Shipped DateTime(UTC)
 	ShippedDate *time.Time `json:"shipped_date"`
 
 	// Status order status.
 	Status OrderStatus `json:"status"`
+
+	// TrackingNumber Tracking number of the shipment.
+	TrackingNumber *TrackingNumber `json:"tracking_number"`
 }
 
 // OrderStatus order status.
@@ -92,6 +135,9 @@ type PetWithMetadata struct {
 	Name     PetName            `json:"name"`
 	PhotoIds []string           `json:"photo_ids"`
 
+	// Photos URLs of renditions of pet images, in the same order as photo_ids
+	Photos []PhotoUrls `json:"photos"`
+
 	// Price Price in USD. Must be positive with max 2 decimal places.
 	Price string `json:"price"`
 
@@ -103,14 +149,30 @@ type PetWithMetadata struct {
 // PhoneNumber defines model for PhoneNumber.
 type PhoneNumber = string
 
+// PhotoUrls defines model for PhotoUrls.
+type PhotoUrls struct {
+	Medium   string `json:"medium"`
+	Original string `json:"original"`
+	Small    string `json:"small"`
+}
+
+// TrackingNumber Tracking number of the shipment.
+type TrackingNumber = string
+
 // UserSchema defines model for User.
 type UserSchema struct {
 	Address     Address     `json:"address"`
 	FullName    FullName    `json:"full_name"`
 	PhoneNumber PhoneNumber `json:"phone_number"`
-	Username    Username    `json:"username"`
+
+	// Role Role of the user. Operations restricted to specific roles declare them using `x-required-roles` extension. admin role cannot be self-assigned.
+	Role     *UserRole `json:"role,omitempty"`
+	Username Username  `json:"username"`
 }
 
+// UserRole Role of the user. Operations restricted to specific roles declare them using `x-required-roles` extension. admin role cannot be self-assigned.
+type UserRole string
+
 // Username defines model for Username.
 type Username = string
 
@@ -135,6 +197,13 @@ type AnimalCategory struct {
 	Name AnimalCategoryName `json:"name"`
 }
 
+// Cart defines model for Cart.
+type Cart struct {
+	// Count Total number of pets in cart
+	Count int        `json:"count"`
+	Items []CartItem `json:"items"`
+}
+
 // Generic defines model for Generic.
 type Generic struct {
 	Message string `json:"message"`
@@ -147,6 +216,19 @@ type OrderArray struct {
 	Orders []Order `json:"orders"`
 }
 
+// Token defines model for Token.
+type Token struct {
+	// AccessToken JWT access token to be used as bearer token
+	AccessToken string `json:"access_token"`
+
+	// ExpiresIn Lifetime of access token in seconds
+	ExpiresIn int `json:"expires_in"`
+
+	// RefreshToken Opaque token used to obtain new access tokens
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
 // User defines model for User.
 type User = UserSchema
 
@@ -155,13 +237,21 @@ type AnimalCategoryRequest struct {
 	Name AnimalCategoryName `json:"name"`
 }
 
+// RefreshTokenRequest defines model for RefreshToken.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // UserRequest defines model for User.
 type UserRequest struct {
 	Address     Address     `json:"address"`
 	FullName    FullName    `json:"full_name"`
 	Password    Password    `json:"password"`
 	PhoneNumber PhoneNumber `json:"phone_number"`
-	Username    Username    `json:"username"`
+
+	// Role Role of the user. Operations restricted to specific roles declare them using `x-required-roles` extension. admin role cannot be self-assigned.
+	Role     *UserRole `json:"role,omitempty"`
+	Username Username  `json:"username"`
 }
 
 // FindAnimalCategoryParams defines parameters for FindAnimalCategory.
@@ -180,6 +270,28 @@ type ReplaceAnimalCategoryJSONBody struct {
 	Name AnimalCategoryName `json:"name"`
 }
 
+// LoginJSONBody defines parameters for Login.
+type LoginJSONBody struct {
+	Password Password `json:"password"`
+	Username Username `json:"username"`
+}
+
+// LogoutJSONBody defines parameters for Logout.
+type LogoutJSONBody struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenJSONBody defines parameters for RefreshToken.
+type RefreshTokenJSONBody struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// GetImageByIDParams defines parameters for GetImageByID.
+type GetImageByIDParams struct {
+	// Size Rendition of the image to get
+	Size *ImageSize `form:"size,omitempty" json:"size,omitempty"`
+}
+
 // FindPetsParams defines parameters for FindPets.
 type FindPetsParams struct {
 	// Name Name of pet
@@ -220,6 +332,26 @@ type UploadPetImageMultipartBody struct {
 	Photos PetPhotos `json:"photos"`
 }
 
+// FindSellerOrdersParams defines parameters for FindSellerOrders.
+type FindSellerOrdersParams struct {
+	// Status Status values that need to be considered for filter
+	Status *[]OrderStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// AfterDate Filter orders placed after this date-time(UTC)
+	AfterDate *time.Time `form:"afterDate,omitempty" json:"afterDate,omitempty"`
+
+	// Cursor Cursor for pagination (from previous response)
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Number of items to return
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// AddToCartJSONBody defines parameters for AddToCart.
+type AddToCartJSONBody struct {
+	PetId Id `json:"petId"`
+}
+
 // FindOrdersParams defines parameters for FindOrders.
 type FindOrdersParams struct {
 	// Status Status values that need to be considered for filter
@@ -240,6 +372,21 @@ type PlaceOrdersJSONBody = []struct {
 	PetId Id `json:"petId"`
 }
 
+// UpdateOrderStatusJSONBody defines parameters for UpdateOrderStatus.
+type UpdateOrderStatusJSONBody struct {
+	// Carrier Carrier delivering the shipment.
+	Carrier *Carrier `json:"carrier"`
+
+	// Status fulfilment status the order moves to.
+	Status UpdateOrderStatusJSONBodyStatus `json:"status"`
+
+	// TrackingNumber Tracking number of the shipment.
+	TrackingNumber *TrackingNumber `json:"tracking_number"`
+}
+
+// UpdateOrderStatusJSONBodyStatus defines parameters for UpdateOrderStatus.
+type UpdateOrderStatusJSONBodyStatus string
+
 // PatchUserApplicationMergePatchPlusJSONBody defines parameters for PatchUser.
 type PatchUserApplicationMergePatchPlusJSONBody struct {
 	Address     *Address     `json:"address,omitempty"`
@@ -254,7 +401,10 @@ type CreateUserJSONBody struct {
 	FullName    FullName    `json:"full_name"`
 	Password    Password    `json:"password"`
 	PhoneNumber PhoneNumber `json:"phone_number"`
-	Username    Username    `json:"username"`
+
+	// Role Role of the user. Operations restricted to specific roles declare them using `x-required-roles` extension. admin role cannot be self-assigned.
+	Role     *UserRole `json:"role,omitempty"`
+	Username Username  `json:"username"`
 }
 
 // AddAnimalCategoryJSONRequestBody defines body for AddAnimalCategory for application/json ContentType.
@@ -263,6 +413,15 @@ type AddAnimalCategoryJSONRequestBody AddAnimalCategoryJSONBody
 // ReplaceAnimalCategoryJSONRequestBody defines body for ReplaceAnimalCategory for application/json ContentType.
 type ReplaceAnimalCategoryJSONRequestBody ReplaceAnimalCategoryJSONBody
 
+// LoginJSONRequestBody defines body for Login for application/json ContentType.
+type LoginJSONRequestBody LoginJSONBody
+
+// LogoutJSONRequestBody defines body for Logout for application/json ContentType.
+type LogoutJSONRequestBody LogoutJSONBody
+
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody RefreshTokenJSONBody
+
 // AddPetMultipartRequestBody defines body for AddPet for multipart/form-data ContentType.
 type AddPetMultipartRequestBody AddPetMultipartBody
 
@@ -272,9 +431,15 @@ type ReplacePetMultipartRequestBody ReplacePetMultipartBody
 // UploadPetImageMultipartRequestBody defines body for UploadPetImage for multipart/form-data ContentType.
 type UploadPetImageMultipartRequestBody UploadPetImageMultipartBody
 
+// AddToCartJSONRequestBody defines body for AddToCart for application/json ContentType.
+type AddToCartJSONRequestBody AddToCartJSONBody
+
 // PlaceOrdersJSONRequestBody defines body for PlaceOrders for application/json ContentType.
 type PlaceOrdersJSONRequestBody = PlaceOrdersJSONBody
 
+// UpdateOrderStatusJSONRequestBody defines body for UpdateOrderStatus for application/json ContentType.
+type UpdateOrderStatusJSONRequestBody UpdateOrderStatusJSONBody
+
 // PatchUserApplicationMergePatchPlusJSONRequestBody defines body for PatchUser for application/merge-patch+json ContentType.
 type PatchUserApplicationMergePatchPlusJSONRequestBody PatchUserApplicationMergePatchPlusJSONBody
 
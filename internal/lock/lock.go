@@ -0,0 +1,182 @@
+// Package lock provides distributed locks with expiring leases. Every acquisition of a key issues a
+// fencing token greater than the previous one, hence a holder whose lease expired & got taken over can be told apart
+package lock
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTTL        = 30 * time.Second
+	defaultMinBackoff = 50 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+
+	maxTrackedKeys = 2000 // Distinct keys of by-key metrics together, others are counted under otherKey
+	otherKey       = "other"
+)
+
+var (
+	ErrNotAcquired = errors.New("lock is held by another holder")
+	ErrLost        = errors.New("lease expired & was taken over")
+)
+
+// Metrics of every Locker in process, published with expvar as "locks"
+var (
+	Acquisitions     = new(expvar.Int)
+	Contentions      = new(expvar.Int) // Attempts finding lock held by another holder
+	WaitTime         = new(expvar.Int) // Nanoseconds spent in Lock waiting for other holders
+	ContentionsByKey = new(expvar.Map).Init()
+	WaitTimeByKey    = new(expvar.Map).Init()
+
+	trackedKeys atomic.Int64
+)
+
+func init() {
+	vars := expvar.NewMap("locks")
+	vars.Set("acquisitions", Acquisitions)
+	vars.Set("contentions", Contentions)
+	vars.Set("wait_time_ns", WaitTime)
+	vars.Set("contentions_by_key", ContentionsByKey)
+	vars.Set("wait_time_ns_by_key", WaitTimeByKey)
+}
+
+// Lease is a held lock on Key
+type Lease struct {
+	Key   string
+	Token int64 // Fencing token, increases on every acquisition of Key
+}
+
+type Locker interface {
+	// TryLock acquires lock on key without waiting. Returns [ErrNotAcquired] when held by another holder.
+	// Lock of a holder which neither renewed nor unlocked it within TTL can be taken over
+	TryLock(ctx context.Context, key string) (*Lease, error)
+
+	// Lock waits for lock on key with jittered exponential backoff until ctx is done
+	Lock(ctx context.Context, key string) (*Lease, error)
+
+	// Unlock releases lease. Returns [ErrLost] when it has been taken over
+	Unlock(ctx context.Context, l *Lease) error
+
+	// Renew extends lease by TTL. Returns [ErrLost] when it expired or has been taken over
+	Renew(ctx context.Context, l *Lease) error
+}
+
+type Options struct {
+	TTL        time.Duration // [DefaultTTL] when zero
+	MinBackoff time.Duration // First wait of Lock, doubled after every failed attempt upto MaxBackoff
+	MaxBackoff time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.TTL <= 0 {
+		o.TTL = DefaultTTL
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = defaultMinBackoff
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = max(defaultMaxBackoff, o.MinBackoff)
+	}
+	return o
+}
+
+// Do runs fn holding lock on key, waiting for it as Lock does. Lease is renewed every renewInterval
+// until fn returns. Context of fn is cancelled when lease is lost, in which case [ErrLost] is returned
+func Do(ctx context.Context, locker Locker, key string, renewInterval time.Duration,
+	fn func(ctx context.Context, l *Lease) error) error {
+	l, err := locker.Lock(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var renewer sync.WaitGroup
+	renewer.Go(func() {
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+				// Other errors are retried on next tick, lease stays valid until it expires
+				if errors.Is(locker.Renew(fnCtx, l), ErrLost) {
+					cancel(ErrLost)
+					return
+				}
+			}
+		}
+	})
+
+	err = fn(fnCtx, l)
+	cancel(nil)
+	renewer.Wait()
+
+	// Unlock runs even when ctx is done, otherwise others wait until lease expires
+	unlockCtx, cancelUnlock := context.WithTimeout(context.WithoutCancel(ctx), renewInterval)
+	defer cancelUnlock()
+	errS := locker.Unlock(unlockCtx, l)
+	if errors.Is(context.Cause(fnCtx), ErrLost) || errors.Is(errS, ErrLost) {
+		return ErrLost
+	}
+	return err
+}
+
+// lock retries tryLock with jittered exponential backoff until it acquires key or ctx is done
+func lock(ctx context.Context, opts Options, key string,
+	tryLock func(ctx context.Context, key string) (*Lease, error)) (*Lease, error) {
+	start := time.Now()
+	backoff := opts.MinBackoff
+	for contended := false; ; contended = true {
+		l, err := tryLock(ctx, key)
+		if !errors.Is(err, ErrNotAcquired) {
+			if contended {
+				observeWait(key, time.Since(start))
+			}
+			return l, err
+		}
+
+		// Half of backoff is random, so that waiting holders do not retry in lockstep
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			observeWait(key, time.Since(start))
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, opts.MaxBackoff)
+	}
+}
+
+// observeAttempt counts outcome of a TryLock
+func observeAttempt(key string, err error) {
+	switch {
+	case err == nil:
+		Acquisitions.Add(1)
+	case errors.Is(err, ErrNotAcquired):
+		Contentions.Add(1)
+		ContentionsByKey.Add(trackedKey(ContentionsByKey, key), 1)
+	}
+}
+
+func observeWait(key string, wait time.Duration) {
+	WaitTime.Add(int64(wait))
+	WaitTimeByKey.Add(trackedKey(WaitTimeByKey, key), int64(wait))
+}
+
+// trackedKey returns key under which metrics of key are counted in m
+func trackedKey(m *expvar.Map, key string) string {
+	if m.Get(key) == nil && trackedKeys.Add(1) > maxTrackedKeys {
+		return otherKey
+	}
+	return key
+}
@@ -0,0 +1,173 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTTL = 50 * time.Millisecond
+
+func TestMemory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, m *Memory)
+	}{
+		{
+			name: "held lock is not acquired",
+			run: func(t *testing.T, m *Memory) {
+				l := mustTryLock(t, m, "a")
+				if _, err := m.TryLock(t.Context(), "a"); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("TryLock() error = %v, want %v", err, ErrNotAcquired)
+				}
+				if err := m.Unlock(t.Context(), l); err != nil {
+					t.Fatalf("Unlock() error = %v", err)
+				}
+				if next := mustTryLock(t, m, "a"); next.Token <= l.Token {
+					t.Errorf("token %d after unlock should be greater than %d", next.Token, l.Token)
+				}
+			},
+		},
+		{
+			name: "expired lock is taken over",
+			run: func(t *testing.T, m *Memory) {
+				l := mustTryLock(t, m, "a")
+				time.Sleep(testTTL)
+				next := mustTryLock(t, m, "a")
+				if next.Token <= l.Token {
+					t.Errorf("token %d after takeover should be greater than %d", next.Token, l.Token)
+				}
+				if err := m.Renew(t.Context(), l); !errors.Is(err, ErrLost) {
+					t.Errorf("Renew() of taken over lease error = %v, want %v", err, ErrLost)
+				}
+				if err := m.Unlock(t.Context(), l); !errors.Is(err, ErrLost) {
+					t.Errorf("Unlock() of taken over lease error = %v, want %v", err, ErrLost)
+				}
+				if err := m.Renew(t.Context(), next); err != nil {
+					t.Errorf("Renew() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "renewed lock is not taken over",
+			run: func(t *testing.T, m *Memory) {
+				l := mustTryLock(t, m, "a")
+				for range 10 {
+					time.Sleep(testTTL / 5)
+					if err := m.Renew(t.Context(), l); err != nil {
+						t.Fatalf("Renew() error = %v", err)
+					}
+				}
+				if _, err := m.TryLock(t.Context(), "a"); !errors.Is(err, ErrNotAcquired) {
+					t.Fatalf("TryLock() error = %v, want %v", err, ErrNotAcquired)
+				}
+			},
+		},
+		{
+			name: "keys are independent",
+			run: func(t *testing.T, m *Memory) {
+				mustTryLock(t, m, "a")
+				mustTryLock(t, m, "b")
+			},
+		},
+		{
+			name: "lock waits for unlock",
+			run: func(t *testing.T, m *Memory) {
+				l := mustTryLock(t, m, "a")
+				go func() {
+					time.Sleep(testTTL / 5)
+					_ = m.Unlock(context.Background(), l)
+				}()
+				ctx, cancel := context.WithTimeout(t.Context(), 2*testTTL)
+				defer cancel()
+				if _, err := m.Lock(ctx, "a"); err != nil {
+					t.Fatalf("Lock() error = %v", err)
+				}
+			},
+		},
+		{
+			name: "lock gives up at deadline",
+			run: func(t *testing.T, m *Memory) {
+				mustTryLock(t, m, "a")
+				ctx, cancel := context.WithTimeout(t.Context(), testTTL/5)
+				defer cancel()
+				if _, err := m.Lock(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("Lock() error = %v, want %v", err, context.DeadlineExceeded)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.run(t, NewMemory(Options{TTL: testTTL, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}))
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lease is renewed & released", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMemory(Options{TTL: testTTL})
+		err := Do(t.Context(), m, "a", testTTL/5, func(ctx context.Context, _ *Lease) error {
+			time.Sleep(2 * testTTL)
+			return ctx.Err()
+		})
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		mustTryLock(t, m, "a")
+	})
+
+	t.Run("lost lease cancels fn", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMemory(Options{TTL: testTTL})
+		err := Do(t.Context(), m, "a", testTTL/5, func(ctx context.Context, l *Lease) error {
+			// Another holder takes over, as if this one stalled beyond TTL
+			m.mu.Lock()
+			m.locks[l.Key].token++
+			m.mu.Unlock()
+
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if !errors.Is(err, ErrLost) {
+			t.Fatalf("Do() error = %v, want %v", err, ErrLost)
+		}
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	key := t.Name()
+	m := NewMemory(Options{TTL: testTTL, MinBackoff: time.Millisecond})
+	acquisitions := Acquisitions.Value()
+	mustTryLock(t, m, key)
+	if got := Acquisitions.Value() - acquisitions; got < 1 {
+		t.Errorf("acquisitions increased by %d, want atleast 1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = m.Lock(ctx, key)
+	if ContentionsByKey.Get(key) == nil || WaitTimeByKey.Get(key) == nil {
+		t.Errorf("contention of %s is not tracked", key)
+	}
+}
+
+func mustTryLock(t *testing.T, m *Memory, key string) *Lease {
+	t.Helper()
+	l, err := m.TryLock(t.Context(), key)
+	if err != nil {
+		t.Fatalf("TryLock(%s) error = %v", key, err)
+	}
+	return l
+}
@@ -0,0 +1,80 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a [Locker] for holders within a single process
+type Memory struct {
+	opts Options
+
+	mu    sync.Mutex
+	locks map[string]*memoryLock // Entries are never removed, so that fencing tokens keep increasing
+}
+
+type memoryLock struct {
+	token       int64
+	lockedUntil time.Time // Zero when unlocked
+}
+
+func NewMemory(opts Options) *Memory {
+	return &Memory{
+		opts:  opts.withDefaults(),
+		locks: map[string]*memoryLock{},
+	}
+}
+
+func (m *Memory) TryLock(_ context.Context, key string) (*Lease, error) {
+	l, err := m.tryLock(key)
+	observeAttempt(key, err)
+	return l, err
+}
+
+func (m *Memory) tryLock(key string) (*Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := m.locks[key]
+	if !ok {
+		entry = &memoryLock{}
+		m.locks[key] = entry
+	}
+	if entry.lockedUntil.After(now) {
+		return nil, ErrNotAcquired
+	}
+	entry.token++
+	entry.lockedUntil = now.Add(m.opts.TTL)
+	return &Lease{Key: key, Token: entry.token}, nil
+}
+
+func (m *Memory) Lock(ctx context.Context, key string) (*Lease, error) {
+	return lock(ctx, m.opts, key, m.TryLock)
+}
+
+func (m *Memory) Unlock(_ context.Context, l *Lease) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.locks[l.Key]
+	if !ok || entry.token != l.Token {
+		return ErrLost
+	}
+	entry.lockedUntil = time.Time{}
+	return nil
+}
+
+func (m *Memory) Renew(_ context.Context, l *Lease) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := m.locks[l.Key]
+	if !ok || entry.token != l.Token || !entry.lockedUntil.After(now) {
+		return ErrLost
+	}
+	entry.lockedUntil = now.Add(m.opts.TTL)
+	return nil
+}
@@ -0,0 +1,132 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	iDField           string = "_id"
+	lockedUntilField  string = "locked_until"
+	fencingTokenField string = "fencing_token"
+)
+
+// Mongo is a [Locker] keeping a document per key. Documents are never deleted, so that fencing tokens
+// keep increasing. Renew within a transaction makes the transaction commit only while lease is held,
+// since a takeover conflicts with the write on lock document
+type Mongo struct {
+	collection *mongo.Collection
+	docID      DocIDFunc
+	opts       Options
+}
+
+// DocIDFunc maps a key to _id of its lock document. _id of a key must never change, otherwise the key
+// gets a new document whose fencing token starts over
+type DocIDFunc func(key string) (any, error)
+
+// ObjectIDKeys maps hex keys to ObjectID _id, for keys which are IDs of other documents
+func ObjectIDKeys(key string) (any, error) {
+	return bson.ObjectIDFromHex(key)
+}
+
+type mongoLock struct {
+	LockedUntil  *time.Time `bson:"locked_until"`  // "bsonType": ["date", "null"]
+	FencingToken int64      `bson:"fencing_token"` // "bsonType": "long"
+}
+
+// NewMongo returns a Mongo keeping lock documents in collection, with key itself as _id when docID is nil
+func NewMongo(collection *mongo.Collection, docID DocIDFunc, opts Options) *Mongo {
+	if docID == nil {
+		docID = func(key string) (any, error) { return key, nil }
+	}
+	return &Mongo{
+		collection: collection,
+		docID:      docID,
+		opts:       opts.withDefaults(),
+	}
+}
+
+func (m *Mongo) TryLock(ctx context.Context, key string) (*Lease, error) {
+	l, err := m.tryLock(ctx, key)
+	observeAttempt(key, err)
+	return l, err
+}
+
+func (m *Mongo) tryLock(ctx context.Context, key string) (*Lease, error) {
+	id, err := m.docID(key)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	var doc mongoLock
+	err = m.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			iDField: id,
+			"$or": []bson.M{
+				{lockedUntilField: bson.Null{}},
+				{lockedUntilField: bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{lockedUntilField: now.Add(m.opts.TTL)},
+			"$inc": bson.M{fencingTokenField: int64(1)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		// Document exists but is locked, upsert then fails as a duplicate _id
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrNotAcquired
+		}
+		return nil, err
+	}
+	return &Lease{Key: key, Token: doc.FencingToken}, nil
+}
+
+func (m *Mongo) Lock(ctx context.Context, key string) (*Lease, error) {
+	return lock(ctx, m.opts, key, m.TryLock)
+}
+
+func (m *Mongo) Unlock(ctx context.Context, l *Lease) error {
+	id, err := m.docID(l.Key)
+	if err != nil {
+		return err
+	}
+	res, err := m.collection.UpdateOne(
+		ctx,
+		bson.M{iDField: id, fencingTokenField: l.Token},
+		bson.M{"$set": bson.M{lockedUntilField: bson.Null{}}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrLost
+	}
+	return nil
+}
+
+func (m *Mongo) Renew(ctx context.Context, l *Lease) error {
+	id, err := m.docID(l.Key)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	res, err := m.collection.UpdateOne(
+		ctx,
+		bson.M{iDField: id, fencingTokenField: l.Token, lockedUntilField: bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{lockedUntilField: now.Add(m.opts.TTL)}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrLost
+	}
+	return nil
+}
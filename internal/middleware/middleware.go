@@ -8,13 +8,20 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/hlog"
 
 	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
 	genRouter "github.com/vrv501/simple-api/internal/generated/router"
 )
 
+const (
+	requiredRolesExtension = "x-required-roles"
+	errMsgInsufficientRole = "insufficient role to perform this operation"
+)
+
 func EntryAudit(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		hlog.FromRequest(r).Info().Msg("Entry Audit")
@@ -32,21 +39,49 @@ func PanicRecovery(h http.Handler) http.Handler {
 					Interface(zerolog.ErrorFieldName, err).
 					Str("stack_trace", string(stack)).
 					Msg("Recovered from panic")
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				jsonBody, _ := json.Marshal(
-					genRouter.Generic{
-						Message: http.StatusText(http.StatusInternalServerError),
-					},
-				)
-				w.Write(jsonBody)
-				w.Write([]byte("\n"))
+				writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 			}
 		}()
 		h.ServeHTTP(w, r)
 	})
 }
 
+// RequireRoles enforces roles declared on spec operations using x-required-roles extension.
+// Role is read from request context, hence it has to be executed after authentication
+func RequireRoles(spec *openapi3.T, basePath string) func(http.Handler) http.Handler {
+	// Routes are registered as "METHOD basePath/path" which is also the pattern matched by ServeMux
+	requiredRoles := make(map[string][]string)
+	for path, pathItem := range spec.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			roleList, ok := operation.Extensions[requiredRolesExtension].([]any)
+			if !ok {
+				continue
+			}
+			roles := make([]string, 0, len(roleList))
+			for _, role := range roleList {
+				if roleStr, isStr := role.(string); isStr {
+					roles = append(roles, roleStr)
+				}
+			}
+			requiredRoles[method+" "+basePath+path] = roles
+		}
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if roles, ok := requiredRoles[r.Pattern]; ok {
+				role, _ := contextKeys.UserRoleFromContext(r.Context())
+				if !slices.Contains(roles, role) {
+					hlog.FromRequest(r).Warn().Str("role", role).Msg("Insufficient role")
+					writeError(w, http.StatusForbidden, errMsgInsufficientRole)
+					return
+				}
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
 func WithCORS(h http.Handler) http.Handler {
 	allowedOriginList := []string{"http://localhost:8080"}
 	if allowedOrigins := os.Getenv(constants.AllowedOrigins); allowedOrigins != "" {
@@ -69,3 +104,15 @@ func WithCORS(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	jsonBody, _ := json.Marshal(
+		genRouter.Generic{
+			Message: message,
+		},
+	)
+	w.Write(jsonBody)
+	w.Write([]byte("\n"))
+}
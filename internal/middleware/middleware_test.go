@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/getkin/kin-openapi/openapi3"
+
 	"github.com/vrv501/simple-api/internal/constants"
+	contextKeys "github.com/vrv501/simple-api/internal/context-keys"
 )
 
 func TestEntryAudit(t *testing.T) {
@@ -115,3 +119,82 @@ func TestWithCORS(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireRoles(t *testing.T) {
+	t.Parallel()
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.4
+info:
+  title: test
+  version: 1.0.0
+paths:
+  "/categories/{id}":
+    get:
+      responses:
+        "200":
+          description: ok
+    put:
+      x-required-roles:
+        - admin
+        - seller
+      responses:
+        "200":
+          description: ok
+`))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	type args struct {
+		method string
+		role   string
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantStatusCode int
+	}{
+		{
+			name:           "operation without roles",
+			args:           args{method: http.MethodGet},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "missing role",
+			args:           args{method: http.MethodPut},
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "insufficient role",
+			args:           args{method: http.MethodPut, role: "customer"},
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "allowed role",
+			args:           args{method: http.MethodPut, role: "seller"},
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := RequireRoles(spec, "/api/v1")
+			mux := http.NewServeMux()
+			for _, method := range []string{http.MethodGet, http.MethodPut} {
+				mux.Handle(method+" /api/v1/categories/{id}", mw(http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusOK)
+					})))
+			}
+
+			req := httptest.NewRequest(tt.args.method, "/api/v1/categories/1", nil)
+			if tt.args.role != "" {
+				req = req.WithContext(contextKeys.ContextWithUserRole(context.Background(), tt.args.role))
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatusCode {
+				t.Errorf("RequireRoles() = %v, want %v", rr.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
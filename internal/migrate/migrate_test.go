@@ -0,0 +1,173 @@
+package migrate
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/vrv501/simple-api/infra/mongo/migrations"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	t.Parallel()
+
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("Load() returned no migrations")
+	}
+	for i, migration := range loaded {
+		if migration.Version != uint(i+1) {
+			t.Errorf("migration %d has version %d, want contiguous versions", i, migration.Version)
+		}
+		for _, file := range []string{migration.up, migration.down} {
+			if file == "" {
+				t.Errorf("migration %d_%s is missing up or down file", migration.Version, migration.Name)
+				continue
+			}
+			raw, errS := migrations.FS.ReadFile(file)
+			if errS != nil {
+				t.Fatalf("ReadFile(%s) error = %v", file, errS)
+			}
+			var commands []bson.D
+			if errS = bson.UnmarshalExtJSON(raw, true, &commands); errS != nil || len(commands) == 0 {
+				t.Errorf("%s should be a non empty array of commands, error = %v", file, errS)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []uint
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version & other files skipped",
+			fsys: fstest.MapFS{
+				"10_b.up.json":   {},
+				"2_a.up.json":    {},
+				"2_a.down.json":  {},
+				"migrations.go":  {},
+				"3_c.up.sql":     {},
+				"README.md":      {},
+				"10_b.down.json": {},
+			},
+			versions: []uint{2, 10},
+		},
+		{
+			name:    "missing up file",
+			fsys:    fstest.MapFS{"1_a.down.json": {}},
+			wantErr: true,
+		},
+		{
+			name:    "same version with different names",
+			fsys:    fstest.MapFS{"1_a.up.json": {}, "1_b.down.json": {}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate up file",
+			fsys:    fstest.MapFS{"1_a.up.json": {}, "01_a.up.json": {}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loaded, err := Load(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var versions []uint
+			for _, migration := range loaded {
+				versions = append(versions, migration.Version)
+			}
+			if !slices.Equal(versions, tt.versions) {
+				t.Errorf("Load() versions = %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	m := &Migrator{migrations: []Migration{
+		{Version: 1, up: "1.up", down: "1.down"},
+		{Version: 2, up: "2.up", down: "2.down"},
+		{Version: 5, up: "5.up"},
+		{Version: 6, up: "6.up", down: "6.down"},
+	}}
+	tests := []struct {
+		name    string
+		current int
+		target  int
+		want    []step
+		wantErr error
+	}{
+		{
+			name:    "up from nil version",
+			current: NilVersion,
+			target:  2,
+			want:    []step{{file: "1.up", version: 1}, {file: "2.up", version: 2}},
+		},
+		{
+			name:    "up skips gaps in versions",
+			current: 2,
+			target:  6,
+			want:    []step{{file: "5.up", version: 5}, {file: "6.up", version: 6}},
+		},
+		{
+			name:    "down to nil version",
+			current: 2,
+			target:  NilVersion,
+			want:    []step{{file: "2.down", version: 1}, {file: "1.down", version: NilVersion}},
+		},
+		{
+			name:    "no change",
+			current: 6,
+			target:  6,
+		},
+		{
+			name:    "down through migration without down file",
+			current: 6,
+			target:  2,
+			wantErr: ErrNoDown,
+		},
+		{
+			name:    "unknown target",
+			current: 1,
+			target:  3,
+			wantErr: ErrUnknownVersion,
+		},
+		{
+			name:    "unknown current",
+			current: 4,
+			target:  6,
+			wantErr: ErrUnknownVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := m.plan(tt.current, tt.target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("plan() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("plan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package orderstate
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+// transitions lists statuses an order can move to from each status.
+// Delivered & cancelled orders are final
+var transitions = map[genRouter.OrderStatus][]genRouter.OrderStatus{
+	genRouter.Placed:     {genRouter.Processing, genRouter.Cancelled},
+	genRouter.Processing: {genRouter.Shipped, genRouter.Cancelled},
+	genRouter.Shipped:    {genRouter.Delivered},
+}
+
+// TransitionError is returned when an order cannot move from its current status to the requested one
+type TransitionError struct {
+	From genRouter.OrderStatus
+	To   genRouter.OrderStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("order cannot move from %s to %s", e.From, e.To)
+}
+
+// Change describes fields of an order to be updated when it moves to Status.
+// Date fields are nil when the transition doesn't stamp them
+type Change struct {
+	From          genRouter.OrderStatus
+	Status        genRouter.OrderStatus
+	ChangedOn     time.Time
+	ShippedDate   *time.Time
+	DeliveredDate *time.Time
+}
+
+func CanTransition(from, to genRouter.OrderStatus) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// Transition validates moving an order from one status to another at given time
+// & returns the change to be applied to the order
+func Transition(from, to genRouter.OrderStatus, at time.Time) (*Change, error) {
+	if !CanTransition(from, to) {
+		return nil, &TransitionError{From: from, To: to}
+	}
+
+	change := &Change{From: from, Status: to, ChangedOn: at}
+	if to == genRouter.Shipped {
+		change.ShippedDate = &at
+	}
+	if to == genRouter.Delivered {
+		change.DeliveredDate = &at
+	}
+	return change, nil
+}
@@ -0,0 +1,104 @@
+package orderstate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func TestTransition(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2025, 8, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    genRouter.OrderStatus
+		to      genRouter.OrderStatus
+		want    *Change
+		wantErr bool
+	}{
+		{
+			name: "placed to processing",
+			from: genRouter.Placed,
+			to:   genRouter.Processing,
+			want: &Change{From: genRouter.Placed, Status: genRouter.Processing, ChangedOn: at},
+		},
+		{
+			name: "placed to cancelled",
+			from: genRouter.Placed,
+			to:   genRouter.Cancelled,
+			want: &Change{From: genRouter.Placed, Status: genRouter.Cancelled, ChangedOn: at},
+		},
+		{
+			name: "processing to cancelled",
+			from: genRouter.Processing,
+			to:   genRouter.Cancelled,
+			want: &Change{From: genRouter.Processing, Status: genRouter.Cancelled, ChangedOn: at},
+		},
+		{
+			name: "processing to shipped stamps shipped date",
+			from: genRouter.Processing,
+			to:   genRouter.Shipped,
+			want: &Change{From: genRouter.Processing, Status: genRouter.Shipped, ChangedOn: at, ShippedDate: &at},
+		},
+		{
+			name: "shipped to delivered stamps delivered date",
+			from: genRouter.Shipped,
+			to:   genRouter.Delivered,
+			want: &Change{From: genRouter.Shipped, Status: genRouter.Delivered, ChangedOn: at, DeliveredDate: &at},
+		},
+		{
+			name:    "placed to shipped skips processing",
+			from:    genRouter.Placed,
+			to:      genRouter.Shipped,
+			wantErr: true,
+		},
+		{
+			name:    "shipped cannot be cancelled",
+			from:    genRouter.Shipped,
+			to:      genRouter.Cancelled,
+			wantErr: true,
+		},
+		{
+			name:    "delivered is final",
+			from:    genRouter.Delivered,
+			to:      genRouter.Processing,
+			wantErr: true,
+		},
+		{
+			name:    "cancelled is final",
+			from:    genRouter.Cancelled,
+			to:      genRouter.Placed,
+			wantErr: true,
+		},
+		{
+			name:    "same status",
+			from:    genRouter.Processing,
+			to:      genRouter.Processing,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Transition(tt.from, tt.to, at)
+			if tt.wantErr {
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) || transitionErr.From != tt.from || transitionErr.To != tt.to {
+					t.Errorf("Transition() error = %v, want TransitionError from %s to %s", err, tt.from, tt.to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transition() unexpected error = %v", err)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("Transition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
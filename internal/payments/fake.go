@@ -0,0 +1,116 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const FakeProviderName = "fake"
+
+// Outcome of a call to the fake provider
+type Outcome int
+
+const (
+	Succeed Outcome = iota
+	Decline
+	Timeout
+)
+
+// Fake is an in-process provider for local development & tests.
+// Calls succeed unless scripted otherwise & intent IDs are sequential, hence deterministic
+type Fake struct {
+	mu      sync.Mutex
+	seq     int
+	script  []Outcome
+	intents map[string]*Intent
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		intents: make(map[string]*Intent),
+	}
+}
+
+// Script queues outcomes for upcoming Authorize, Capture & Refund calls, which consume them in order.
+// Declined & timed out calls leave intents untouched
+func (f *Fake) Script(outcomes ...Outcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, outcomes...)
+}
+
+func (f *Fake) Name() string {
+	return FakeProviderName
+}
+
+func (f *Fake) Authorize(ctx context.Context, req AuthorizeRequest) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	f.seq++
+	intent := &Intent{
+		ID:       fmt.Sprintf("fake_pi_%06d", f.seq),
+		Provider: FakeProviderName,
+		Status:   StatusAuthorized,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}
+	f.intents[intent.ID] = intent
+	res := *intent
+	return &res, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, intentID string) (*Intent, error) {
+	return f.move(ctx, intentID, StatusAuthorized, StatusCaptured)
+}
+
+func (f *Fake) Refund(ctx context.Context, intentID string) (*Intent, error) {
+	return f.move(ctx, intentID, "", StatusRefunded)
+}
+
+// move changes status of an intent to the given one. When from is set, the intent must be in it
+func (f *Fake) move(ctx context.Context, intentID string, from, to Status) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status == to || (from != "" && intent.Status != from) {
+		return nil, ErrInvalidState
+	}
+	intent.Status = to
+	res := *intent
+	return &res, nil
+}
+
+// next consumes the upcoming scripted outcome
+func (f *Fake) next(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(f.script) == 0 {
+		return nil
+	}
+	outcome := f.script[0]
+	f.script = f.script[1:]
+	switch outcome {
+	case Decline:
+		return ErrDeclined
+	case Timeout:
+		return ErrTimeout
+	case Succeed:
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	req := AuthorizeRequest{OrderID: "1", Amount: 2999, Currency: "USD"}
+
+	tests := []struct {
+		name     string
+		outcomes []Outcome
+		run      func(f *Fake) (*Intent, error)
+		want     *Intent
+		wantErr  error
+	}{
+		{
+			name: "authorize",
+			run: func(f *Fake) (*Intent, error) {
+				return f.Authorize(ctx, req)
+			},
+			want: &Intent{ID: "fake_pi_000001", Provider: FakeProviderName, Status: StatusAuthorized, Amount: 2999,
+				Currency: "USD"},
+		},
+		{
+			name:     "authorize declined",
+			outcomes: []Outcome{Decline},
+			run: func(f *Fake) (*Intent, error) {
+				return f.Authorize(ctx, req)
+			},
+			wantErr: ErrDeclined,
+		},
+		{
+			name:     "authorize timed out",
+			outcomes: []Outcome{Timeout},
+			run: func(f *Fake) (*Intent, error) {
+				return f.Authorize(ctx, req)
+			},
+			wantErr: ErrTimeout,
+		},
+		{
+			name: "capture",
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				return f.Capture(ctx, intent.ID)
+			},
+			want: &Intent{ID: "fake_pi_000001", Provider: FakeProviderName, Status: StatusCaptured, Amount: 2999,
+				Currency: "USD"},
+		},
+		{
+			name:     "capture declined after authorize",
+			outcomes: []Outcome{Succeed, Decline},
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				return f.Capture(ctx, intent.ID)
+			},
+			wantErr: ErrDeclined,
+		},
+		{
+			name: "capture twice",
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				_, _ = f.Capture(ctx, intent.ID)
+				return f.Capture(ctx, intent.ID)
+			},
+			wantErr: ErrInvalidState,
+		},
+		{
+			name: "refund captured intent",
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				_, _ = f.Capture(ctx, intent.ID)
+				return f.Refund(ctx, intent.ID)
+			},
+			want: &Intent{ID: "fake_pi_000001", Provider: FakeProviderName, Status: StatusRefunded, Amount: 2999,
+				Currency: "USD"},
+		},
+		{
+			name: "refund releases authorization",
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				return f.Refund(ctx, intent.ID)
+			},
+			want: &Intent{ID: "fake_pi_000001", Provider: FakeProviderName, Status: StatusRefunded, Amount: 2999,
+				Currency: "USD"},
+		},
+		{
+			name: "refund twice",
+			run: func(f *Fake) (*Intent, error) {
+				intent, _ := f.Authorize(ctx, req)
+				_, _ = f.Refund(ctx, intent.ID)
+				return f.Refund(ctx, intent.ID)
+			},
+			wantErr: ErrInvalidState,
+		},
+		{
+			name: "refund unknown intent",
+			run: func(f *Fake) (*Intent, error) {
+				return f.Refund(ctx, "fake_pi_000001")
+			},
+			wantErr: ErrIntentNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := NewFake()
+			f.Script(tt.outcomes...)
+			got, err := tt.run(f)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Fake error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("Fake = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		amount  string
+		want    int64
+		wantErr bool
+	}{
+		{amount: "29.99", want: 2999},
+		{amount: "29.9", want: 2990},
+		{amount: "29", want: 2900},
+		{amount: "0.05", want: 5},
+		{amount: "29.999", wantErr: true},
+		{amount: "-1", wantErr: true},
+		{amount: ".5", wantErr: true},
+		{amount: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseAmount(tt.amount)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseAmount() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vrv501/simple-api/internal/constants"
+)
+
+var (
+	ErrDeclined       = errors.New("payment declined")
+	ErrTimeout        = errors.New("payment provider timed out")
+	ErrIntentNotFound = errors.New("payment intent not found")
+	ErrInvalidState   = errors.New("payment intent cannot be moved to requested state")
+	ErrInvalidAmount  = errors.New("invalid amount")
+)
+
+type Status string
+
+const (
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusRefunded   Status = "refunded"
+)
+
+// Intent is the state of a payment for an order as tracked by a provider.
+// Amount is in minor units of Currency, i.e. cents for USD
+type Intent struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Status   Status `json:"status"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type AuthorizeRequest struct {
+	OrderID  string
+	Amount   int64
+	Currency string
+}
+
+// Provider is a payment gateway. Implementations must be safe for concurrent use
+type Provider interface {
+	Name() string
+	// Authorize reserves amount on the payer's instrument without moving funds
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Intent, error)
+	// Capture moves funds reserved by an authorized intent
+	Capture(ctx context.Context, intentID string) (*Intent, error)
+	// Refund returns funds of a captured intent or releases reservation of an authorized one
+	Refund(ctx context.Context, intentID string) (*Intent, error)
+}
+
+// NewProviderFromEnv creates the provider named by PAYMENT_PROVIDER, defaults to the fake provider
+func NewProviderFromEnv() (Provider, error) {
+	switch name := os.Getenv(constants.PaymentProvider); name {
+	case "", FakeProviderName:
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unsupported %s %q", constants.PaymentProvider, name)
+	}
+}
+
+// ParseAmount converts a decimal amount with at most 2 decimal places like "29.99" to minor units
+func ParseAmount(amount string) (int64, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" || len(frac) > 2 || strings.ContainsAny(amount, "+-") {
+		return 0, ErrInvalidAmount
+	}
+	units, err := strconv.ParseInt(whole+frac+strings.Repeat("0", 2-len(frac)), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	return units, nil
+}
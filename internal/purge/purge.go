@@ -0,0 +1,226 @@
+// Package purge hard-deletes Mongo documents which were soft-deleted longer than a retention window ago
+package purge
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/vrv501/simple-api/internal/blobstore"
+)
+
+const (
+	DefaultRetention = 30 * 24 * time.Hour
+	DefaultBatchSize = 500
+
+	usersCollection         string = "users"
+	petsCollection          string = "pets"
+	imagesCollection        string = "images"
+	cartItemsCollection     string = "cart_items"
+	refreshTokensCollection string = "refresh_tokens"
+
+	iDField         string = "_id"
+	userIDField     string = "user_id"
+	petIDField      string = "pet_id"
+	deletedOnField  string = "deleted_on"
+	blobKeyField    string = "blob_key"
+	renditionsField string = "renditions"
+
+	inOperator  string = "$in"
+	lteOperator string = "$lte"
+	gtOperator  string = "$gt"
+)
+
+type Options struct {
+	Retention time.Duration // Documents soft-deleted before now minus Retention are purged
+	BatchSize int           // Documents deleted by a single command
+	DryRun    bool          // Only count documents which would be purged
+}
+
+// Summary is count of documents purged per collection. On a dry run documents reached by a cascade
+// as well as on their own are counted twice
+type Summary map[string]int64
+
+// Purger needs find & remove privileges, i.e. purgerRole created by mongo-admin.sh.
+// Orders are kept as history of sales, hence they can refer to purged users & pets
+type Purger struct {
+	collection func(name string) collection
+	blobs      blobstore.Store // Blobs of purged images are deleted along with them
+	opts       Options
+}
+
+// collection is satisfied by [mongo.Collection], purger needs nothing else of it
+type collection interface {
+	Find(ctx context.Context, filter any, opts ...options.Lister[options.FindOptions]) (*mongo.Cursor, error)
+	CountDocuments(ctx context.Context, filter any, opts ...options.Lister[options.CountOptions]) (int64, error)
+	DeleteMany(ctx context.Context, filter any,
+		opts ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error)
+}
+
+func New(db *mongo.Database, blobs blobstore.Store, opts Options) *Purger {
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Purger{
+		collection: func(name string) collection { return db.Collection(name) },
+		blobs:      blobs,
+		opts:       opts,
+	}
+}
+
+// Run purges users cascading to their pets, images, refresh tokens & cart items, followed by remaining
+// pets cascading to their images & cart items, followed by remaining images. Children are deleted before
+// their parent, so that a run stopped midway leaves no orphans behind & the next run picks up from there
+func (p *Purger) Run(ctx context.Context) (Summary, error) {
+	summary := Summary{}
+	deletedBefore := bson.M{deletedOnField: bson.M{lteOperator: time.Now().Add(-p.opts.Retention).UTC()}}
+
+	err := p.batches(ctx, usersCollection, deletedBefore, func(userIDs []bson.ObjectID) error {
+		return p.purgeUsers(ctx, userIDs, summary)
+	})
+	if err != nil {
+		return summary, err
+	}
+	err = p.batches(ctx, petsCollection, deletedBefore, func(petIDs []bson.ObjectID) error {
+		return p.purgePets(ctx, petIDs, summary)
+	})
+	if err != nil {
+		return summary, err
+	}
+	err = p.purgeImages(ctx, deletedBefore, summary)
+	return summary, err
+}
+
+// purgeUsers purges every pet of userIDs, not only soft-deleted ones. Pets which are not soft-deleted, such as
+// sold ones, are meant to be removed along with their owner, orders keep history of the sales
+func (p *Purger) purgeUsers(ctx context.Context, userIDs []bson.ObjectID, summary Summary) error {
+	ofUsers := bson.M{userIDField: bson.M{inOperator: userIDs}}
+	err := p.batches(ctx, petsCollection, ofUsers, func(petIDs []bson.ObjectID) error {
+		return p.purgePets(ctx, petIDs, summary)
+	})
+	if err != nil {
+		return err
+	}
+	if err = p.purgeImages(ctx, ofUsers, summary); err != nil {
+		return err
+	}
+	for _, collection := range []string{cartItemsCollection, refreshTokensCollection} {
+		if err = p.remove(ctx, collection, ofUsers, summary); err != nil {
+			return err
+		}
+	}
+	return p.remove(ctx, usersCollection, bson.M{iDField: bson.M{inOperator: userIDs}}, summary)
+}
+
+func (p *Purger) purgePets(ctx context.Context, petIDs []bson.ObjectID, summary Summary) error {
+	ofPets := bson.M{petIDField: bson.M{inOperator: petIDs}}
+	if err := p.purgeImages(ctx, ofPets, summary); err != nil {
+		return err
+	}
+	if err := p.remove(ctx, cartItemsCollection, ofPets, summary); err != nil {
+		return err
+	}
+	return p.remove(ctx, petsCollection, bson.M{iDField: bson.M{inOperator: petIDs}}, summary)
+}
+
+// purgeImages deletes blobs of images matching filter before the images themselves, so that a blob is
+// never left behind without an image referring to it. A blob already deleted by a stopped run is skipped
+func (p *Purger) purgeImages(ctx context.Context, filter bson.M, summary Summary) error {
+	return p.batches(ctx, imagesCollection, filter, func(imageIDs []bson.ObjectID) error {
+		ofImages := bson.M{iDField: bson.M{inOperator: imageIDs}}
+		if !p.opts.DryRun {
+			cursor, err := p.collection(imagesCollection).Find(ctx, ofImages,
+				options.Find().SetProjection(bson.M{blobKeyField: 1, renditionsField: 1}))
+			if err != nil {
+				return err
+			}
+			var docs []struct {
+				BlobKey    string            `bson:"blob_key"`
+				Renditions map[string]string `bson:"renditions"`
+			}
+			if err = cursor.All(ctx, &docs); err != nil {
+				return err
+			}
+			for i := range docs {
+				keys := slices.Collect(maps.Values(docs[i].Renditions))
+				if docs[i].BlobKey != "" {
+					keys = append(keys, docs[i].BlobKey)
+				}
+				for _, key := range keys {
+					if err = p.blobs.Delete(ctx, key); err != nil {
+						return fmt.Errorf("failed to purge blob %s: %w", key, err)
+					}
+				}
+			}
+		}
+		return p.remove(ctx, imagesCollection, ofImages, summary)
+	})
+}
+
+// batches calls fn with IDs of documents matching filter, atmost BatchSize at a time in increasing order.
+// IDs are paged by keyset, hence documents need not be deleted by fn for progress, as on a dry run
+func (p *Purger) batches(ctx context.Context, collection string, filter bson.M,
+	fn func(ids []bson.ObjectID) error) error {
+	pageFilter := maps.Clone(filter)
+	for {
+		cursor, err := p.collection(collection).Find(
+			ctx,
+			pageFilter,
+			options.Find().
+				SetSort(bson.M{iDField: 1}).
+				SetLimit(int64(p.opts.BatchSize)).
+				SetProjection(bson.M{iDField: 1}),
+		)
+		if err != nil {
+			return err
+		}
+		var docs []struct {
+			ID bson.ObjectID `bson:"_id"`
+		}
+		if err = cursor.All(ctx, &docs); err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		ids := make([]bson.ObjectID, len(docs))
+		for i := range docs {
+			ids[i] = docs[i].ID
+		}
+		if err = fn(ids); err != nil {
+			return err
+		}
+		if len(docs) < p.opts.BatchSize {
+			return nil
+		}
+		pageFilter[iDField] = bson.M{gtOperator: ids[len(ids)-1]}
+	}
+}
+
+// remove deletes documents matching filter, or counts them on a dry run
+func (p *Purger) remove(ctx context.Context, collection string, filter bson.M, summary Summary) error {
+	if p.opts.DryRun {
+		n, err := p.collection(collection).CountDocuments(ctx, filter)
+		summary[collection] += n
+		return err
+	}
+
+	res, err := p.collection(collection).DeleteMany(ctx, filter)
+	if res != nil {
+		summary[collection] += res.DeletedCount
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge %s: %w", collection, err)
+	}
+	return nil
+}
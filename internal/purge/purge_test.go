@@ -0,0 +1,337 @@
+package purge
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/vrv501/simple-api/internal/blobstore"
+)
+
+// fakeDB keeps documents in memory & understands only filters, operators & options used by [Purger]
+type fakeDB struct {
+	docs    map[string][]bson.M
+	deletes []string // Collections in order of DeleteMany calls which deleted anything
+}
+
+type fakeCollection struct {
+	db   *fakeDB
+	name string
+}
+
+func (f *fakeDB) collection(name string) collection {
+	return &fakeCollection{db: f, name: name}
+}
+
+func (c *fakeCollection) Find(_ context.Context, filter any,
+	opts ...options.Lister[options.FindOptions]) (*mongo.Cursor, error) {
+	var findOpts options.FindOptions
+	for _, opt := range opts {
+		for _, set := range opt.List() {
+			if err := set(&findOpts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Purger always sorts by _id, if at all
+	var docs []any
+	for _, doc := range c.sorted() {
+		if findOpts.Limit != nil && int64(len(docs)) == *findOpts.Limit {
+			break
+		}
+		if matches(doc, filter) {
+			docs = append(docs, doc)
+		}
+	}
+	return mongo.NewCursorFromDocuments(docs, nil, nil)
+}
+
+func (c *fakeCollection) CountDocuments(_ context.Context, filter any,
+	_ ...options.Lister[options.CountOptions]) (int64, error) {
+	var n int64
+	for _, doc := range c.db.docs[c.name] {
+		if matches(doc, filter) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (c *fakeCollection) DeleteMany(_ context.Context, filter any,
+	_ ...options.Lister[options.DeleteManyOptions]) (*mongo.DeleteResult, error) {
+	docs := c.db.docs[c.name]
+	left := slices.DeleteFunc(slices.Clone(docs), func(doc bson.M) bool { return matches(doc, filter) })
+	c.db.docs[c.name] = left
+	n := int64(len(docs) - len(left))
+	if n > 0 {
+		c.db.deletes = append(c.db.deletes, c.name)
+	}
+	return &mongo.DeleteResult{DeletedCount: n}, nil
+}
+
+func (c *fakeCollection) sorted() []bson.M {
+	docs := slices.Clone(c.db.docs[c.name])
+	sort.Slice(docs, func(i, j int) bool {
+		a, b := docs[i][iDField].(bson.ObjectID), docs[j][iDField].(bson.ObjectID)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	return docs
+}
+
+func matches(doc bson.M, filter any) bool {
+	for field, cond := range filter.(bson.M) {
+		value, ok := doc[field]
+		ops, isOp := cond.(bson.M)
+		if !isOp {
+			if !ok || !reflect.DeepEqual(value, cond) {
+				return false
+			}
+			continue
+		}
+		for op, arg := range ops {
+			switch op {
+			case inOperator:
+				id, isID := value.(bson.ObjectID)
+				if !isID || !slices.Contains(arg.([]bson.ObjectID), id) {
+					return false
+				}
+			case lteOperator:
+				t, isTime := value.(time.Time)
+				if !isTime || t.After(arg.(time.Time)) {
+					return false
+				}
+			case gtOperator:
+				id, isID := value.(bson.ObjectID)
+				after := arg.(bson.ObjectID)
+				if !isID || bytes.Compare(id[:], after[:]) <= 0 {
+					return false
+				}
+			default:
+				panic("unsupported operator " + op)
+			}
+		}
+	}
+	return true
+}
+
+type world struct {
+	docs  map[string][]bson.M
+	blobs []string
+}
+
+// add adds document of id to collection along with blobs when it is an image. Refs are field to referred id
+func (w *world) add(collection string, id bson.ObjectID, deletedOn any, refs bson.M) {
+	doc := bson.M{iDField: id, deletedOnField: deletedOn}
+	for field, ref := range refs {
+		doc[field] = ref
+	}
+	if collection == imagesCollection {
+		key := id.Hex()
+		doc[blobKeyField] = key
+		doc[renditionsField] = bson.M{"small": key + "_small"}
+		w.blobs = append(w.blobs, key, key+"_small")
+	}
+	w.docs[collection] = append(w.docs[collection], doc)
+}
+
+func ids(n int) []bson.ObjectID {
+	ids := make([]bson.ObjectID, n)
+	for i := range ids {
+		ids[i] = bson.NewObjectID()
+	}
+	return ids
+}
+
+func TestPurger_Run(t *testing.T) {
+	t.Parallel()
+	old := time.Now().Add(-2 * DefaultRetention).UTC()
+	recent := time.Now().UTC()
+
+	// Users: u[0] deleted long ago, u[1] active, u[2] deleted recently
+	// Pets: p[0] sold pet of u[0], p[1] deleted pet of u[1], p[2] & p[3] available pets of u[1] & u[2]
+	// Images: one of each pet, i[3] of p[2] deleted on its own
+	u, p, i, c, r := ids(3), ids(4), ids(4), ids(3), ids(2)
+	cascade := func() *world {
+		w := &world{docs: map[string][]bson.M{}}
+		for k, deletedOn := range []any{old, nil, recent} {
+			w.add(usersCollection, u[k], deletedOn, nil)
+		}
+		w.add(petsCollection, p[0], nil, bson.M{userIDField: u[0]})
+		w.add(petsCollection, p[1], old, bson.M{userIDField: u[1]})
+		w.add(petsCollection, p[2], nil, bson.M{userIDField: u[1]})
+		w.add(petsCollection, p[3], nil, bson.M{userIDField: u[2]})
+		w.add(imagesCollection, i[0], nil, bson.M{userIDField: u[0], petIDField: p[0]})
+		w.add(imagesCollection, i[1], nil, bson.M{userIDField: u[1], petIDField: p[1]})
+		w.add(imagesCollection, i[2], nil, bson.M{userIDField: u[1], petIDField: p[2]})
+		w.add(imagesCollection, i[3], old, bson.M{userIDField: u[1], petIDField: p[2]})
+		w.add(cartItemsCollection, c[0], nil, bson.M{userIDField: u[0], petIDField: p[2]})
+		w.add(cartItemsCollection, c[1], nil, bson.M{userIDField: u[2], petIDField: p[1]})
+		w.add(cartItemsCollection, c[2], nil, bson.M{userIDField: u[2], petIDField: p[2]})
+		w.add(refreshTokensCollection, r[0], nil, bson.M{userIDField: u[0]})
+		w.add(refreshTokensCollection, r[1], nil, bson.M{userIDField: u[1]})
+		return w
+	}
+	deletedPets := func(n int) func() *world {
+		return func() *world {
+			w := &world{docs: map[string][]bson.M{}}
+			images := ids(n)
+			for k, id := range ids(n) {
+				w.add(petsCollection, id, old, nil)
+				w.add(imagesCollection, images[k], nil, bson.M{petIDField: id})
+			}
+			return w
+		}
+	}
+
+	tests := []struct {
+		name        string
+		opts        Options
+		world       func() *world
+		want        Summary
+		wantLeft    map[string][]bson.ObjectID // nil when every document is left as is
+		wantDeletes []string
+		wantBlobs   []string // Keys of blobs left, nil when every blob is left as is
+	}{
+		{
+			name:  "cascade deletes children before their parent",
+			opts:  Options{BatchSize: DefaultBatchSize},
+			world: cascade,
+			want: Summary{
+				usersCollection: 1, petsCollection: 2, imagesCollection: 3,
+				cartItemsCollection: 2, refreshTokensCollection: 1,
+			},
+			// Sold pet p[0] goes along with its owner, even though it was never deleted on its own
+			wantLeft: map[string][]bson.ObjectID{
+				usersCollection:         {u[1], u[2]},
+				petsCollection:          {p[2], p[3]},
+				imagesCollection:        {i[2]},
+				cartItemsCollection:     {c[2]},
+				refreshTokensCollection: {r[1]},
+			},
+			wantDeletes: []string{
+				imagesCollection, petsCollection, cartItemsCollection, refreshTokensCollection, usersCollection,
+				imagesCollection, cartItemsCollection, petsCollection,
+				imagesCollection,
+			},
+			wantBlobs: []string{i[2].Hex(), i[2].Hex() + "_small"},
+		},
+		{
+			name:  "dry run only counts",
+			opts:  Options{BatchSize: DefaultBatchSize, DryRun: true},
+			world: cascade,
+			// Image of p[0] is reached by cascades of both its pet & user
+			want: Summary{
+				usersCollection: 1, petsCollection: 2, imagesCollection: 4,
+				cartItemsCollection: 2, refreshTokensCollection: 1,
+			},
+		},
+		{
+			name:  "last batch is full",
+			opts:  Options{BatchSize: 2},
+			world: deletedPets(4),
+			want:  Summary{petsCollection: 4, imagesCollection: 4, cartItemsCollection: 0},
+			wantLeft: map[string][]bson.ObjectID{
+				petsCollection:   nil,
+				imagesCollection: nil,
+			},
+			wantDeletes: []string{imagesCollection, petsCollection, imagesCollection, petsCollection},
+			wantBlobs:   []string{},
+		},
+		{
+			name:  "last batch is partial",
+			opts:  Options{BatchSize: 2},
+			world: deletedPets(5),
+			want:  Summary{petsCollection: 5, imagesCollection: 5, cartItemsCollection: 0},
+			wantLeft: map[string][]bson.ObjectID{
+				petsCollection:   nil,
+				imagesCollection: nil,
+			},
+			wantDeletes: []string{
+				imagesCollection, petsCollection, imagesCollection, petsCollection, imagesCollection, petsCollection,
+			},
+			wantBlobs: []string{},
+		},
+		{
+			name:  "dry run pages past batches it does not delete",
+			opts:  Options{BatchSize: 2, DryRun: true},
+			world: deletedPets(5),
+			want:  Summary{petsCollection: 5, imagesCollection: 5, cartItemsCollection: 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := tt.world()
+			wantLeft := tt.wantLeft
+			if wantLeft == nil {
+				wantLeft = map[string][]bson.ObjectID{}
+				for collection, docs := range w.docs {
+					for _, doc := range docs {
+						wantLeft[collection] = append(wantLeft[collection], doc[iDField].(bson.ObjectID))
+					}
+				}
+			}
+			dir := t.TempDir()
+			store, err := blobstore.NewFS(dir)
+			if err != nil {
+				t.Fatalf("NewFS() error = %v", err)
+			}
+			for _, key := range w.blobs {
+				if err = store.Put(t.Context(), key, bytes.NewReader([]byte(key))); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+			}
+			db := &fakeDB{docs: w.docs}
+			opts := tt.opts
+			opts.Retention = DefaultRetention
+			p := &Purger{collection: db.collection, blobs: store, opts: opts}
+
+			got, err := p.Run(t.Context())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+			if !slices.Equal(db.deletes, tt.wantDeletes) {
+				t.Errorf("deleted from %v, want %v", db.deletes, tt.wantDeletes)
+			}
+
+			for collection, want := range wantLeft {
+				var left []bson.ObjectID
+				for _, doc := range (&fakeCollection{db: db, name: collection}).sorted() {
+					left = append(left, doc[iDField].(bson.ObjectID))
+				}
+				slices.SortFunc(want, func(a, b bson.ObjectID) int { return bytes.Compare(a[:], b[:]) })
+				if !slices.Equal(left, want) {
+					t.Errorf("%s left = %v, want %v", collection, left, want)
+				}
+			}
+
+			wantBlobs := tt.wantBlobs
+			if wantBlobs == nil {
+				wantBlobs = w.blobs
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			blobs := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				blobs = append(blobs, entry.Name())
+			}
+			if !slices.Equal(blobs, slices.Sorted(slices.Values(wantBlobs))) {
+				t.Errorf("blobs left = %v, want %v", blobs, wantBlobs)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Package rendition scales pet images down to smaller renditions, so that listing pages
+// need not download images in original size
+package rendition
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+const jpegQuality = 85
+
+// Sizes are longest side in pixels of each scaled down rendition
+var Sizes = map[genRouter.ImageSize]int{
+	genRouter.Small:  128,
+	genRouter.Medium: 512,
+}
+
+// Set is JPEG of every rendition of a single pet image keyed by size. Original is the uploaded JPEG as is,
+// a rendition of an image already smaller than its size refers to the original
+type Set map[genRouter.ImageSize][]byte
+
+// New returns renditions of img decoded from original
+func New(original []byte, img image.Image) (Set, error) {
+	set := Set{genRouter.Original: original}
+	bounds := img.Bounds()
+	longest := max(bounds.Dx(), bounds.Dy())
+	for size, side := range Sizes {
+		if longest <= side {
+			set[size] = original
+			continue
+		}
+		var buf bytes.Buffer
+		err := jpeg.Encode(&buf, scale(img, side*bounds.Dx()/longest, side*bounds.Dy()/longest),
+			&jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+		set[size] = buf.Bytes()
+	}
+	return set, nil
+}
+
+// scale shrinks img to width x height, averaging every source pixel covered by a destination pixel
+func scale(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	width, height = max(width, 1), max(height, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0, y1 := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := range width {
+			x0, x1 := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			pix := dst.Pix[y*dst.Stride+x*4:]
+			for i := range sum {
+				pix[i] = uint8(sum[i] / n) //nolint:gosec // Average of uint8 values fits in uint8
+			}
+		}
+	}
+	return dst
+}
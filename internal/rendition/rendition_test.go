@@ -0,0 +1,106 @@
+package rendition
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	genRouter "github.com/vrv501/simple-api/internal/generated/router"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		width, height int
+		want          map[genRouter.ImageSize]image.Point // Zero point when rendition is the original
+	}{
+		{
+			name:  "landscape image is scaled by width",
+			width: 1920, height: 1080,
+			want: map[genRouter.ImageSize]image.Point{
+				genRouter.Small:  {X: 128, Y: 72},
+				genRouter.Medium: {X: 512, Y: 288},
+			},
+		},
+		{
+			name:  "portrait image is scaled by height",
+			width: 300, height: 600,
+			want: map[genRouter.ImageSize]image.Point{
+				genRouter.Small:  {X: 64, Y: 128},
+				genRouter.Medium: {X: 256, Y: 512},
+			},
+		},
+		{
+			name:  "image smaller than rendition is not scaled",
+			width: 256, height: 256,
+			want: map[genRouter.ImageSize]image.Point{
+				genRouter.Small: {X: 128, Y: 128},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			original, img := newJPEG(t, tt.width, tt.height)
+			set, err := New(original, img)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if !bytes.Equal(set[genRouter.Original], original) {
+				t.Errorf("original rendition differs from uploaded image")
+			}
+			for size := range Sizes {
+				want := tt.want[size]
+				if want == (image.Point{}) {
+					if !bytes.Equal(set[size], original) {
+						t.Errorf("%s rendition differs from uploaded image", size)
+					}
+					continue
+				}
+				cfg, errS := jpeg.DecodeConfig(bytes.NewReader(set[size]))
+				if errS != nil {
+					t.Fatalf("%s rendition is not a jpeg: %v", size, errS)
+				}
+				if got := (image.Point{X: cfg.Width, Y: cfg.Height}); got != want {
+					t.Errorf("%s rendition is %v, want %v", size, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	t.Parallel()
+
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(0, 0, color.Gray{Y: 255})
+	img.SetGray(1, 1, color.Gray{Y: 255})
+	got := scale(img, 1, 1).RGBAAt(0, 0)
+	if want := (color.RGBA{R: 127, G: 127, B: 127, A: 255}); got != want {
+		t.Errorf("scale() = %v, want %v", got, want)
+	}
+}
+
+func newJPEG(t *testing.T, width, height int) ([]byte, image.Image) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255}) //nolint:gosec // Wraps around
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("jpeg.Decode() error = %v", err)
+	}
+	return buf.Bytes(), decoded
+}